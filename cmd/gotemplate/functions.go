@@ -0,0 +1,67 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+// function describes a template function in the json output
+type function struct {
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`
+	Description string `json:"description"`
+}
+
+// functionsCommand prints the template functions with their signatures and descriptions
+func functionsCommand(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("functions", flag.ContinueOnError)
+	format := flags.String("format", "text", "the output format, text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("invalid format: %s, expected text or json", *format)
+	}
+
+	funcs := render.Funcs()
+	signatures := render.Signatures(funcs)
+	descriptions := render.Descriptions(funcs)
+	var list []function
+	for name := range funcs {
+		list = append(list, function{Name: name, Signature: signatures[name], Description: descriptions[name]})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(list)
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, x := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", x.Name, x.Signature, x.Description)
+	}
+
+	return w.Flush()
+}
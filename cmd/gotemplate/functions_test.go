@@ -0,0 +1,62 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+func TestFunctionsCommand(t *testing.T) {
+	out := new(bytes.Buffer)
+	if err := run([]string{"functions"}, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(render.Funcs()) {
+		t.Errorf("expected a line per function, got: %d", len(lines))
+	}
+	if !strings.Contains(out.String(), "converts the string to uppercase") {
+		t.Errorf("expected the descriptions in the output: %s", out.String())
+	}
+}
+
+func TestFunctionsCommandJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	if err := run([]string{"functions", "-format", "json"}, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var list []function
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatalf("unable to decode the output, error: %s", err)
+	}
+	if len(list) == 0 || list[0].Name != "chunklist" {
+		t.Errorf("expected the functions sorted by name: %v", list)
+	}
+	found := false
+	for _, x := range list {
+		if x.Name == "empty" && x.Signature == "func(string) bool" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("unexpected functions: %v", list)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gambol99/terraform-gotemplate/pkg"
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+// lintCommand checks the templates in a directory, printing the problems found
+func lintCommand(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	format := flags.String("format", "text", "the output format, text or json")
+	varsFile := flags.String("vars-file", "", "the path to a file of the variables, read as the vars_files of the provider, enabling the check for undeclared variables")
+	frontmatter := flags.Bool("frontmatter", false, "parse the frontmatter of the templates, the variables it declares are checked")
+	disable := flags.String("disable", "", "a comma separated list of the checks to skip, i.e. unused_define")
+	followSymlinks := flags.Bool("follow-symlinks", false, "follow symbolic links when walking the directory")
+	includeHidden := flags.Bool("include-hidden", false, "include files and directories whose name begins with a dot")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("you must specify a single directory, usage: gotemplate lint [flags] <dir>")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("invalid format: %s, expected text or json", *format)
+	}
+	disabled := make(map[string]bool)
+	for _, x := range strings.Split(*disable, ",") {
+		if x = strings.TrimSpace(x); x == "" {
+			continue
+		}
+		if !containsKind(x) {
+			return fmt.Errorf("unknown check: %s, expected one of: %s", x, strings.Join(render.LintKinds, ", "))
+		}
+		disabled[x] = true
+	}
+
+	lint := render.LintOptions{Frontmatter: *frontmatter}
+	if *varsFile != "" {
+		vars, err := pkg.ReadVarsFile(*varsFile)
+		if err != nil {
+			return err
+		}
+		lint.Vars = vars
+	}
+	found, err := render.Lint(flags.Arg(0), render.Options{
+		FollowSymlinks: *followSymlinks,
+		IncludeHidden:  *includeHidden,
+	}, lint)
+	if err != nil {
+		return err
+	}
+	problems := []render.LintProblem{}
+	for _, x := range found {
+		if !disabled[x.Kind] {
+			problems = append(problems, x)
+		}
+	}
+
+	// step: print the problems, failing if there were any so the command can gate a pipeline
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(problems); err != nil {
+			return err
+		}
+	} else {
+		for _, x := range problems {
+			fmt.Fprintln(out, x.String())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problem(s) in %s", len(problems), flags.Arg(0))
+	}
+
+	return nil
+}
+
+// containsKind checks the kind of problem is known
+func containsKind(kind string) bool {
+	for _, x := range render.LintKinds {
+		if x == kind {
+			return true
+		}
+	}
+
+	return false
+}
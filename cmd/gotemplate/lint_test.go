@@ -0,0 +1,78 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+func testLintDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gotemplate")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "bad.tmpl"), []byte("{{ if }}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "defines.tmpl"), []byte(`{{ define "unused" }}{{ end }}`), 0644)
+
+	return dir
+}
+
+func TestLintCommandJSON(t *testing.T) {
+	dir := testLintDir(t)
+	defer os.RemoveAll(dir)
+
+	out := new(bytes.Buffer)
+	if err := run([]string{"lint", "-format", "json", dir}, out); err == nil {
+		t.Errorf("expected an error when problems are found")
+	}
+	var problems []render.LintProblem
+	if err := json.Unmarshal(out.Bytes(), &problems); err != nil {
+		t.Fatalf("unable to decode the output: %s, error: %s", out.String(), err)
+	}
+	if len(problems) != 2 || problems[0].Kind != render.LintSyntax || problems[1].Kind != render.LintUnusedDefine {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+}
+
+func TestLintCommandDisable(t *testing.T) {
+	dir := testLintDir(t)
+	defer os.RemoveAll(dir)
+	os.Remove(filepath.Join(dir, "bad.tmpl"))
+
+	out := new(bytes.Buffer)
+	if err := run([]string{"lint", "-disable", "unused_define", dir}, out); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if out.String() != "" {
+		t.Errorf("expected no output, got: %q", out.String())
+	}
+	if err := run([]string{"lint", "-disable", "bad", dir}, out); err == nil {
+		t.Errorf("expected an error for an unknown check")
+	}
+}
+
+func TestLintCommandUsage(t *testing.T) {
+	if err := run([]string{"lint"}, new(bytes.Buffer)); err == nil {
+		t.Errorf("expected an error without a directory")
+	}
+}
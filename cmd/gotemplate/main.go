@@ -0,0 +1,75 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command is the signature for a cli subcommand
+type command func(args []string, out io.Writer) error
+
+// stdin is the input read when a template of - is given
+var stdin io.Reader = os.Stdin
+
+// commands is a list of subcommands we support
+var commands = map[string]command{
+	"functions": functionsCommand,
+	"lint":      lintCommand,
+	"render":    renderCommand,
+	"watch":     watchCommand,
+}
+
+func main() {
+	// step: the render logs of the provider are only shown when asked for, as terraform does
+	if os.Getenv("TF_LOG") == "" {
+		log.SetOutput(ioutil.Discard)
+	}
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// run is responsible for dispatching to the subcommand
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command specified, usage: gotemplate [%s]", commandNames())
+	}
+	cmd, found := commands[args[0]]
+	if !found {
+		return fmt.Errorf("unknown command: %s, usage: gotemplate [%s]", args[0], commandNames())
+	}
+
+	return cmd(args[1:], out)
+}
+
+// commandNames returns a sorted list of the subcommands
+func commandNames() string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gambol99/terraform-gotemplate/pkg"
+)
+
+// renderCommand renders a template and prints the output
+func renderCommand(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	config := addRenderFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+	rendered, err := config.render()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, rendered)
+
+	return err
+}
+
+// renderConfig are the flags shared by the commands which render a template
+type renderConfig struct {
+	templateName string
+	snippetsPath string
+	strict       bool
+	varsFile     string
+	sets         varFlags
+}
+
+// addRenderFlags registers the render flags on the flag set
+func addRenderFlags(flags *flag.FlagSet) *renderConfig {
+	config := &renderConfig{}
+	flags.StringVar(&config.templateName, "template", "", "the path to the template you wish rendered, - reads it from stdin")
+	flags.StringVar(&config.snippetsPath, "snippets", "", "the path to a directory containing snippets")
+	flags.BoolVar(&config.strict, "strict", false, "fail the render on references to missing variables")
+	flags.StringVar(&config.varsFile, "vars-file", "", "the path to a yaml, json, .env or .tfvars file of the variables, read as the vars_files of the provider")
+	flags.Var(&config.sets, "var", "a variable as key=value, dotted keys set nested values, may be repeated")
+
+	return config
+}
+
+// validate checks the flags are usable
+func (c *renderConfig) validate() error {
+	if c.templateName == "" {
+		return fmt.Errorf("you must specify a template via --template")
+	}
+
+	return nil
+}
+
+// render is responsible for rendering the template as the gotemplate_file data source does, the
+// vars file being read as one of its vars_files and the variables as set blocks
+func (c *renderConfig) render() (string, error) {
+	arguments, err := templateArguments(c.templateName)
+	if err != nil {
+		return "", err
+	}
+	arguments["snippets"] = c.snippetsPath
+	arguments["strict"] = c.strict
+	if c.varsFile != "" {
+		arguments["vars_files"] = []interface{}{c.varsFile}
+	}
+	var sets []interface{}
+	for _, x := range c.sets {
+		sets = append(sets, map[string]interface{}{"key": x.key, "value": x.value})
+	}
+	if len(sets) > 0 {
+		arguments["set"] = sets
+	}
+
+	return pkg.Render(arguments)
+}
+
+// templateArguments returns the arguments giving the data source the template; the arguments are
+// interpolated as terraform would, so no template text may be passed in them, the ${...} of a shell
+// script failing to parse. A file is passed by its absolute path for the provider to read, while
+// stdin and inline contents are passed base64 encoded
+func templateArguments(name string) (map[string]interface{}, error) {
+	if name == "-" {
+		content, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the template from stdin, error: %s", err)
+		}
+		return map[string]interface{}{"content_base64": base64.StdEncoding.EncodeToString(content)}, nil
+	}
+	if info, err := os.Stat(name); err == nil && !info.IsDir() {
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve the template: %s, error: %s", name, err)
+		}
+		return map[string]interface{}{"template": path}, nil
+	}
+
+	return map[string]interface{}{"content_base64": base64.StdEncoding.EncodeToString([]byte(name))}, nil
+}
+
+// varFlag is a single key=value variable given on the command line
+type varFlag struct {
+	key   string
+	value string
+}
+
+// varFlags collects the repeated --var flags in order
+type varFlags []varFlag
+
+// String returns the variables as given
+func (v *varFlags) String() string {
+	var list []string
+	for _, x := range *v {
+		list = append(list, x.key+"="+x.value)
+	}
+
+	return strings.Join(list, ",")
+}
+
+// Set parses a key=value variable
+func (v *varFlags) Set(value string) error {
+	index := strings.Index(value, "=")
+	if index <= 0 {
+		return fmt.Errorf("expected key=value, got: %q", value)
+	}
+	*v = append(*v, varFlag{key: value[:index], value: value[index+1:]})
+
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotemplate")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	templateFile := filepath.Join(dir, "template.tmpl")
+	varsFile := filepath.Join(dir, "vars.json")
+	ioutil.WriteFile(templateFile, []byte(`Hello {{ .name }}{{ if .enabled }}!{{ end }}`), 0644)
+	ioutil.WriteFile(varsFile, []byte(`{"name": "rohith", "enabled": true}`), 0644)
+
+	out := new(bytes.Buffer)
+	if err := run([]string{"render", "-template", templateFile, "-vars-file", varsFile}, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != "Hello rohith!" {
+		t.Errorf("got: %q, want: %q", out.String(), "Hello rohith!")
+	}
+}
+
+func TestRenderCommandProviderLoader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotemplate")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// step: the vars files are read as the provider reads its vars_files, keeping the types
+	templateFile := filepath.Join(dir, "template.tmpl")
+	varsFile := filepath.Join(dir, "vars.yaml")
+	ioutil.WriteFile(templateFile, []byte(`{{ if .enabled }}on{{ else }}off{{ end }} {{ .gotemplate.workspace }}`), 0644)
+	ioutil.WriteFile(varsFile, []byte("enabled: false\n"), 0644)
+
+	out := new(bytes.Buffer)
+	if err := run([]string{"render", "-template", templateFile, "-vars-file", varsFile}, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != "off default" {
+		t.Errorf("got: %q, want: %q", out.String(), "off default")
+	}
+}
+
+func TestRenderCommandStdin(t *testing.T) {
+	defer func(in io.Reader) { stdin = in }(stdin)
+	stdin = strings.NewReader(`{{ .name }} {{ .image.tag }} {{ .empty }}`)
+
+	out := new(bytes.Buffer)
+	args := []string{"render", "-template", "-", "--var", "name=rohith", "--var", "image.tag=a=b", "--var", "empty="}
+	if err := run(args, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != "rohith a=b " {
+		t.Errorf("got: %q, want: %q", out.String(), "rohith a=b ")
+	}
+	if err := run([]string{"render", "-template", "x", "--var", "novalue"}, new(bytes.Buffer)); err == nil {
+		t.Error("expected an error for a var without a value")
+	}
+}
+
+func TestRenderCommandShellTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotemplate")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// step: the shell expansions are not terraform interpolations, so must reach the render intact
+	content := "#!/bin/sh\necho ${NAME:-default} {{ .name }}\n"
+	expected := "#!/bin/sh\necho ${NAME:-default} app\n"
+	templateFile := filepath.Join(dir, "t.sh")
+	ioutil.WriteFile(templateFile, []byte(content), 0644)
+
+	out := new(bytes.Buffer)
+	if err := run([]string{"render", "-template", templateFile, "--var", "name=app"}, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("got: %q, want: %q", out.String(), expected)
+	}
+
+	defer func(in io.Reader) { stdin = in }(stdin)
+	stdin = strings.NewReader(content)
+	out.Reset()
+	if err := run([]string{"render", "-template", "-", "--var", "name=app"}, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("stdin, got: %q, want: %q", out.String(), expected)
+	}
+}
+
+func TestRenderCommandNoTemplate(t *testing.T) {
+	if err := run([]string{"render"}, new(bytes.Buffer)); err == nil {
+		t.Error("expected an error when no template is specified")
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	if err := run([]string{"unknown"}, new(bytes.Buffer)); err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+	if err := run([]string{}, new(bytes.Buffer)); err == nil {
+		t.Error("expected an error for no command")
+	}
+}
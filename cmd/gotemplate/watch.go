@@ -0,0 +1,154 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+// watchCommand re-renders the template whenever it, the snippets or the vars file change
+func watchCommand(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	config := addRenderFlags(flags)
+	interval := flags.Duration("interval", time.Second, "how often to check the files for changes")
+	output := flags.String("output", "", "write the rendered template to the path rather than printing it")
+	listen := flags.String("listen", "", "serve the latest rendered template over http on the address, i.e. :8080")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+	if config.templateName == "-" {
+		return fmt.Errorf("the template cannot be read from stdin when watching")
+	}
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	var latest preview
+	if *listen != "" {
+		server := &http.Server{Addr: *listen, Handler: &latest}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(out, "[error] unable to serve the preview, error: %s\n", err)
+			}
+		}()
+		defer server.Close()
+		fmt.Fprintf(out, "[info] serving the rendered template on %s\n", *listen)
+	}
+
+	watch(config, *interval, stop, func(rendered string, err error) {
+		latest.update(rendered, err)
+		switch {
+		case err != nil:
+			fmt.Fprintf(out, "[error] %s\n", err)
+		case *output != "":
+			if err := ioutil.WriteFile(*output, []byte(rendered), 0644); err != nil {
+				fmt.Fprintf(out, "[error] unable to write the output, error: %s\n", err)
+				return
+			}
+			fmt.Fprintf(out, "[info] rendered %s at %s\n", *output, time.Now().Format(time.RFC3339))
+		case *listen == "":
+			fmt.Fprintf(out, "%s\n", rendered)
+		}
+	})
+
+	return nil
+}
+
+// watch is responsible for rendering the template on start and again whenever the files change,
+// until the stop channel is closed
+func watch(config *renderConfig, interval time.Duration, stop <-chan struct{}, fn func(string, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		if current := watchFingerprint(config); current != last {
+			last = current
+			fn(config.render())
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchFingerprint returns a summary of the size and modification time of the watched files
+func watchFingerprint(config *renderConfig) string {
+	var fingerprint string
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			fingerprint += fmt.Sprintf("%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	add(config.templateName)
+	add(config.varsFile)
+	if config.snippetsPath != "" {
+		render.WalkFiles(config.snippetsPath, render.WalkOptions{}, func(path, _ string) error {
+			add(path)
+			return nil
+		})
+	}
+
+	return fingerprint
+}
+
+// preview serves the latest render over http
+type preview struct {
+	sync.RWMutex
+	rendered string
+	err      error
+}
+
+// update records the latest render
+func (p *preview) update(rendered string, err error) {
+	p.Lock()
+	defer p.Unlock()
+	p.rendered, p.err = rendered, err
+}
+
+// ServeHTTP writes the latest render, or the error which prevented it
+func (p *preview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.RLock()
+	defer p.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if p.err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, p.err.Error())
+		return
+	}
+	io.WriteString(w, p.rendered)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotemplate")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	templateFile := filepath.Join(dir, "template.tmpl")
+	ioutil.WriteFile(templateFile, []byte("first"), 0644)
+
+	renders := make(chan string, 10)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	config := &renderConfig{templateName: templateFile}
+	go func() {
+		watch(config, 10*time.Millisecond, stop, func(rendered string, err error) {
+			renders <- rendered
+		})
+		close(done)
+	}()
+
+	if got := <-renders; got != "first" {
+		t.Errorf("got: %q, want: %q", got, "first")
+	}
+	ioutil.WriteFile(templateFile, []byte("second render"), 0644)
+	select {
+	case got := <-renders:
+		if got != "second render" {
+			t.Errorf("got: %q, want: %q", got, "second render")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the template to be re-rendered on change")
+	}
+	close(stop)
+	<-done
+}
+
+func TestPreview(t *testing.T) {
+	var p preview
+	p.update("rendered", nil)
+	recorder := httptest.NewRecorder()
+	p.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	if recorder.Code != 200 || recorder.Body.String() != "rendered" {
+		t.Errorf("unexpected response: %d, %q", recorder.Code, recorder.Body.String())
+	}
+
+	p.update("", errors.New("failed"))
+	recorder = httptest.NewRecorder()
+	p.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	if recorder.Code != 500 || recorder.Body.String() != "failed" {
+		t.Errorf("unexpected response: %d, %q", recorder.Code, recorder.Body.String())
+	}
+}
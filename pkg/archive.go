@@ -0,0 +1,112 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// archiveTarGz indicates a gzipped tarball
+	archiveTarGz = "tar.gz"
+	// archiveZip indicates a zip archive
+	archiveZip = "zip"
+)
+
+// archiveEpoch is the modification time used for all entries, keeping the archive reproducible
+var archiveEpoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// archiveFiles is responsible for packing the files into an archive of the given format
+func archiveFiles(format string, files map[string]string) ([]byte, error) {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch format {
+	case archiveTarGz:
+		return tarGzFiles(names, files)
+	case archiveZip:
+		return zipFiles(names, files)
+	}
+
+	return nil, fmt.Errorf("unsupported archive format: %s", format)
+}
+
+// tarGzFiles produces a gzipped tarball of the files
+func tarGzFiles(names []string, files map[string]string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	zw := gzip.NewWriter(buffer)
+	tw := tar.NewWriter(zw)
+
+	for _, name := range names {
+		content := []byte(files[name])
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: archiveEpoch,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// zipFiles produces a zip archive of the files
+func zipFiles(names []string, files map[string]string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	zw := zip.NewWriter(buffer)
+
+	for _, name := range names {
+		header := &zip.FileHeader{
+			Name:   name,
+			Method: zip.Deflate,
+		}
+		header.SetModTime(archiveEpoch)
+		header.SetMode(0644)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+var testArchiveFiles = map[string]string{
+	"b.conf":     "b",
+	"a/one.conf": "one",
+}
+
+func TestArchiveTarGz(t *testing.T) {
+	content, err := archiveFiles(archiveTarGz, testArchiveFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	tr := tar.NewReader(zr)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		data, _ := ioutil.ReadAll(tr)
+		if string(data) != testArchiveFiles[header.Name] {
+			t.Errorf("file: %s, got: %q, want: %q", header.Name, data, testArchiveFiles[header.Name])
+		}
+		names = append(names, header.Name)
+	}
+	if len(names) != 2 || names[0] != "a/one.conf" {
+		t.Errorf("expected sorted entries, got: %v", names)
+	}
+}
+
+func TestArchiveZip(t *testing.T) {
+	content, err := archiveFiles(archiveZip, testArchiveFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(zr.File) != 2 {
+		t.Fatalf("expected 2 files, got: %d", len(zr.File))
+	}
+	for _, x := range zr.File {
+		rc, err := x.Open()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		data, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if string(data) != testArchiveFiles[x.Name] {
+			t.Errorf("file: %s, got: %q, want: %q", x.Name, data, testArchiveFiles[x.Name])
+		}
+	}
+}
+
+func TestArchiveReproducible(t *testing.T) {
+	a, _ := archiveFiles(archiveTarGz, testArchiveFiles)
+	b, _ := archiveFiles(archiveTarGz, testArchiveFiles)
+	if !bytes.Equal(a, b) {
+		t.Error("expected the archive to be reproducible")
+	}
+}
+
+func TestArchiveUnsupported(t *testing.T) {
+	if _, err := archiveFiles("rar", testArchiveFiles); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// awsSettings are the settings of the aws clients used by the aws resources
+type awsSettings struct {
+	// region is the region of the requests
+	region string
+	// profile is the name of the shared credentials profile
+	profile string
+	// accessKey, secretKey and token are static credentials, overriding the default chain
+	accessKey string
+	secretKey string
+	token     string
+	// ssmEndpoint overrides the endpoint of the ssm api
+	ssmEndpoint string
+	// s3Endpoint overrides the endpoint of the s3 api
+	s3Endpoint string
+	// s3ForcePathStyle addresses the buckets by path rather than virtual host
+	s3ForcePathStyle bool
+}
+
+// awsSchema returns the provider block configuring the aws clients, any setting left unset
+// falling back to the standard AWS_* environment variables and shared configuration
+func awsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "The connection settings of the aws apis used by the aws resources",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"region": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The region of the requests, i.e. eu-west-2",
+				},
+				"profile": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The name of the profile in the shared credentials and config files",
+				},
+				"access_key": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The static access key used to sign the requests",
+				},
+				"secret_key": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "The static secret key used to sign the requests",
+				},
+				"token": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "The session token of temporary static credentials",
+				},
+				"ssm_endpoint": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "A custom endpoint of the ssm api",
+				},
+				"s3_endpoint": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "A custom endpoint of the s3 api",
+				},
+				"s3_force_path_style": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Address the buckets by path rather than virtual host, as required by most s3 compatible stores",
+				},
+			},
+		},
+	}
+}
+
+// awsConfig returns the aws settings from the provider block, if any
+func awsConfig(blocks []interface{}) awsSettings {
+	if len(blocks) == 0 || blocks[0] == nil {
+		return awsSettings{}
+	}
+	block := blocks[0].(map[string]interface{})
+
+	return awsSettings{
+		region:           block["region"].(string),
+		profile:          block["profile"].(string),
+		accessKey:        block["access_key"].(string),
+		secretKey:        block["secret_key"].(string),
+		token:            block["token"].(string),
+		ssmEndpoint:      block["ssm_endpoint"].(string),
+		s3Endpoint:       block["s3_endpoint"].(string),
+		s3ForcePathStyle: block["s3_force_path_style"].(bool),
+	}
+}
+
+// awsOf returns the aws settings from the provider configuration
+func awsOf(meta interface{}) awsSettings {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.aws
+	}
+
+	return awsSettings{}
+}
+
+// session returns an aws session using the settings
+func (a awsSettings) session() (*session.Session, error) {
+	options := session.Options{
+		Profile:           a.profile,
+		SharedConfigState: session.SharedConfigEnable,
+	}
+	if a.region != "" {
+		options.Config.Region = aws.String(a.region)
+	}
+	if a.accessKey != "" {
+		options.Config.Credentials = credentials.NewStaticCredentials(a.accessKey, a.secretKey, a.token)
+	}
+
+	return session.NewSessionWithOptions(options)
+}
+
+// endpoint returns the client configuration overriding the endpoint, if given
+func endpoint(url string) *aws.Config {
+	config := &aws.Config{}
+	if url != "" {
+		config.Endpoint = aws.String(url)
+	}
+
+	return config
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"testing"
+)
+
+func TestAWSConfig(t *testing.T) {
+	settings := awsConfig([]interface{}{map[string]interface{}{
+		"region":              "eu-west-2",
+		"profile":             "",
+		"access_key":          "access",
+		"secret_key":          "secret",
+		"token":               "",
+		"ssm_endpoint":        "http://localhost:4566",
+		"s3_endpoint":         "",
+		"s3_force_path_style": true,
+	}})
+	expected := awsSettings{region: "eu-west-2", accessKey: "access", secretKey: "secret", ssmEndpoint: "http://localhost:4566", s3ForcePathStyle: true}
+	if settings != expected {
+		t.Errorf("got: %+v, want: %+v", settings, expected)
+	}
+	session, err := settings.session()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if credentials, err := session.Config.Credentials.Get(); err != nil || credentials.AccessKeyID != "access" {
+		t.Errorf("expected the static credentials, got: %v, error: %v", credentials, err)
+	}
+	if config := endpoint(""); config.Endpoint != nil {
+		t.Errorf("expected no endpoint override")
+	}
+}
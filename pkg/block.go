@@ -0,0 +1,112 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// blockMarkers returns the lines marking the beginning and end of a managed block
+func blockMarkers(marker string) (string, string) {
+	return strings.Replace(marker, "{mark}", "BEGIN", -1), strings.Replace(marker, "{mark}", "END", -1)
+}
+
+// blockBody returns the body of a block as written, always ending in a newline unless empty
+func blockBody(content string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	return content
+}
+
+// findBlock returns the offsets of the block within the content, from the start of the begin
+// marker line to the end of the end marker line
+func findBlock(content, begin, end string) (int, int, error) {
+	start, stop, offset := -1, -1, 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.TrimRight(line, "\r\n") {
+		case begin:
+			if start >= 0 {
+				return 0, 0, fmt.Errorf("the marker %q is found more than once", begin)
+			}
+			start = offset
+		case end:
+			if start < 0 || stop >= 0 {
+				return 0, 0, fmt.Errorf("the marker %q is not preceded by a single %q", end, begin)
+			}
+			stop = offset + len(line)
+		}
+		offset += len(line)
+	}
+	if start >= 0 && stop < 0 {
+		return 0, 0, fmt.Errorf("the marker %q is not followed by %q", begin, end)
+	}
+
+	return start, stop, nil
+}
+
+// readBlock returns the body of the block within the content, if found
+func readBlock(content, begin, end string) (string, bool, error) {
+	start, stop, err := findBlock(content, begin, end)
+	if err != nil || start < 0 {
+		return "", false, err
+	}
+	block := content[start:stop]
+	body := block[strings.Index(block, "\n")+1:]
+	lines := strings.SplitAfter(body, "\n")
+	// step: drop the end marker, the last line or the one before the trailing empty split
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines[:len(lines)-1], ""), true, nil
+}
+
+// writeBlock returns the content with the block replaced by the body, or appended when absent, the
+// markers being ended by the newline; a body holding a marker line is refused, as the next read
+// would find the wrong boundary of the block
+func writeBlock(content, begin, end, body, newline string) (string, error) {
+	for _, line := range strings.Split(body, "\n") {
+		if x := strings.TrimRight(line, "\r"); x == begin || x == end {
+			return "", fmt.Errorf("the body contains the marker %q", x)
+		}
+	}
+	start, stop, err := findBlock(content, begin, end)
+	if err != nil {
+		return "", err
+	}
+	block := begin + newline + body + end + newline
+	if start < 0 {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += newline
+		}
+		return content + block, nil
+	}
+
+	return content[:start] + block + content[stop:], nil
+}
+
+// removeBlock returns the content without the block
+func removeBlock(content, begin, end string) (string, error) {
+	start, stop, err := findBlock(content, begin, end)
+	if err != nil || start < 0 {
+		return content, err
+	}
+
+	return content[:start] + content[stop:], nil
+}
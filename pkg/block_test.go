@@ -0,0 +1,107 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteBlock(t *testing.T) {
+	begin, end := blockMarkers("# {mark} gotemplate")
+	cases := []struct {
+		Content  string
+		Body     string
+		Expected string
+	}{
+		{Content: "", Body: "a", Expected: "# BEGIN gotemplate\na\n# END gotemplate\n"},
+		{Content: "x", Body: "a\n", Expected: "x\n# BEGIN gotemplate\na\n# END gotemplate\n"},
+		{Content: "x\n# BEGIN gotemplate\nold\n# END gotemplate\ny\n", Body: "a\nb", Expected: "x\n# BEGIN gotemplate\na\nb\n# END gotemplate\ny\n"},
+		{Content: "x\n# BEGIN gotemplate\nold\n# END gotemplate", Body: "", Expected: "x\n# BEGIN gotemplate\n# END gotemplate\n"},
+	}
+	for i, x := range cases {
+		content, err := writeBlock(x.Content, begin, end, blockBody(x.Body), "\n")
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if content != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, content, x.Expected)
+		}
+		if body, found, err := readBlock(content, begin, end); err != nil || !found || body != blockBody(x.Body) {
+			t.Errorf("case %d, unexpected block: %q, found: %t, error: %v", i, body, found, err)
+		}
+	}
+}
+
+func TestWriteBlockWindows(t *testing.T) {
+	begin, end := blockMarkers("# {mark}")
+	content, err := writeBlock("x", begin, end, "a\r\n", "\r\n")
+	if err != nil || content != "x\r\n# BEGIN\r\na\r\n# END\r\n" {
+		t.Errorf("unexpected content: %q, error: %v", content, err)
+	}
+	if body, found, err := readBlock(content, begin, end); err != nil || !found || body != "a\r\n" {
+		t.Errorf("unexpected block: %q, found: %t, error: %v", body, found, err)
+	}
+}
+
+func TestWriteBlockMarkerInBody(t *testing.T) {
+	begin, end := blockMarkers("# {mark}")
+	for _, body := range []string{"a\n# END\nb\n", "# BEGIN\n", "a\r\n# END\r\n"} {
+		if _, err := writeBlock("x\n", begin, end, body, "\n"); err == nil || !strings.Contains(err.Error(), "contains the marker") {
+			t.Errorf("expected a marker error for %q, got: %v", body, err)
+		}
+	}
+	if _, err := writeBlock("x\n", begin, end, "a # END\n", "\n"); err != nil {
+		t.Errorf("unexpected error for a marker within a line: %s", err)
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	begin, end := blockMarkers("# {mark}")
+	content, err := removeBlock("x\n# BEGIN\na\n# END\ny\n", begin, end)
+	if err != nil || content != "x\ny\n" {
+		t.Errorf("unexpected content: %q, error: %v", content, err)
+	}
+	if _, found, _ := readBlock(content, begin, end); found {
+		t.Error("expected the block to be removed")
+	}
+}
+
+func TestFindBlockErrors(t *testing.T) {
+	begin, end := blockMarkers("# {mark}")
+	for _, x := range []string{
+		"# BEGIN\na\n",
+		"a\n# END\n",
+		"# BEGIN\n# BEGIN\n# END\n",
+		"# BEGIN\n# END\n# END\n",
+	} {
+		if _, _, err := findBlock(x, begin, end); err == nil || !strings.Contains(err.Error(), "marker") {
+			t.Errorf("expected a marker error for %q, got: %v", x, err)
+		}
+	}
+}
+
+func TestValidateBlockMarker(t *testing.T) {
+	if _, errs := validateBlockMarker("// {mark} managed", "marker"); len(errs) > 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	for _, x := range []string{"# managed", "# {mark}\n"} {
+		if _, errs := validateBlockMarker(x, "marker"); len(errs) == 0 {
+			t.Errorf("expected an error for %q", x)
+		}
+	}
+}
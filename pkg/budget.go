@@ -0,0 +1,161 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// budgetQueue waits for other renders to release the budget
+	budgetQueue = "queue"
+	// budgetFail fails the render which would exceed the budget
+	budgetFail = "fail"
+)
+
+// memoryBudget bounds the bytes of rendered output held across the concurrent renders
+type memoryBudget struct {
+	sync.Mutex
+	cond *sync.Cond
+	// limit is the budget in bytes
+	limit int64
+	// action is what happens to a render which would exceed the budget
+	action string
+	// used is the number of bytes currently held
+	used int64
+	// holders is the number of reservations holding some of the budget
+	holders int
+	// waiting is the number of holders with writes waiting on the budget; a reservation shared by
+	// the workers of a render is counted once however many of them are waiting
+	waiting int
+}
+
+// newMemoryBudget returns a budget of the limit, or nil when the limit is zero
+func newMemoryBudget(limit int64, action string) *memoryBudget {
+	if limit <= 0 {
+		return nil
+	}
+	b := &memoryBudget{limit: limit, action: action}
+	b.cond = sync.NewCond(b)
+
+	return b
+}
+
+// reservation returns a new, empty, reservation against the budget
+func (b *memoryBudget) reservation() *reservation {
+	if b == nil {
+		return nil
+	}
+
+	return &reservation{budget: b}
+}
+
+// reservation is the share of the budget held by a single render
+type reservation struct {
+	budget *memoryBudget
+	held   int64
+	// waiting is the number of writes of the reservation waiting on the budget
+	waiting int
+}
+
+// reserve is responsible for taking a further n bytes of the budget. When queueing a render
+// holding none of the budget waits for it; one already holding some only waits while another
+// holder is running, as waiting on a holder which is itself waiting would never end. The
+// reservation may be shared by the workers of a render, so is counted as waiting only once
+func (r *reservation) reserve(n int64) error {
+	if r == nil || n == 0 {
+		return nil
+	}
+	b := r.budget
+	b.Lock()
+	defer b.Unlock()
+
+	for b.used+n > b.limit {
+		if r.held+n > b.limit {
+			return fmt.Errorf("the render requires more than the memory budget of %d bytes", b.limit)
+		}
+		if b.action == budgetFail {
+			return fmt.Errorf("the memory budget of %d bytes is exhausted by the concurrent renders", b.limit)
+		}
+		if r.held == 0 {
+			b.cond.Wait()
+			continue
+		}
+		waiting := b.waiting
+		if r.waiting == 0 {
+			waiting++
+		}
+		if waiting >= b.holders {
+			return fmt.Errorf("the memory budget of %d bytes is exhausted by the concurrent renders, all of which are waiting", b.limit)
+		}
+		b.waiting = waiting
+		r.waiting++
+		b.cond.Wait()
+		if r.waiting--; r.waiting == 0 {
+			b.waiting--
+		}
+	}
+	if r.held == 0 {
+		b.holders++
+	}
+	b.used += n
+	r.held += n
+
+	return nil
+}
+
+// release returns the reservation to the budget
+func (r *reservation) release() {
+	if r == nil {
+		return
+	}
+	b := r.budget
+	b.Lock()
+	defer b.Unlock()
+
+	if r.held > 0 {
+		b.used -= r.held
+		b.holders--
+		r.held = 0
+	}
+	b.cond.Broadcast()
+}
+
+// writer returns a writer reserving the budget for each write before passing it on
+func (r *reservation) writer(w io.Writer) io.Writer {
+	if r == nil {
+		return w
+	}
+
+	return &budgetWriter{w: w, reservation: r}
+}
+
+// budgetWriter reserves the budget for the bytes written through it
+type budgetWriter struct {
+	w           io.Writer
+	reservation *reservation
+}
+
+// Write reserves the budget and writes the content
+func (b *budgetWriter) Write(p []byte) (int, error) {
+	if err := b.reservation.reserve(int64(len(p))); err != nil {
+		return 0, err
+	}
+
+	return b.w.Write(p)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	if newMemoryBudget(0, budgetQueue) != nil {
+		t.Errorf("expected no budget when unlimited")
+	}
+	budget := newMemoryBudget(10, budgetFail)
+	first, second := budget.reservation(), budget.reservation()
+	if err := first.reserve(8); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := second.reserve(4); err == nil {
+		t.Errorf("expected an error exceeding the budget")
+	}
+	first.release()
+	if err := second.reserve(4); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := second.reserve(7); err == nil || !strings.Contains(err.Error(), "requires more than") {
+		t.Errorf("expected an error for a render larger than the budget, got: %v", err)
+	}
+}
+
+func TestMemoryBudgetQueue(t *testing.T) {
+	budget := newMemoryBudget(10, budgetQueue)
+	first, second := budget.reservation(), budget.reservation()
+	if err := first.reserve(8); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- second.reserve(4)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("expected the reservation to wait for the budget")
+	case <-time.After(50 * time.Millisecond):
+	}
+	first.release()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestMemoryBudgetQueueDeadlock(t *testing.T) {
+	budget := newMemoryBudget(10, budgetQueue)
+	first, second := budget.reservation(), budget.reservation()
+	first.reserve(5)
+	second.reserve(4)
+	done := make(chan error)
+	go func() {
+		done <- first.reserve(3)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	// step: both holders waiting on each other would never end, so the latest fails
+	if err := second.reserve(3); err == nil {
+		t.Errorf("expected an error when every holder is waiting")
+	}
+	second.release()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestMemoryBudgetQueueSharedReservation(t *testing.T) {
+	budget := newMemoryBudget(10, budgetQueue)
+	shared, other := budget.reservation(), budget.reservation()
+	shared.reserve(3)
+	other.reserve(6)
+	// step: the workers of one render share the reservation, so while the other render is running
+	// the second waiting worker must not be taken as every holder waiting
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			done <- shared.reserve(2)
+		}()
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected the workers to wait for the budget, got: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	other.release()
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+}
+
+func TestReservationWriter(t *testing.T) {
+	var none *reservation
+	buffer := new(bytes.Buffer)
+	if none.writer(buffer) != buffer {
+		t.Errorf("expected the writer unwrapped without a budget")
+	}
+	r := newMemoryBudget(4, budgetFail).reservation()
+	w := r.writer(buffer)
+	if _, err := fmt.Fprint(w, "abc"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := fmt.Fprint(w, "de"); err == nil {
+		t.Errorf("expected an error exceeding the budget")
+	}
+	if buffer.String() != "abc" {
+		t.Errorf("unexpected content: %q", buffer.String())
+	}
+}
+
+func TestGoTemplateMemoryBudget(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "gotemplate" {
+						memory_budget = 64
+					}
+					data "gotemplate_file" "test" {
+						template = "{{ printf \"%0100d\" 0 }}"
+					}`,
+				ExpectError: regexp.MustCompile("requires more than the memory budget"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateConcatMemoryBudget(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "gotemplate" {
+						memory_budget = 64
+					}
+					data "gotemplate_concat" "test" {
+						fragment {
+							template = "{{ printf \"%0100d\" 0 }}"
+						}
+					}`,
+				ExpectError: regexp.MustCompile("requires more than the memory budget"),
+			},
+			{
+				Config: `
+					provider "gotemplate" {
+						memory_budget = 64
+					}
+					data "gotemplate_concat" "test" {
+						fragment {
+							content = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+						}
+					}`,
+				ExpectError: regexp.MustCompile("requires more than the memory budget"),
+			},
+			{
+				Config: `
+					provider "gotemplate" {
+						memory_budget = 64
+					}
+					data "gotemplate_concat" "test" {
+						fragment {
+							content = "a"
+						}
+						fragment {
+							content = "b"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_concat.test", "rendered", "a\nb"),
+			},
+		},
+	})
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// checksumSHA256 is the default checksum algorithm
+	checksumSHA256 = "sha256"
+	// checksumSHA512 is the sha512 checksum algorithm
+	checksumSHA512 = "sha512"
+	// checksumBlake2b is the 256 bit blake2b checksum algorithm
+	checksumBlake2b = "blake2b"
+	// checksumMD5 is the md5 checksum algorithm, only for systems requiring it
+	checksumMD5 = "md5"
+)
+
+// checksumAlgorithms maps the checksum algorithm to the sum of the content
+var checksumAlgorithms = map[string]func([]byte) []byte{
+	checksumSHA256: func(content []byte) []byte {
+		sum := sha256.Sum256(content)
+		return sum[:]
+	},
+	checksumSHA512: func(content []byte) []byte {
+		sum := sha512.Sum512(content)
+		return sum[:]
+	},
+	checksumBlake2b: func(content []byte) []byte {
+		sum := blake2b.Sum256(content)
+		return sum[:]
+	},
+	checksumMD5: func(content []byte) []byte {
+		sum := md5.Sum(content)
+		return sum[:]
+	},
+}
+
+// checksum returns the hex encoded checksum of the content in the algorithm, having been validated
+// by the schema
+func checksum(algorithm, content string) string {
+	return hex.EncodeToString(checksumAlgorithms[algorithm]([]byte(content)))
+}
+
+// checksumAlgorithmSchema returns the schema of the checksum_algorithm attribute
+func checksumAlgorithmSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      checksumSHA256,
+		ValidateFunc: validation.StringInSlice([]string{checksumSHA256, checksumSHA512, checksumBlake2b, checksumMD5}, false),
+		Description:  "The algorithm of the id and checksums, i.e. sha256, sha512, blake2b or md5",
+	}
+}
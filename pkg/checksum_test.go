@@ -0,0 +1,58 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestChecksum(t *testing.T) {
+	expected := map[string]string{
+		checksumSHA256:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		checksumSHA512:  "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e",
+		checksumBlake2b: "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		checksumMD5:     "d41d8cd98f00b204e9800998ecf8427e",
+	}
+	for algorithm, sum := range expected {
+		if got := checksum(algorithm, ""); got != sum {
+			t.Errorf("algorithm %s, got: %s, want: %s", algorithm, got, sum)
+		}
+	}
+	if checksum(checksumSHA256, "hello") != hash("hello") {
+		t.Error("expected the default algorithm to match the hash of the id")
+	}
+}
+
+func TestGoTemplateChecksumAlgorithm(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template           = "hello"
+						checksum_algorithm = "md5"
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "checksum", "5d41402abc4b2a76b9719d911017c592"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "id", "5d41402abc4b2a76b9719d911017c592"),
+				),
+			},
+		},
+	})
+}
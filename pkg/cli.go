@@ -0,0 +1,54 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// Render is responsible for rendering a template exactly as the gotemplate_file data source of a
+// provider left to its defaults does, the arguments being those of the data source. It backs the
+// gotemplate cli, so the template authors see the output terraform would render
+func Render(arguments map[string]interface{}) (string, error) {
+	provider := Provider().(*schema.Provider)
+	if err := provider.Configure(terraform.NewResourceConfig(nil)); err != nil {
+		return "", err
+	}
+	raw, err := config.NewRawConfig(arguments)
+	if err != nil {
+		return "", err
+	}
+	c := terraform.NewResourceConfig(raw)
+
+	source := provider.DataSourcesMap["gotemplate_file"]
+	if _, errs := source.Validate(c); len(errs) > 0 {
+		return "", fmt.Errorf("invalid arguments, error: %s", errs[0])
+	}
+	diff, err := source.Diff(nil, c, provider.Meta())
+	if err != nil {
+		return "", err
+	}
+	state, err := source.ReadDataApply(diff, provider.Meta())
+	if err != nil {
+		return "", err
+	}
+
+	return state.Attributes["rendered"], nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// commandRunner runs the external commands of the resources, permitting only those allowed by
+// the provider configuration
+type commandRunner struct {
+	// allowed are the executables which may be run, running commands being disabled when empty
+	allowed map[string]bool
+}
+
+// newCommandRunner returns a runner permitting the executables
+func newCommandRunner(allowed []string) *commandRunner {
+	runner := &commandRunner{allowed: make(map[string]bool)}
+	for _, x := range allowed {
+		runner.allowed[x] = true
+	}
+
+	return runner
+}
+
+// run is responsible for running the command, given as the executable and its arguments without a
+// shell, failing with the combined output when it exits non-zero or outlives the timeout
+func (c *commandRunner) run(command []string, stdin io.Reader, env []string, timeout time.Duration) error {
+	name := strings.Join(command, " ")
+	if c == nil || !c.allowed[command[0]] {
+		return fmt.Errorf("the command: %s is not permitted, the provider allowed_commands must include: %s", name, command[0])
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	output := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+	cmd.Stdout = output
+	cmd.Stderr = output
+	started := time.Now()
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		logEvent(logError, "command failed", "command", name, "duration", time.Since(started), "error", err)
+		return fmt.Errorf("the command: %s failed, error: %s, output:\n%s", name, err, strings.TrimSpace(output.String()))
+	}
+	logEvent(logDebug, "command succeeded", "command", name, "duration", time.Since(started))
+
+	return nil
+}
+
+// runPostWriteCommand runs the post_write_command of the resource, if any, after the file is written;
+// on failure the hash of the written content is cleared so the next plan retries the write
+func runPostWriteCommand(d *schema.ResourceData, meta interface{}, filename string) error {
+	command := toStrings(d.Get("post_write_command").([]interface{}))
+	if len(command) == 0 {
+		return nil
+	}
+	timeout := time.Duration(d.Get("command_timeout").(int)) * time.Second
+	if err := commandsOf(meta).run(command, nil, []string{"GOTEMPLATE_FILENAME=" + filename}, timeout); err != nil {
+		d.Set("content_sha256", "")
+		return err
+	}
+
+	return nil
+}
+
+// runValidateCommand runs the validate_command of the data source, if any, with the rendered
+// template on stdin
+func runValidateCommand(d *schema.ResourceData, meta interface{}, rendered string) error {
+	command := toStrings(d.Get("validate_command").([]interface{}))
+	if len(command) == 0 {
+		return nil
+	}
+	timeout := time.Duration(d.Get("command_timeout").(int)) * time.Second
+	if err := commandsOf(meta).run(command, strings.NewReader(rendered), nil, timeout); err != nil {
+		return fmt.Errorf("the rendered template failed validation, error: %s", err)
+	}
+
+	return nil
+}
+
+// commandsOf returns the command runner from the provider configuration, if any
+func commandsOf(meta interface{}) *commandRunner {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.commands
+	}
+
+	return nil
+}
+
+// postWriteCommandSchema returns the schema of the post_write_command attribute of the file resources
+func postWriteCommandSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "A command and its arguments run without a shell after the file is written, i.e. to validate or reload; the executable must be in the provider allowed_commands and GOTEMPLATE_FILENAME holds the path of the file",
+	}
+}
+
+// commandTimeoutSchema returns the schema of the command_timeout attribute, bounding both the
+// post_write_command of the file resources and the validate_command of the gotemplate_file data
+// source
+func commandTimeoutSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      60,
+		ValidateFunc: validation.IntAtLeast(1),
+		Description:  "The seconds a command is given to complete before failing",
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestCommandRunner(t *testing.T) {
+	runner := newCommandRunner([]string{"sh"})
+	if err := runner.run([]string{"sh", "-c", `test "$NAME" = web`}, nil, []string{"NAME=web"}, time.Second); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := runner.run([]string{"sh", "-c", "echo invalid config >&2; exit 3"}, nil, nil, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "invalid config") {
+		t.Errorf("expected the failure with its output, got: %v", err)
+	}
+	if err := runner.run([]string{"sh", "-c", "sleep 5"}, nil, nil, 100*time.Millisecond); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got: %v", err)
+	}
+	if err := runner.run([]string{"true"}, nil, nil, time.Second); err == nil || !strings.Contains(err.Error(), "not permitted") {
+		t.Errorf("expected the command to be refused, got: %v", err)
+	}
+	var disabled *commandRunner
+	if err := disabled.run([]string{"sh"}, nil, nil, time.Second); err == nil || !strings.Contains(err.Error(), "not permitted") {
+		t.Errorf("expected the command to be refused without a provider, got: %v", err)
+	}
+}
+
+func TestGoTemplateLocalFilePostWriteCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.conf")
+	copied := filepath.Join(dir, "copied.conf")
+
+	config := func(allowed string) string {
+		return `
+			provider "gotemplate" {
+				allowed_commands = [` + allowed + `]
+			}
+			resource "gotemplate_local_file" "test" {
+				filename           = "` + filename + `"
+				template           = "name: web"
+				post_write_command = ["sh", "-c", "cp \"$GOTEMPLATE_FILENAME\" ` + copied + `"]
+			}`
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      config(""),
+				ExpectError: regexp.MustCompile("is not permitted"),
+			},
+			{
+				Config: config(`"sh"`),
+				Check: func(*terraform.State) error {
+					content, err := ioutil.ReadFile(copied)
+					if err != nil {
+						return err
+					}
+					if string(content) != "name: web" {
+						return fmt.Errorf("copied file holds: %q", content)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestGoTemplateValidateCommand(t *testing.T) {
+	config := func(name string) string {
+		return `
+			provider "gotemplate" {
+				allowed_commands = ["grep"]
+			}
+			data "gotemplate_file" "test" {
+				template         = "name: {{ .name }}"
+				validate_command = ["grep", "-q", "^name: web$"]
+				vars {
+					name = "` + name + `"
+				}
+			}`
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("web"),
+				Check:  resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "name: web"),
+			},
+			{
+				Config:      config("api"),
+				ExpectError: regexp.MustCompile("failed validation(.|\n)*exit status 1"),
+			},
+		},
+	})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// compressionNone leaves the content uncompressed
+	compressionNone = "none"
+	// compressionGzip is gzip compression
+	compressionGzip = "gzip"
+	// compressionZstd is zstandard compression
+	compressionZstd = "zstd"
+	// compressionBrotli is brotli compression
+	compressionBrotli = "brotli"
+)
+
+// compressionCodecs is a list of the compression codecs we support
+var compressionCodecs = []string{compressionNone, compressionGzip, compressionZstd, compressionBrotli}
+
+// compressOutput is responsible for compressing the content with the codec
+func compressOutput(content []byte, codec string) ([]byte, error) {
+	if codec == compressionNone || codec == "" {
+		return content, nil
+	}
+	buffer := new(bytes.Buffer)
+	w, err := newCompressWriter(buffer, codec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// newCompressWriter returns a writer compressing with the codec, the output being complete once
+// the writer is closed
+func newCompressWriter(w io.Writer, codec string) (io.WriteCloser, error) {
+	switch codec {
+	case compressionNone, "":
+		return nopWriteCloser{w}, nil
+	case compressionGzip:
+		return gzip.NewWriterLevel(w, gzip.BestCompression)
+	case compressionZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	case compressionBrotli:
+		return brotli.NewWriterLevel(w, brotli.BestCompression), nil
+	}
+
+	return nil, fmt.Errorf("unsupported compression codec: %s", codec)
+}
+
+// nopWriteCloser is a writer with nothing to do on close
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressOutput(t *testing.T) {
+	content := []byte(strings.Repeat("#cloud-config\n", 100))
+
+	readers := map[string]func(io.Reader) (io.Reader, error){
+		compressionGzip: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		compressionZstd: func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r)
+		},
+		compressionBrotli: func(r io.Reader) (io.Reader, error) {
+			return brotli.NewReader(r), nil
+		},
+	}
+	for codec, reader := range readers {
+		compressed, err := compressOutput(content, codec)
+		if err != nil {
+			t.Errorf("codec: %s, unexpected error: %s", codec, err)
+			continue
+		}
+		if len(compressed) >= len(content) {
+			t.Errorf("codec: %s, expected the content to be compressed", codec)
+		}
+		r, err := reader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Errorf("codec: %s, unexpected error: %s", codec, err)
+			continue
+		}
+		decompressed, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("codec: %s, unexpected error: %s", codec, err)
+			continue
+		}
+		if !bytes.Equal(decompressed, content) {
+			t.Errorf("codec: %s, the decompressed content does not match", codec)
+		}
+	}
+}
+
+func TestCompressOutputNone(t *testing.T) {
+	content := []byte("hello")
+	if compressed, _ := compressOutput(content, compressionNone); !bytes.Equal(compressed, content) {
+		t.Errorf("expected the content untouched, got: %v", compressed)
+	}
+	if _, err := compressOutput(content, "lzma"); err == nil {
+		t.Error("expected an error for an unsupported codec")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// consulSchema returns the provider block configuring the consul client, any setting left unset
+// falling back to the standard CONSUL_HTTP_* environment variables
+func consulSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "The connection settings of the consul agent used by the consul resources",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"address": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The address of the consul agent, i.e. 127.0.0.1:8500",
+				},
+				"scheme": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The scheme used to talk to the agent, either http or https",
+				},
+				"datacenter": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The default datacenter of the requests",
+				},
+				"token": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "The acl token used to authenticate the requests",
+				},
+				"ca_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The path to a pem encoded certificate authority used to verify the agent",
+				},
+				"cert_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The path to a pem encoded client certificate",
+				},
+				"key_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The path to the pem encoded private key of the client certificate",
+				},
+			},
+		},
+	}
+}
+
+// consulConfig returns the consul client configuration from the provider block, if any
+func consulConfig(blocks []interface{}) api.Config {
+	var config api.Config
+	if len(blocks) == 0 || blocks[0] == nil {
+		return config
+	}
+	block := blocks[0].(map[string]interface{})
+	config.Address = block["address"].(string)
+	config.Scheme = block["scheme"].(string)
+	config.Datacenter = block["datacenter"].(string)
+	config.Token = block["token"].(string)
+	config.TLSConfig.CAFile = block["ca_file"].(string)
+	config.TLSConfig.CertFile = block["cert_file"].(string)
+	config.TLSConfig.KeyFile = block["key_file"].(string)
+
+	return config
+}
+
+// consulOf returns a consul client from the provider configuration; the client is created per
+// call as it holds no connection until used
+func consulOf(meta interface{}) (*api.Client, error) {
+	config := api.Config{}
+	if provider, ok := meta.(*providerConfig); ok {
+		config = provider.consul
+	}
+
+	return api.NewClient(&config)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"testing"
+)
+
+func TestConsulConfig(t *testing.T) {
+	config := consulConfig([]interface{}{map[string]interface{}{
+		"address":    "consul:8500",
+		"scheme":     "https",
+		"datacenter": "dc1",
+		"token":      "secret",
+		"ca_file":    "ca.pem",
+		"cert_file":  "",
+		"key_file":   "",
+	}})
+	if config.Address != "consul:8500" || config.Scheme != "https" || config.Datacenter != "dc1" || config.Token != "secret" || config.TLSConfig.CAFile != "ca.pem" {
+		t.Errorf("unexpected config: %#v", config)
+	}
+	if config := consulConfig(nil); config.Address != "" {
+		t.Errorf("expected an empty config, got: %#v", config)
+	}
+	if _, err := consulOf(nil); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// contextVar is the name of the variable holding the render context
+const contextVar = "gotemplate"
+
+// Version is the version of the provider, set at build time
+var Version = "dev"
+
+// templateContext returns the metadata exposed to the templates as .gotemplate; the workspace is
+// taken from TF_WORKSPACE, as terraform does not pass it to providers, and the module path is the
+// base path if given, otherwise the working directory. A frozen timestamp must be in RFC3339 format
+// and the profile is that selected on the provider, if any
+func templateContext(basePath, timestamp, profile string) (map[string]interface{}, error) {
+	workspace := os.Getenv("TF_WORKSPACE")
+	if workspace == "" {
+		workspace = "default"
+	}
+	modulePath := basePath
+	if modulePath == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		modulePath = wd
+	}
+	renderedAt := time.Now().UTC()
+	if timestamp != "" {
+		frozen, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid render_timestamp, expected RFC3339, error: %s", err)
+		}
+		renderedAt = frozen.UTC()
+	}
+
+	return map[string]interface{}{
+		"workspace":        workspace,
+		"module_path":      modulePath,
+		"profile":          profile,
+		"provider_version": Version,
+		"timestamp":        renderedAt.Format(time.RFC3339),
+	}, nil
+}
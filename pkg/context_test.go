@@ -0,0 +1,104 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestTemplateContext(t *testing.T) {
+	os.Setenv("TF_WORKSPACE", "staging")
+	defer os.Unsetenv("TF_WORKSPACE")
+
+	context, err := templateContext("/modules/app", "2017-06-01T12:00:00+01:00", "prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"workspace":        "staging",
+		"module_path":      "/modules/app",
+		"profile":          "prod",
+		"provider_version": Version,
+		"timestamp":        "2017-06-01T11:00:00Z",
+	}
+	for k, v := range expected {
+		if context[k] != v {
+			t.Errorf("%s, got: %v, want: %v", k, context[k], v)
+		}
+	}
+}
+
+func TestTemplateContextDefaults(t *testing.T) {
+	os.Unsetenv("TF_WORKSPACE")
+	context, err := templateContext("", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if context["workspace"] != "default" {
+		t.Errorf("expected the default workspace, got: %v", context["workspace"])
+	}
+	if wd, _ := os.Getwd(); context["module_path"] != wd {
+		t.Errorf("expected the working directory, got: %v", context["module_path"])
+	}
+	if _, err := templateContext("", "yesterday", ""); err == nil {
+		t.Errorf("expected an error for an invalid timestamp")
+	}
+}
+
+func TestGoTemplateContext(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						base_path        = "/modules/app"
+						template         = "# generated by gotemplate {{ .gotemplate.provider_version }} from {{ .gotemplate.module_path }} at {{ .gotemplate.timestamp }}"
+						render_timestamp = "2017-06-01T11:00:00Z"
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered",
+					"# generated by gotemplate dev from /modules/app at 2017-06-01T11:00:00Z"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateContextNotCoerced(t *testing.T) {
+	defer func(version string) { Version = version }(Version)
+	Version = "1.10"
+	os.Setenv("TF_WORKSPACE", "2020")
+	defer os.Unsetenv("TF_WORKSPACE")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template     = "{{ .gotemplate.provider_version }} {{ printf \"%T\" .gotemplate.workspace }} {{ .replicas }}"
+						coerce_types = true
+						vars {
+							replicas = "3"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "1.10 string 3"),
+			},
+		},
+	})
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+// renderDefaults are the render settings of the provider block, used by the data sources which
+// do not set their own
+type renderDefaults struct {
+	// strict fails the render on references to missing variables
+	strict bool
+	// leftDelim and rightDelim are the action delimiters
+	leftDelim  string
+	rightDelim string
+	// snippets is the path to a directory containing snippets
+	snippets string
+	// disableFuncs are the names of the template functions removed
+	disableFuncs []string
+	// profile is the name of the selected profile, if any
+	profile string
+	// vars are the variables of the profile passed beneath those of every template
+	vars map[string]interface{}
+	// snippetPaths are the snippet directories of the profile loaded before those of the data source
+	snippetPaths []string
+}
+
+// defaultsOf returns the render defaults from the provider configuration, if any
+func defaultsOf(meta interface{}) renderDefaults {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.defaults
+	}
+
+	return renderDefaults{}
+}
+
+// apply is responsible for filling in the options left unset by the data source
+func (r renderDefaults) apply(options *render.Options) {
+	if options.Snippets == "" {
+		options.Snippets = r.snippets
+	}
+	options.SnippetPaths = append(append([]string{}, r.snippetPaths...), options.SnippetPaths...)
+	if options.LeftDelim == "" {
+		options.LeftDelim = r.leftDelim
+	}
+	if options.RightDelim == "" {
+		options.RightDelim = r.rightDelim
+	}
+	options.DisableFuncs = unionStrings(r.disableFuncs, options.DisableFuncs)
+}
+
+// renderSettings is responsible for reading the render settings of the data source over the
+// provider defaults; strict is only taken from the data source when explicitly set, and the
+// disabled functions are added to those of the provider, which a data source cannot re-enable
+func renderSettings(d templateData, meta interface{}, options *render.Options) error {
+	defaults := defaultsOf(meta)
+	options.Strict = defaults.strict
+	if strict, found := d.GetOkExists("strict"); found {
+		options.Strict = strict.(bool)
+	}
+	options.LeftDelim = d.Get("left_delimiter").(string)
+	options.RightDelim = d.Get("right_delimiter").(string)
+	options.DisableFuncs = toStrings(d.Get("disable_functions").([]interface{}))
+	if err := checkFunctionNames(options.DisableFuncs); err != nil {
+		return err
+	}
+	defaults.apply(options)
+
+	return nil
+}
+
+// checkFunctionNames checks each of the names is a template function
+func checkFunctionNames(names []string) error {
+	funcs := render.Funcs()
+	for _, name := range names {
+		if _, found := funcs[name]; !found {
+			return fmt.Errorf("unknown template function: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// unionStrings returns the values of both lists without duplicates, in the order first seen
+func unionStrings(a, b []string) []string {
+	var values []string
+	seen := make(map[string]bool, len(a)+len(b))
+	for _, x := range append(append([]string{}, a...), b...) {
+		if !seen[x] {
+			seen[x] = true
+			values = append(values, x)
+		}
+	}
+
+	return values
+}
+
+// toStrings converts the list to strings
+func toStrings(list []interface{}) []string {
+	var values []string
+	for _, x := range list {
+		values = append(values, x.(string))
+	}
+
+	return values
+}
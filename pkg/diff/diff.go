@@ -0,0 +1,199 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff provides a line based unified diff between two documents
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Context is the number of unchanged lines shown around each change
+const Context = 3
+
+type operation int
+
+const (
+	equal operation = iota
+	remove
+	insert
+)
+
+// edit is a single line operation in the edit script
+type edit struct {
+	op   operation
+	line string
+}
+
+// Unified returns a unified diff of the two documents, or an empty string if they are the same
+func Unified(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	edits := computeEdits(splitLines(from), splitLines(to))
+
+	// step: calculate the line positions for each edit
+	fromLine := make([]int, len(edits)+1)
+	toLine := make([]int, len(edits)+1)
+	for i, x := range edits {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if x.op != insert {
+			fromLine[i+1]++
+		}
+		if x.op != remove {
+			toLine[i+1]++
+		}
+	}
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(edits) {
+		fromCount := fromLine[h[1]] - fromLine[h[0]]
+		toCount := toLine[h[1]] - toLine[h[0]]
+		fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n",
+			hunkStart(fromLine[h[0]], fromCount), fromCount,
+			hunkStart(toLine[h[0]], toCount), toCount)
+
+		for _, x := range edits[h[0]:h[1]] {
+			prefix := " "
+			switch x.op {
+			case remove:
+				prefix = "-"
+			case insert:
+				prefix = "+"
+			}
+			out.WriteString(prefix + x.line)
+			if !strings.HasSuffix(x.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// hunkStart returns the starting line number of a hunk
+func hunkStart(before, count int) int {
+	if count == 0 {
+		return before
+	}
+
+	return before + 1
+}
+
+// hunks groups the changes into ranges of edits, including the surrounding context
+func hunks(edits []edit) [][2]int {
+	var list [][2]int
+	for i, x := range edits {
+		if x.op == equal {
+			continue
+		}
+		start, end := i-Context, i+Context+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(edits) {
+			end = len(edits)
+		}
+		if len(list) > 0 && start <= list[len(list)-1][1] {
+			list[len(list)-1][1] = end
+			continue
+		}
+		list = append(list, [2]int{start, end})
+	}
+
+	return list
+}
+
+// splitLines splits the content into lines, retaining the line endings
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// computeEdits returns the shortest edit script between the two lists using the myers algorithm
+func computeEdits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	// step: walk back through the trace to build the edit script
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{op: equal, line: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{op: insert, line: b[y-1]})
+			} else {
+				edits = append(edits, edit{op: remove, line: a[x-1]})
+			}
+			x, y = prevX, prevY
+		}
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"testing"
+)
+
+func TestUnifiedSame(t *testing.T) {
+	if d := Unified("a", "b", "same\n", "same\n"); d != "" {
+		t.Errorf("expected no diff, got: %q", d)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	cases := []struct {
+		From     string
+		To       string
+		Expected string
+	}{
+		{
+			From:     "a\nb\nc\n",
+			To:       "a\nB\nc\n",
+			Expected: "--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			From:     "",
+			To:       "a\n",
+			Expected: "--- from\n+++ to\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			From:     "a\n",
+			To:       "a",
+			Expected: "--- from\n+++ to\n@@ -1,1 +1,1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+		{
+			From: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			To:   "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			Expected: "--- from\n+++ to\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n" +
+				"@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+	}
+	for i, x := range cases {
+		if d := Unified("from", "to", x.From, x.To); d != x.Expected {
+			t.Errorf("case %d, got:\n%s\nwant:\n%s", i, d, x.Expected)
+		}
+	}
+}
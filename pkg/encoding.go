@@ -0,0 +1,180 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// encodingUTF8 is utf-8 encoding
+	encodingUTF8 = "utf-8"
+	// encodingUTF16LE is little endian utf-16 encoding
+	encodingUTF16LE = "utf-16le"
+	// encodingLatin1 is iso-8859-1 encoding
+	encodingLatin1 = "latin-1"
+)
+
+// outputEncodings is a list of the output encodings we support
+var outputEncodings = []string{encodingUTF8, encodingUTF16LE, encodingLatin1}
+
+// encodeOutput is responsible for converting the content into the given encoding
+func encodeOutput(content, encoding string) ([]byte, error) {
+	if encoding == encodingUTF8 || encoding == "" {
+		return []byte(content), nil
+	}
+	buffer := new(bytes.Buffer)
+	w, err := newEncodingWriter(buffer, encoding)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// encodingWriter converts the utf-8 written to it into the encoding as it is written, holding
+// back any incomplete character at the end of a write until the next
+type encodingWriter struct {
+	w        io.Writer
+	encoding string
+	// pending is the start of a character split across writes
+	pending []byte
+	// offset is the number of bytes encoded so far
+	offset int
+}
+
+// newEncodingWriter returns a writer encoding to the given encoding
+func newEncodingWriter(w io.Writer, encoding string) (*encodingWriter, error) {
+	switch encoding {
+	case encodingUTF8, encodingUTF16LE, encodingLatin1, "":
+		return &encodingWriter{w: w, encoding: encoding}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported output encoding: %s", encoding)
+}
+
+// Write encodes the complete characters of the content
+func (e *encodingWriter) Write(p []byte) (int, error) {
+	if e.encoding == encodingUTF8 || e.encoding == "" {
+		return e.w.Write(p)
+	}
+	content := append(e.pending, p...)
+	end := len(content)
+	for i := len(content) - 1; i >= 0 && i >= len(content)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(content[i]) {
+			if !utf8.FullRune(content[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+	if err := e.encode(content[:end]); err != nil {
+		return 0, err
+	}
+	e.pending = append([]byte(nil), content[end:]...)
+
+	return len(p), nil
+}
+
+// Flush encodes any incomplete character held back, which being invalid becomes the replacement
+// character
+func (e *encodingWriter) Flush() error {
+	pending := e.pending
+	e.pending = nil
+
+	return e.encode(pending)
+}
+
+// encode writes the content in the encoding
+func (e *encodingWriter) encode(content []byte) error {
+	if len(content) == 0 {
+		return nil
+	}
+	var encoded []byte
+	switch e.encoding {
+	case encodingUTF16LE:
+		units := utf16.Encode([]rune(string(content)))
+		encoded = make([]byte, len(units)*2)
+		for i, x := range units {
+			binary.LittleEndian.PutUint16(encoded[i*2:], x)
+		}
+	case encodingLatin1:
+		encoded = make([]byte, 0, len(content))
+		for i, x := range string(content) {
+			if x > 0xff {
+				return fmt.Errorf("character %q at offset %d cannot be represented in latin-1", x, e.offset+i)
+			}
+			encoded = append(encoded, byte(x))
+		}
+	}
+	e.offset += len(content)
+	_, err := e.w.Write(encoded)
+
+	return err
+}
+
+// byteOrderMark returns the byte order mark for the encoding
+func byteOrderMark(encoding string) ([]byte, error) {
+	switch encoding {
+	case encodingUTF8, "":
+		return []byte{0xef, 0xbb, 0xbf}, nil
+	case encodingUTF16LE:
+		return []byte{0xff, 0xfe}, nil
+	}
+
+	return nil, fmt.Errorf("the %s encoding does not have a byte order mark", encoding)
+}
+
+// decodeOutput is responsible for converting content in the given encoding back into utf-8, the
+// leading byte order mark of the encoding being dropped when one was written; otherwise a leading
+// U+FEFF is the start of the rendered content and kept
+func decodeOutput(content []byte, encoding string, withBOM bool) (string, error) {
+	if bom, err := byteOrderMark(encoding); err == nil && withBOM {
+		content = bytes.TrimPrefix(content, bom)
+	}
+	switch encoding {
+	case encodingUTF8, "":
+		return string(content), nil
+	case encodingUTF16LE:
+		if len(content)%2 != 0 {
+			return "", fmt.Errorf("the content is not valid utf-16le, having an odd number of bytes")
+		}
+		units := make([]uint16, len(content)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(content[i*2:])
+		}
+		return string(utf16.Decode(units)), nil
+	case encodingLatin1:
+		runes := make([]rune, len(content))
+		for i, x := range content {
+			runes[i] = rune(x)
+		}
+		return string(runes), nil
+	}
+
+	return "", fmt.Errorf("unsupported output encoding: %s", encoding)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeOutput(t *testing.T) {
+	cases := []struct {
+		Content  string
+		Encoding string
+		Expected []byte
+	}{
+		{Content: "héllo", Encoding: encodingUTF8, Expected: []byte("héllo")},
+		{Content: "hé", Encoding: encodingUTF16LE, Expected: []byte{'h', 0, 0xe9, 0}},
+		{Content: "😀", Encoding: encodingUTF16LE, Expected: []byte{0x3d, 0xd8, 0x00, 0xde}},
+		{Content: "hé", Encoding: encodingLatin1, Expected: []byte{'h', 0xe9}},
+	}
+	for i, x := range cases {
+		encoded, err := encodeOutput(x.Content, x.Encoding)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if !bytes.Equal(encoded, x.Expected) {
+			t.Errorf("case %d, got: %v, want: %v", i, encoded, x.Expected)
+		}
+	}
+}
+
+func TestEncodeOutputErrors(t *testing.T) {
+	if _, err := encodeOutput("h€", encodingLatin1); err == nil {
+		t.Error("expected an error for characters outside of latin-1")
+	}
+	if _, err := encodeOutput("h", "ebcdic"); err == nil {
+		t.Error("expected an error for an unsupported encoding")
+	}
+}
+
+func TestByteOrderMark(t *testing.T) {
+	if bom, _ := byteOrderMark(encodingUTF8); !bytes.Equal(bom, []byte{0xef, 0xbb, 0xbf}) {
+		t.Errorf("unexpected utf-8 bom: %v", bom)
+	}
+	if bom, _ := byteOrderMark(encodingUTF16LE); !bytes.Equal(bom, []byte{0xff, 0xfe}) {
+		t.Errorf("unexpected utf-16le bom: %v", bom)
+	}
+	if _, err := byteOrderMark(encodingLatin1); err == nil {
+		t.Error("expected an error for latin-1")
+	}
+}
+
+func TestDecodeOutput(t *testing.T) {
+	cases := []struct {
+		Content  []byte
+		Encoding string
+		BOM      bool
+		Expected string
+	}{
+		{Content: []byte("héllo"), Encoding: encodingUTF8, Expected: "héllo"},
+		{Content: []byte{0xef, 0xbb, 0xbf, 'h'}, Encoding: encodingUTF8, BOM: true, Expected: "h"},
+		{Content: []byte{0xef, 0xbb, 0xbf, 'h'}, Encoding: encodingUTF8, Expected: "\ufeffh"},
+		{Content: []byte{0xff, 0xfe, 'h', 0, 0xe9, 0}, Encoding: encodingUTF16LE, BOM: true, Expected: "hé"},
+		{Content: []byte{0xff, 0xfe, 'h', 0}, Encoding: encodingUTF16LE, Expected: "\ufeffh"},
+		{Content: []byte{0x3d, 0xd8, 0x00, 0xde}, Encoding: encodingUTF16LE, Expected: "😀"},
+		{Content: []byte{'h', 0xe9}, Encoding: encodingLatin1, Expected: "hé"},
+	}
+	for i, x := range cases {
+		decoded, err := decodeOutput(x.Content, x.Encoding, x.BOM)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if decoded != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, decoded, x.Expected)
+		}
+	}
+	if _, err := decodeOutput([]byte{'h'}, encodingUTF16LE, false); err == nil {
+		t.Error("expected an error for an odd number of utf-16le bytes")
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/diff"
+)
+
+func goDataSourceAssert() *schema.Resource {
+	s := dataSourceTemplateSchema()
+	s["expected"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		Description: "The expected content, or path to a file containing it, of the rendered template",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The rendered template",
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceAssertRead,
+		Schema: s,
+	}
+}
+
+// dataSourceAssertRead is responsible for rendering the template and comparing it to the expected content
+func dataSourceAssertRead(d *schema.ResourceData, meta interface{}) error {
+	semaphore := semaphoreOf(meta)
+	semaphore.acquire()
+	defer semaphore.release()
+
+	buffer := new(bytes.Buffer)
+	scoped, err := renderGoTemplateTo(d, meta, buffer)
+	if err != nil {
+		return err
+	}
+	rendered := buffer.String()
+	expected, err := readPathOrContents(d.Get("base_path").(string), d.Get("expected").(string))
+	if err != nil {
+		return err
+	}
+	// step: the diff holds the rendered lines, so the sensitive values of the render are redacted
+	if changes := diff.Unified("expected", "rendered", expected, rendered); changes != "" {
+		return fmt.Errorf("rendered template does not match the expected content:\n%s", scoped.redact(changes))
+	}
+	d.Set("rendered", rendered)
+	d.SetId(hash(rendered))
+
+	return nil
+}
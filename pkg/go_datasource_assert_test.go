@@ -0,0 +1,82 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoDataSourceAssert(t *testing.T) {
+	resource := goDataSourceAssert()
+	if resource == nil {
+		t.Error("we should have recieved the provider schema")
+	}
+}
+
+func TestGoTemplateAssert(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_assert" "test" {
+						template = "Hello {{ .name }}"
+						expected = "Hello rohith"
+						vars     = { name = "rohith" }
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_assert.test", "rendered", "Hello rohith"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateAssertMismatch(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_assert" "test" {
+						template = "Hello {{ .name }}"
+						expected = "Hello world"
+						vars     = { name = "rohith" }
+					}`,
+				ExpectError: regexp.MustCompile(`(?s)does not match.*-Hello world.*\+Hello rohith`),
+			},
+		},
+	})
+}
+
+func TestGoTemplateAssertRedacted(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_assert" "test" {
+						template       = "password: {{ .password }}"
+						expected       = "password: changeme"
+						vars           = { password = "hunter2secret" }
+						sensitive_vars = ["password"]
+					}`,
+				ExpectError: regexp.MustCompile(`(?s)-password: changeme.*\+password: <redacted>`),
+			},
+		},
+	})
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// defaultBoundary is the default mime boundary between the parts
+const defaultBoundary = "MIMEBOUNDARY"
+
+// cloudInitContentTypes are the content types cloud-init dispatches a part to by the start of its
+// content, ordered longest prefix first as cloud-init matches them
+var cloudInitContentTypes = []struct {
+	Prefix      string
+	ContentType string
+}{
+	{Prefix: "#cloud-config-archive", ContentType: "text/cloud-config-archive"},
+	{Prefix: "#cloud-config-jsonp", ContentType: "text/cloud-config-jsonp"},
+	{Prefix: "## template: jinja", ContentType: "text/jinja2"},
+	{Prefix: "#cloud-boothook", ContentType: "text/cloud-boothook"},
+	{Prefix: "#include-once", ContentType: "text/x-include-once-url"},
+	{Prefix: "#cloud-config", ContentType: "text/cloud-config"},
+	{Prefix: "#part-handler", ContentType: "text/part-handler"},
+	{Prefix: "#upstart-job", ContentType: "text/upstart-job"},
+	{Prefix: "#include", ContentType: "text/x-include-url"},
+	{Prefix: "#!", ContentType: "text/x-shellscript"},
+}
+
+func goDataSourceCloudInit() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceCloudInitRead,
+		Schema: map[string]*schema.Schema{
+			"part": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "An ordered list of the parts of the multipart document",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"content": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The content of the part",
+						},
+						"content_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The mime type of the part, by default detected from the leading #! or #cloud-config style header",
+						},
+						"filename": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The filename given to the part in its content disposition",
+						},
+						"merge_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The cloud-init merge type of the part, i.e. list(append)+dict(recurse_array)",
+						},
+						"gzip": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Description: "Compress the part, cloud-init then detects the content type from the decompressed content",
+						},
+					},
+				},
+			},
+			"boundary": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     defaultBoundary,
+				Description: "The mime boundary placed between the parts",
+			},
+			"gzip": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Compress the whole document, requires base64_encode",
+			},
+			"base64_encode": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Base64 encode the document",
+			},
+			"rendered": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The rendered multipart document",
+			},
+		},
+	}
+}
+
+// cloudInitPart is a single part of the multipart document
+type cloudInitPart struct {
+	Content     string
+	ContentType string
+	Filename    string
+	MergeType   string
+	Gzip        bool
+}
+
+// dataSourceCloudInitRead is responsible for assembling the multipart document
+func dataSourceCloudInitRead(d *schema.ResourceData, meta interface{}) error {
+	compress := d.Get("gzip").(bool)
+	encode := d.Get("base64_encode").(bool)
+	if compress && !encode {
+		return fmt.Errorf("base64_encode must be enabled when using gzip")
+	}
+
+	var parts []cloudInitPart
+	for _, x := range d.Get("part").([]interface{}) {
+		part := x.(map[string]interface{})
+		parts = append(parts, cloudInitPart{
+			Content:     part["content"].(string),
+			ContentType: detectContentType(part["content_type"].(string), part["content"].(string)),
+			Filename:    part["filename"].(string),
+			MergeType:   part["merge_type"].(string),
+			Gzip:        part["gzip"].(bool),
+		})
+	}
+
+	document, err := renderMultipart(d.Get("boundary").(string), parts)
+	if err != nil {
+		return err
+	}
+	if compress {
+		if document, err = compressOutput(document, compressionGzip); err != nil {
+			return err
+		}
+	}
+	rendered := string(document)
+	if encode {
+		rendered = base64.StdEncoding.EncodeToString(document)
+	}
+
+	d.Set("rendered", rendered)
+	d.SetId(hash(rendered))
+
+	return nil
+}
+
+// renderMultipart is responsible for assembling the parts into a mime multipart document
+func renderMultipart(boundary string, parts []cloudInitPart) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	w := multipart.NewWriter(buffer)
+	if err := w.SetBoundary(boundary); err != nil {
+		return nil, fmt.Errorf("invalid boundary: %q, error: %s", boundary, err)
+	}
+	fmt.Fprintf(buffer, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n", boundary)
+	fmt.Fprintf(buffer, "MIME-Version: 1.0\r\n\r\n")
+
+	for i, x := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", x.ContentType)
+		header.Set("Content-Transfer-Encoding", "7bit")
+		header.Set("Mime-Version", "1.0")
+		if x.Filename != "" {
+			header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", x.Filename))
+		}
+		if x.MergeType != "" {
+			header.Set("X-Merge-Type", x.MergeType)
+		}
+		content := []byte(x.Content)
+		if x.Gzip {
+			compressed, err := compressOutput(content, compressionGzip)
+			if err != nil {
+				return nil, err
+			}
+			header.Set("Content-Type", "application/x-gzip")
+			header.Set("Content-Transfer-Encoding", "base64")
+			content = []byte(base64.StdEncoding.EncodeToString(compressed))
+		}
+
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create part %d, error: %s", i, err)
+		}
+		if _, err := pw.Write(content); err != nil {
+			return nil, fmt.Errorf("unable to write part %d, error: %s", i, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// detectContentType returns the content type, when not given detecting it from the start of the
+// content by the rules of cloud-init, otherwise defaulting to text/plain
+func detectContentType(contentType, content string) string {
+	if contentType != "" {
+		return contentType
+	}
+	for _, x := range cloudInitContentTypes {
+		if strings.HasPrefix(content, x.Prefix) {
+			return x.ContentType
+		}
+	}
+
+	return "text/plain"
+}
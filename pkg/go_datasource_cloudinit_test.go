@@ -0,0 +1,176 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoDataSourceCloudInit(t *testing.T) {
+	resource := goDataSourceCloudInit()
+	if resource == nil {
+		t.Error("we should have recieved the provider schema")
+	}
+}
+
+// testPart is a part read back from a multipart document
+type testPart struct {
+	Header   textproto.MIMEHeader
+	Filename string
+	Content  []byte
+}
+
+// testReadMultipart parses the multipart document, returning the parts
+func testReadMultipart(t *testing.T, document []byte) []testPart {
+	msg, err := mail.ReadMessage(bytes.NewReader(document))
+	if err != nil {
+		t.Fatalf("unable to read the document: %s", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("unexpected content type: %s, error: %v", mediaType, err)
+	}
+	var parts []testPart
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unable to read part: %s", err)
+		}
+		content, err := ioutil.ReadAll(part)
+		if err != nil {
+			t.Fatalf("unable to read part: %s", err)
+		}
+		parts = append(parts, testPart{Header: part.Header, Filename: part.FileName(), Content: content})
+	}
+
+	return parts
+}
+
+func TestRenderMultipart(t *testing.T) {
+	document, err := renderMultipart("CUSTOM", []cloudInitPart{
+		{Content: "#cloud-config\n", ContentType: "text/cloud-config", MergeType: "list(append)+dict(recurse_array)"},
+		{Content: "#!/bin/bash\necho hello\n", ContentType: "text/x-shellscript", Filename: "hello.sh"},
+		{Content: "#!/bin/bash\n", ContentType: "text/x-shellscript", Gzip: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(string(document), "--CUSTOM\r\n") {
+		t.Errorf("expected the custom boundary in the document")
+	}
+
+	parts := testReadMultipart(t, document)
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got: %d", len(parts))
+	}
+	if parts[0].Header.Get("X-Merge-Type") != "list(append)+dict(recurse_array)" {
+		t.Errorf("unexpected merge type: %s", parts[0].Header.Get("X-Merge-Type"))
+	}
+	if parts[1].Filename != "hello.sh" || parts[1].Header.Get("Content-Type") != "text/x-shellscript" {
+		t.Errorf("unexpected part headers: %v", parts[1].Header)
+	}
+	if parts[2].Header.Get("Content-Type") != "application/x-gzip" {
+		t.Errorf("unexpected content type: %s", parts[2].Header.Get("Content-Type"))
+	}
+	zr, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(parts[2].Content)))
+	if err != nil {
+		t.Fatalf("unable to decompress the part: %s", err)
+	}
+	if content, _ := ioutil.ReadAll(zr); string(content) != "#!/bin/bash\n" {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestRenderMultipartBadBoundary(t *testing.T) {
+	if _, err := renderMultipart("bad boundary!", []cloudInitPart{{Content: "a"}}); err == nil {
+		t.Error("expected an error on an invalid boundary")
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	cases := []struct {
+		ContentType string
+		Content     string
+		Expected    string
+	}{
+		{Content: "#!/bin/bash\necho", Expected: "text/x-shellscript"},
+		{Content: "#cloud-config\npackages: []", Expected: "text/cloud-config"},
+		{Content: "#cloud-config-archive\n- type: text/cloud-config", Expected: "text/cloud-config-archive"},
+		{Content: "#include\nhttp://example.com/a", Expected: "text/x-include-url"},
+		{Content: "#include-once\nhttp://example.com/a", Expected: "text/x-include-once-url"},
+		{Content: "#cloud-boothook\necho", Expected: "text/cloud-boothook"},
+		{Content: "## template: jinja\n#cloud-config", Expected: "text/jinja2"},
+		{Content: "#part-handler\n", Expected: "text/part-handler"},
+		{Content: " #!/bin/bash", Expected: "text/plain"},
+		{Content: "hello", Expected: "text/plain"},
+		{ContentType: "text/x-custom", Content: "#!/bin/bash", Expected: "text/x-custom"},
+	}
+	for i, x := range cases {
+		if detected := detectContentType(x.ContentType, x.Content); detected != x.Expected {
+			t.Errorf("case %d, got: %s, want: %s", i, detected, x.Expected)
+		}
+	}
+}
+
+func TestGoTemplateCloudInit(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_cloudinit_config" "test" {
+						gzip          = false
+						base64_encode = false
+						part {
+							content_type = "text/x-shellscript"
+							content      = "#!/bin/bash"
+						}
+					}`,
+				Check: resource.TestMatchResourceAttr("data.gotemplate_cloudinit_config.test", "rendered",
+					regexp.MustCompile("(?s)^Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\".*text/x-shellscript.*#!/bin/bash")),
+			},
+			{
+				Config: `
+					data "gotemplate_cloudinit_config" "test" {
+						gzip          = false
+						base64_encode = false
+						part {
+							content = "#cloud-config\npackages: [nginx]"
+						}
+					}`,
+				Check: resource.TestMatchResourceAttr("data.gotemplate_cloudinit_config.test", "rendered",
+					regexp.MustCompile("(?s)Content-Type: text/cloud-config\r\n.*#cloud-config")),
+			},
+		},
+	})
+}
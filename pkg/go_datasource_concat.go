@@ -0,0 +1,166 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+func goDataSourceConcat() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConcatRead,
+		Schema: map[string]*schema.Schema{
+			"base_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path relative template and snippet paths are resolved against, i.e. path.module",
+			},
+			"fragment": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "An ordered list of the fragments of the document, each either content, which may be empty, or a template",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"content": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Content included as is, i.e. the output of another data source",
+						},
+						"template": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The contents of, or path to, a template rendered as the fragment",
+						},
+						"vars": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Description: "Variables of the template, merged over the shared vars",
+						},
+					},
+				},
+			},
+			"separator": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "\n",
+				Description: "The separator placed between the fragments",
+			},
+			"skip_empty": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Leave out fragments which are empty or hold nothing but whitespace, i.e. disabled features",
+			},
+			"snippets": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path to a directory containing snippets available to the templates",
+			},
+			"vars": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Default:     make(map[string]interface{}),
+				Description: "A map of variables shared by the templates",
+			},
+			"rendered": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The concatenated document",
+			},
+		},
+	}
+}
+
+// dataSourceConcatRead is responsible for rendering the fragments and joining them together
+func dataSourceConcatRead(d *schema.ResourceData, meta interface{}) error {
+	semaphore := semaphoreOf(meta)
+	semaphore.acquire()
+	defer semaphore.release()
+	reservation := budgetOf(meta).reservation()
+	defer reservation.release()
+
+	basePath := d.Get("base_path").(string)
+	options := render.Options{
+		Library:      libraryOf(meta),
+		SnippetCache: snippetCacheOf(meta),
+		Snippets:     resolvePath(basePath, d.Get("snippets").(string)),
+	}
+	options.Strict = defaultsOf(meta).strict
+	defaultsOf(meta).apply(&options)
+	renderer := render.New(options)
+	shared := values.Merge(defaultsOf(meta).vars, d.Get("vars").(map[string]interface{}))
+
+	var fragments []string
+	for i, x := range d.Get("fragment").([]interface{}) {
+		// step: a fragment holding only an empty content is read back as nil
+		fragment, _ := x.(map[string]interface{})
+		content, _ := fragment["content"].(string)
+		template, _ := fragment["template"].(string)
+		// step: an empty content is permitted, being the output of a disabled feature
+		if content != "" && template != "" {
+			return fmt.Errorf("fragment %d must set only one of content or template", i)
+		}
+		if template != "" {
+			source, err := readPathOrContents(basePath, template)
+			if err != nil {
+				return fmt.Errorf("unable to read fragment %d, error: %s", i, err)
+			}
+			vars := make(map[string]interface{}, len(shared))
+			for k, v := range shared {
+				vars[k] = v
+			}
+			if fragmentVars, ok := fragment["vars"].(map[string]interface{}); ok {
+				for k, v := range fragmentVars {
+					vars[k] = v
+				}
+			}
+			if content, err = renderer.Render(source, vars); err != nil {
+				return fmt.Errorf("unable to render fragment %d, error: %s", i, err)
+			}
+		}
+		if d.Get("skip_empty").(bool) && strings.TrimSpace(content) == "" {
+			continue
+		}
+		if err := reservation.reserve(int64(len(content))); err != nil {
+			return err
+		}
+		fragments = append(fragments, content)
+	}
+	// step: the joined output is held alongside the fragments until the read returns
+	separator := d.Get("separator").(string)
+	var size int
+	for i, x := range fragments {
+		if i > 0 {
+			size += len(separator)
+		}
+		size += len(x)
+	}
+	if err := reservation.reserve(int64(size)); err != nil {
+		return err
+	}
+	rendered := strings.Join(fragments, separator)
+
+	d.Set("rendered", rendered)
+	d.SetId(hash(rendered))
+
+	return nil
+}
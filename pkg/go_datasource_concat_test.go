@@ -0,0 +1,108 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoTemplateConcat(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"port.tmpl": "Port {{ .port }}",
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_concat" "test" {
+						base_path  = "%s"
+						skip_empty = true
+						vars {
+							port = "22"
+							root = "no"
+						}
+						fragment {
+							content = "# managed by terraform"
+						}
+						fragment {
+							template = "port.tmpl"
+						}
+						fragment {
+							template = "{{ if eq .enabled \"true\" }}X11Forwarding yes{{ end }}"
+							vars {
+								enabled = "false"
+							}
+						}
+						fragment {
+							template = "PermitRootLogin {{ .root }}"
+						}
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_concat.test", "rendered", "# managed by terraform\nPort 22\nPermitRootLogin no"),
+			},
+			{
+				Config: `
+					data "gotemplate_concat" "test" {
+						separator = ", "
+						fragment {
+							content = "a"
+						}
+						fragment {
+							template = "{{ .name }}"
+							vars {
+								name = "b"
+							}
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_concat.test", "rendered", "a, b"),
+			},
+			{
+				Config: `
+					data "gotemplate_concat" "test" {
+						skip_empty = true
+						fragment {
+							content = "a"
+						}
+						fragment {
+							content = ""
+						}
+						fragment {
+							content = "b"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_concat.test", "rendered", "a\nb"),
+			},
+			{
+				Config: `
+					data "gotemplate_concat" "test" {
+						fragment {
+							content  = "a"
+							template = "b"
+						}
+					}`,
+				ExpectError: regexp.MustCompile("must set only one of content or template"),
+			},
+		},
+	})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/diff"
+)
+
+func goDataSourceDiff() *schema.Resource {
+	s := dataSourceTemplateSchema()
+	s["target"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		Description: "The path to the file the rendered template is compared against",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The rendered template",
+	}
+	s["changed"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Computed:    true,
+		Description: "Indicates the rendered template differs from the target file",
+	}
+	s["diff"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "A unified diff of the target file against the rendered template",
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceDiffRead,
+		Schema: s,
+	}
+}
+
+// dataSourceDiffRead is responsible for rendering the template and comparing it to the target file
+func dataSourceDiffRead(d *schema.ResourceData, meta interface{}) error {
+	semaphore := semaphoreOf(meta)
+	semaphore.acquire()
+	defer semaphore.release()
+
+	target := resolvePath(d.Get("base_path").(string), d.Get("target").(string))
+
+	buffer := new(bytes.Buffer)
+	scoped, err := renderGoTemplateTo(d, meta, buffer)
+	if err != nil {
+		return err
+	}
+	rendered := buffer.String()
+	// step: a missing target is treated as empty, i.e. everything has changed
+	current, err := ioutil.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	changes := diff.Unified(target, target, string(current), rendered)
+
+	d.Set("rendered", rendered)
+	d.Set("changed", changes != "")
+	// step: the diff is shown in the plan, so the sensitive values of the render are redacted
+	d.Set("diff", scoped.redact(changes))
+	d.SetId(hash(rendered + changes))
+
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestGoDataSourceDiff(t *testing.T) {
+	resource := goDataSourceDiff()
+	if resource == nil {
+		t.Error("we should have recieved the provider schema")
+	}
+}
+
+func TestGoTemplateDiff(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{"current.conf": "Hello rohith"})
+	defer os.RemoveAll(dir)
+	target := filepath.ToSlash(filepath.Join(dir, "current.conf"))
+	missing := filepath.ToSlash(filepath.Join(dir, "missing.conf"))
+
+	cases := []struct {
+		Target  string
+		Name    string
+		Changed string
+		Diff    string
+	}{
+		{Target: target, Name: "rohith", Changed: "false", Diff: ""},
+		{
+			Target:  target,
+			Name:    "world",
+			Changed: "true",
+			Diff: fmt.Sprintf("--- %s\n+++ %s\n@@ -1,1 +1,1 @@\n-Hello rohith\n\\ No newline at end of file\n"+
+				"+Hello world\n\\ No newline at end of file\n", target, target),
+		},
+		{Target: missing, Name: "rohith", Changed: "true"},
+	}
+	for _, x := range cases {
+		check := []resource.TestCheckFunc{
+			resource.TestCheckResourceAttr("data.gotemplate_diff.test", "changed", x.Changed),
+		}
+		if x.Target != missing {
+			check = append(check, resource.TestCheckResourceAttr("data.gotemplate_diff.test", "diff", x.Diff))
+		}
+		resource.UnitTest(t, resource.TestCase{
+			Providers: testProviders,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(`
+						data "gotemplate_diff" "test" {
+							template = "Hello {{ .name }}"
+							target   = "%s"
+							vars     = { name = "%s" }
+						}`, x.Target, x.Name),
+					Check: resource.ComposeTestCheckFunc(check...),
+				},
+			},
+		})
+	}
+}
+
+func TestGoTemplateDiffRedacted(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{"current.conf": "password: changeme"})
+	defer os.RemoveAll(dir)
+	target := filepath.ToSlash(filepath.Join(dir, "current.conf"))
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_diff" "test" {
+						template       = "password: {{ .password }}"
+						target         = "%s"
+						vars           = { password = "hunter2secret" }
+						sensitive_vars = ["password"]
+					}`, target),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_diff.test", "changed", "true"),
+					resource.TestMatchResourceAttr("data.gotemplate_diff.test", "diff", regexp.MustCompile(`\+password: <redacted>`)),
+					func(state *terraform.State) error {
+						if diff := state.RootModule().Resources["data.gotemplate_diff.test"].Primary.Attributes["diff"]; strings.Contains(diff, "hunter2secret") {
+							return fmt.Errorf("expected the sensitive value to be redacted from the diff: %s", diff)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+func goDataSourceDir() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceDirRead,
+		Schema: map[string]*schema.Schema{
+			"base_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path relative source and snippet paths are resolved against, i.e. path.module",
+			},
+			"source_dir": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The path to a directory of templates you wish rendered",
+			},
+			"snippets": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path to a directory containing snippets",
+			},
+			"vars": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Default:     make(map[string]interface{}),
+				Description: "A map of variables used within the templates",
+			},
+			"follow_symlinks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Follow symbolic links when walking the directories, by default they are skipped",
+			},
+			"parallelism": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "The number of templates rendered concurrently, defaults to the number of cpus",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"archive": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Emit the rendered tree as a base64 encoded archive, either tar.gz or zip",
+				ValidateFunc: validation.StringInSlice([]string{archiveTarGz, archiveZip}, false),
+			},
+			"rendered": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "A map of the relative path to the rendered template",
+			},
+			"checksum_algorithm": checksumAlgorithmSchema(),
+			"checksums": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "A map of the relative path to the checksum of the rendered template in the checksum_algorithm",
+			},
+			"archive_base64": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The base64 encoded archive of the rendered tree",
+			},
+		},
+	}
+}
+
+// dataSourceDirRead is responsible for rendering all the templates under the directory
+func dataSourceDirRead(d *schema.ResourceData, meta interface{}) error {
+	semaphore := semaphoreOf(meta)
+	semaphore.acquire()
+	defer semaphore.release()
+
+	basePath := d.Get("base_path").(string)
+	sourceDir := resolvePath(basePath, d.Get("source_dir").(string))
+	snippetsPath := resolvePath(basePath, d.Get("snippets").(string))
+	vars := values.Merge(defaultsOf(meta).vars, d.Get("vars").(map[string]interface{}))
+
+	followSymlinks := d.Get("follow_symlinks").(bool)
+
+	files, err := listTemplateFiles(sourceDir, followSymlinks)
+	if err != nil {
+		return err
+	}
+
+	options := render.Options{Library: libraryOf(meta), SnippetCache: snippetCacheOf(meta), Snippets: snippetsPath, FollowSymlinks: followSymlinks}
+	options.Strict = defaultsOf(meta).strict
+	defaultsOf(meta).apply(&options)
+	reservation := budgetOf(meta).reservation()
+	defer reservation.release()
+	started := time.Now()
+	outputs, err := renderTemplateFiles(sourceDir, files, options, vars, d.Get("parallelism").(int), renderPoolOf(meta), reservation)
+	if err != nil {
+		logEvent(logError, "template directory render failed", "source_dir", sourceDir, "error", err)
+		return err
+	}
+	logEvent(logDebug, "template directory rendered", "source_dir", sourceDir, "files", len(files), "duration", time.Since(started))
+
+	rendered := make(map[string]string, len(files))
+	checksums := make(map[string]string, len(files))
+	algorithm := d.Get("checksum_algorithm").(string)
+	for i, name := range files {
+		rendered[name] = outputs[i]
+		checksums[name] = checksum(algorithm, outputs[i])
+	}
+
+	d.Set("rendered", rendered)
+	d.Set("checksums", checksums)
+	d.SetId(hashFiles(algorithm, checksums))
+
+	archive := ""
+	if format := d.Get("archive").(string); format != "" {
+		encoded, err := archiveFiles(format, rendered)
+		if err != nil {
+			return fmt.Errorf("unable to archive the rendered files, error: %s", err)
+		}
+		archive = base64.StdEncoding.EncodeToString(encoded)
+	}
+	d.Set("archive_base64", archive)
+
+	return nil
+}
+
+// renderTemplateFiles is responsible for rendering the files using a pool of workers; the
+// outputs are returned in the same order as the files and the error reported is always that
+// of the first failing file, regardless of the order in which the workers completed. Each file
+// holds a slot of the provider render pool while rendering, which may be nil, and the workers
+// default to the size of the pool or the number of cpus. The outputs are held against the
+// reservation, which may be nil
+func renderTemplateFiles(dir string, files []string, options render.Options, vars map[string]interface{}, workers int, pool renderSemaphore, budget *reservation) ([]string, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+		if pool != nil && cap(pool) < workers {
+			workers = cap(pool)
+		}
+	}
+	renderer := render.New(options)
+	outputs := make([]string, len(files))
+	errs := make([]error, len(files))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				outputs[index], errs[index] = renderTemplateFile(renderer, dir, files[index], vars, pool, budget)
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to render template: %s, error: %s", files[i], err)
+		}
+	}
+
+	return outputs, nil
+}
+
+// renderTemplateFile is responsible for reading and rendering a single file under the directory;
+// the budget is reserved once the pool slot is released, so a render waiting on the budget never
+// holds a slot another render needs to complete
+func renderTemplateFile(renderer *render.Renderer, dir, name string, vars map[string]interface{}, pool renderSemaphore, budget *reservation) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return "", err
+	}
+	pool.acquire()
+	started := time.Now()
+	rendered, err := renderer.Render(string(content), vars)
+	pool.release()
+	if err != nil {
+		return "", err
+	}
+	if err := budget.reserve(int64(len(rendered))); err != nil {
+		return "", err
+	}
+	logEvent(logTrace, "template file rendered", "file", name, "bytes", len(rendered), "duration", time.Since(started))
+
+	return rendered, nil
+}
+
+// listTemplateFiles returns a sorted list of the relative paths of all files under the directory
+func listTemplateFiles(dir string, followSymlinks bool) ([]string, error) {
+	var files []string
+	// unlike snippets, hidden files form part of the rendered tree
+	walk := render.WalkOptions{FollowSymlinks: followSymlinks, IncludeHidden: true}
+
+	err := render.WalkFiles(dir, walk, func(_, relative string) error {
+		files = append(files, relative)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// hashFiles is responsible for calculating a checksum across a map of files to their checksums
+func hashFiles(algorithm string, checksums map[string]string) string {
+	var names []string
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var content string
+	for _, name := range names {
+		content += fmt.Sprintf("%s:%s:", name, checksums[name])
+	}
+
+	return checksum(algorithm, content)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+func TestGoDataSourceDir(t *testing.T) {
+	resource := goDataSourceDir()
+	if resource == nil {
+		t.Error("we should have recieved the provider schema")
+	}
+}
+
+func TestListTemplateFiles(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"z.conf":         "z",
+		"a/b/nested.cfg": "nested",
+		"m.conf":         "m",
+	})
+	defer os.RemoveAll(dir)
+
+	files, err := listTemplateFiles(dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{"a/b/nested.cfg", "m.conf", "z.conf"}
+	if fmt.Sprintf("%v", files) != fmt.Sprintf("%v", expected) {
+		t.Errorf("got: %v, want: %v", files, expected)
+	}
+}
+
+func TestRenderTemplateFiles(t *testing.T) {
+	files := make(map[string]string)
+	var names []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("file%02d.conf", i)
+		files[name] = fmt.Sprintf("{{ .name }}-%d", i)
+		names = append(names, name)
+	}
+	dir := testTemplateDir(t, files)
+	defer os.RemoveAll(dir)
+
+	for _, workers := range []int{0, 1, 8} {
+		outputs, err := renderTemplateFiles(dir, names, render.Options{}, map[string]interface{}{"name": "test"}, workers, nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		for i, x := range outputs {
+			if expected := fmt.Sprintf("test-%d", i); x != expected {
+				t.Errorf("workers: %d, got: %s, want: %s", workers, x, expected)
+			}
+		}
+	}
+}
+
+func TestRenderTemplateFilesPool(t *testing.T) {
+	files := make(map[string]string)
+	var names []string
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("file%02d.conf", i)
+		files[name] = "{{ track }}"
+		names = append(names, name)
+	}
+	dir := testTemplateDir(t, files)
+	defer os.RemoveAll(dir)
+
+	var running, peak int32
+	options := render.Options{Funcs: template.FuncMap{"track": func() string {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			previous := atomic.LoadInt32(&peak)
+			if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return ""
+	}}}
+
+	// step: two data sources of many workers share the pool of two slots
+	pool := newRenderSemaphore(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := renderTemplateFiles(dir, names, options, nil, 6, pool, nil); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent renders, got: %d", peak)
+	}
+}
+
+func TestRenderTemplateFilesError(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"a.conf": "{{ .name",
+		"b.conf": "{{ end }}",
+		"c.conf": "ok",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := renderTemplateFiles(dir, []string{"a.conf", "b.conf", "c.conf"}, render.Options{}, nil, 3, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "a.conf") {
+		t.Errorf("expected the error of the first file, got: %s", err)
+	}
+}
+
+func TestGoTemplateDir(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"hello.conf":     "Hello {{ .name }}",
+		"sub/upper.conf": "{{ upper .name }}",
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_dir" "test" {
+						source_dir = "%s"
+						archive    = "zip"
+						vars       = { name = "rohith" }
+					}`, filepath.ToSlash(dir)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_dir.test", "rendered.%", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_dir.test", "rendered.hello.conf", "Hello rohith"),
+					resource.TestCheckResourceAttr("data.gotemplate_dir.test", "rendered.sub/upper.conf", "ROHITH"),
+					resource.TestCheckResourceAttr("data.gotemplate_dir.test", "checksums.%", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_dir.test", "checksums.hello.conf", hash("Hello rohith")),
+					func(s *terraform.State) error {
+						rs := s.RootModule().Resources["data.gotemplate_dir.test"]
+						if rs.Primary.Attributes["archive_base64"] == "" {
+							return fmt.Errorf("expected the archive to be populated")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// testTemplateDir creates a temporary directory containing the files
+func testTemplateDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "gotemplate")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unable to create directory: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("unable to write file: %s", err)
+		}
+	}
+
+	return dir
+}
@@ -18,148 +18,765 @@ package pkg
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"strings"
-	"text/template"
+	"time"
 
-	"github.com/hashicorp/terraform/helper/pathorcontents"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+	"github.com/gambol99/terraform-gotemplate/pkg/validate"
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+const (
+	// defaultMaxSnippetSize is the default size limit of a snippet file
+	defaultMaxSnippetSize = 1 << 20
+	// oversizeError fails the render on an oversized snippet
+	oversizeError = "error"
+	// oversizeSkip skips over an oversized snippet
+	oversizeSkip = "skip"
 )
 
 func goDataSourceFile() *schema.Resource {
+	s := dataSourceTemplateSchema()
+	s["enabled"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "When false nothing is read or rendered and the outputs are empty",
+	}
+	s["error_on_empty"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Fail when the rendered template is empty or holds nothing but whitespace",
+	}
+	s["validate"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Validate the rendered template as a document of the given type, i.e. ignition, kubernetes or systemd-unit",
+		ValidateFunc: validation.StringInSlice(validate.Names(), false),
+	}
+	s["validate_command"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "A command and its arguments run without a shell with the rendered template on stdin, i.e. promtool check config /dev/stdin, failing the read when it exits non-zero; the executable must be in the provider allowed_commands",
+	}
+	s["command_timeout"] = commandTimeoutSchema()
+	s["kubernetes_schemas"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The path to an offline bundle of kubernetes json schemas used by the kubernetes validation",
+	}
+	s["kubernetes_version"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The kubernetes version whose schemas are used by the kubernetes validation, defaults to master",
+	}
+	s["post_process"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringInSlice(postProcessorNames(), false)},
+		Description: "An ordered list of post processors, i.e. trim, ensure_newline, strip_newline, minify_json, pretty_json, sort_keys, sort_keys_yaml, gzip, zstd or brotli; the compression steps must come last and only apply to rendered_base64",
+	}
+	s["trailing_newline"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      trailingNewlineKeep,
+		Description:  "How the output ends, either keep as rendered, ensure a single line break or strip the line breaks; applied after the post processors",
+		ValidateFunc: validation.StringInSlice([]string{trailingNewlineKeep, trailingNewlineEnsure, trailingNewlineStrip}, false),
+	}
+	s["skip_unchanged"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Keep the previously rendered state of the gotemplate_file resource while input_sha256 is unchanged, rather than rendering on every read",
+	}
+	s["input_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The hash of the arguments, template, vars files and snippets rendered, set when skip_unchanged is enabled or the id_scheme is input-hash",
+	}
+	s["id_scheme"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      idSchemeOutputHash,
+		ValidateFunc: validation.StringInSlice(idSchemes, false),
+		Description:  "How the id is derived, either output-hash, the checksum of the rendered template, input-hash, the input_sha256, uuid, a random uuid kept by the resource until its arguments change, or static, a constant",
+	}
+	s["output_encoding"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      encodingUTF8,
+		Description:  "The character encoding applied to rendered_base64, either utf-8, utf-16le or latin-1",
+		ValidateFunc: validation.StringInSlice(outputEncodings, false),
+	}
+	s["byte_order_mark"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Prefix rendered_base64 with the byte order mark of the output encoding",
+	}
+	s["compression"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      compressionNone,
+		Description:  "The compression applied to rendered_base64, either none, gzip, zstd or brotli",
+		ValidateFunc: validation.StringInSlice(compressionCodecs, false),
+	}
+	s["size_limit_profile"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Description:  "Check the encoded output against a platform limit, i.e. aws_user_data, azure_custom_data or gcp_metadata",
+		ValidateFunc: validation.StringInSlice(sizeLimitProfileNames(), false),
+	}
+	s["size_limit_action"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      sizeLimitError,
+		Description:  "The action taken when the size limit is exceeded, either error or warn",
+		ValidateFunc: validation.StringInSlice([]string{sizeLimitError, sizeLimitWarn}, false),
+	}
+	s["split_on"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Split the rendered template on the separator, i.e. a marker line, into rendered_parts",
+	}
+	s["rendered_parts"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "The parts of the rendered template when split_on is set",
+	}
+	s["split_yaml"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Split the rendered template into its yaml documents, exposed in yaml_documents",
+	}
+	s["decode_yaml"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Decode each of the yaml documents, exposing them json encoded in yaml_documents_json",
+	}
+	s["yaml_documents"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "The non empty yaml documents of the rendered template when split_yaml is enabled",
+	}
+	s["yaml_documents_json"] = &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "The json encoding of each yaml document when decode_yaml is enabled",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The rendered template; the read fails when the vars hold values not known until apply, whereas a resource defers the render to the apply",
+	}
+	s["rendered_base64"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The base64 encoded rendered template in the output encoding and compression",
+	}
+	s["checksum_algorithm"] = checksumAlgorithmSchema()
+	s["checksum"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The checksum of the rendered template in the checksum_algorithm, also the id under the output-hash id_scheme",
+	}
+
 	return &schema.Resource{
-		Read: dataSourceFileRead,
-		Schema: map[string]*schema.Schema{
-			"template": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Contents of the template you wish rendered",
-			},
-			"snippets": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "The path to a directory containing snippets",
-			},
-			"vars": {
-				Type:        schema.TypeMap,
-				Optional:    true,
-				Default:     make(map[string]interface{}),
-				Description: "A map of variables used within the template",
-			},
-			"rendered": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "The rendered template",
+		Read:   dataSourceFileRead,
+		Schema: s,
+	}
+}
+
+// templateSchema returns the attributes used by renderGoTemplate to read and render a template
+func templateSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"base_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The path relative template and snippet paths are resolved against, i.e. path.module",
+		},
+		"template": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "Contents of the template you wish rendered",
+			ConflictsWith: []string{"content_base64"},
+		},
+		"content_base64": {
+			Type:          schema.TypeString,
+			Optional:      true,
+			Description:   "The base64 encoded contents of the template, for content which cannot be held in a string intact",
+			ConflictsWith: []string{"template"},
+		},
+		"snippets": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The path to a directory containing snippets, defaulting to the provider setting",
+		},
+		"strict": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Fail the render on references to missing variables, defaulting to the provider setting",
+		},
+		"left_delimiter": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The left action delimiter, defaulting to the provider setting or {{",
+		},
+		"right_delimiter": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The right action delimiter, defaulting to the provider setting or }}",
+		},
+		"disable_functions": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The names of template functions to remove, in addition to those of the provider setting",
+		},
+		"snippet_paths": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "An ordered list of snippet directories loaded after snippets, later directories override defines from earlier ones",
+		},
+		"vars": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Default:     make(map[string]interface{}),
+			Description: "A map of variables used within the template",
+		},
+		"coerce_types": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Convert the variables holding true or false to bools and those holding numbers to numbers before rendering",
+		},
+		"var_types": {
+			Type:         schema.TypeMap,
+			Optional:     true,
+			Elem:         &schema.Schema{Type: schema.TypeString},
+			ValidateFunc: validateVarTypes,
+			Description:  "A map of variable name to its declared type, i.e. number, bool or map(string), the variables being converted and checked before rendering",
+		},
+		"sensitive_vars": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The names of variables whose string values, of at least four characters, are redacted from the error messages, traces and logs",
+		},
+		"secret_vars": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "A map of secret variables merged over every other layer, hidden in the plan and redacted as the sensitive_vars are",
+		},
+		"resolve_vars": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Execute the string variables as templates against the other variables, so one may compose another",
+		},
+		"computed_vars": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Description: "A map of variable name to a template expression, i.e. printf \"%s.%s\" .name .domain, evaluated once before the render",
+		},
+		"values_file": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The path to a yaml or json file of values, the lowest precedence layer of the variables",
+		},
+		"override_files": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "An ordered list of yaml or json files deep merged over the values_file, later files winning",
+		},
+		"vars_files": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "An ordered list of yaml, json, .env or terraform .tfvars and .tfvars.json files of variables, merged over the override_files and under vars",
+		},
+		"set": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Values set by dotted key, i.e. a.b.c, taking precedence over the files and vars",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"key": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "The dotted path of the value, a literal dot may be escaped with a backslash",
+					},
+					"value": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "The value to set",
+					},
+				},
 			},
 		},
+		"frontmatter": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Read the leading yaml block delimited by --- declaring the engine, delimiters, required vars and defaults",
+		},
+		"render_timestamp": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Freeze the .gotemplate.timestamp to the given RFC3339 time, keeping the render reproducible",
+		},
+		"follow_symlinks": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Follow symbolic links when loading the snippets, by default they are skipped",
+		},
+		"include_hidden": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Load snippets whose name begins with a dot, by default they are skipped",
+		},
+		"max_snippet_size": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      defaultMaxSnippetSize,
+			Description:  "The maximum size in bytes of a snippet file, zero disables the check",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"oversize_action": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      oversizeError,
+			Description:  "The action taken on a snippet exceeding the maximum size, either error or skip",
+			ValidateFunc: validation.StringInSlice([]string{oversizeError, oversizeSkip}, false),
+		},
+		"allow_overrides": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Permit snippets to redefine templates defined in other files",
+		},
+		"legacy_snippet_names": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Name snippet files by their basename rather than their path relative to the snippets directory",
+		},
+		"trim_blocks": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Remove the first newline after a block action such as if, range or end",
+		},
+		"lstrip_blocks": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Strip the spaces and tabs from the start of a line up to a block action",
+		},
+		"strip_comments": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Remove the template comments, along with the lines they occupy alone",
+		},
+		"comment_prefix": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Remove the lines of output whose first non-blank characters are the prefix, i.e. ##",
+		},
+		"render_passes": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      1,
+			ValidateFunc: validation.IntBetween(1, 10),
+			Description:  "The most times the output is rendered again, for templates generating template syntax, stopping once unchanged",
+		},
+		"debug": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Record the execution of the templates and defines into the trace",
+		},
+		"warnings": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The conditions found which did not fail the render, i.e. skipped snippets or deprecated functions",
+		},
+		"overridden": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "A map of each template redefined by a later parsed file to the path of that file",
+		},
+		"render_defines": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Execute each define in addition to the template, exposing the outputs in defines",
+		},
+		"defines": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "A map of the define name to its output when render_defines is enabled",
+		},
+		"snippets_loaded": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The names of the templates loaded from the snippets directory",
+		},
+		"merge_strategy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      values.StrategyOverride,
+			ValidateFunc: validation.StringInSlice(values.Strategies, false),
+			Description:  "How the values other than maps of two layers of vars are merged: override, append-lists or fail-on-conflict",
+		},
+		"effective_vars_sha256": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The sha256 of the json encoded variables as seen by the template, less the .gotemplate context",
+		},
+		"vars_used": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The dotted paths of the variables referenced by the template and snippets",
+		},
+		"vars_missing": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "The variables referenced by the template and snippets which were not supplied",
+		},
+		"trace": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The execution trace of the templates and defines when debug is enabled",
+		},
+	}
+}
+
+// goResourceFile returns the gotemplate_file data source shimmed as the resource, without the ref
+// which only a data source can hold
+func goResourceFile() *schema.Resource {
+	r := goDataSourceFile()
+	delete(r.Schema, "ref")
+
+	return r
+}
+
+// dataSourceTemplateSchema returns the attributes of templateSchema along with the ref to a registered
+// template, which only a data source can hold as the apply of a resource does not read the registry
+func dataSourceTemplateSchema() map[string]*schema.Schema {
+	s := templateSchema()
+	s["ref"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Description:   "The name of a template registered by a gotemplate_template, whose vars are the defaults of these",
+		ConflictsWith: []string{"template", "content_base64"},
 	}
+
+	return s
 }
 
 // dataSourceFileRead is responsible rendering the template content
 func dataSourceFileRead(d *schema.ResourceData, meta interface{}) error {
-	rendered, err := renderGoTemplate(d)
+	// step: a disabled template is neither read nor rendered, so its paths need not exist
+	if !d.Get("enabled").(bool) {
+		d.Set("rendered", "")
+		d.Set("rendered_base64", "")
+		d.Set("checksum", checksum(d.Get("checksum_algorithm").(string), ""))
+		id, err := dataSourceID(d, "")
+		if err != nil {
+			return err
+		}
+		d.SetId(id)
+		return nil
+	}
+	// step: a resource whose inputs are unchanged keeps the state rendered previously; a data
+	// source has no previous state, so always renders
+	var inputs string
+	if d.Get("skip_unchanged").(bool) || d.Get("id_scheme").(string) == idSchemeInputHash {
+		var err error
+		if inputs, err = inputHash(d, meta, goDataSourceFile().Schema); err != nil {
+			return err
+		}
+		if d.Get("skip_unchanged").(bool) && d.Id() != "" && inputs == d.Get("input_sha256").(string) {
+			logEvent(logDebug, "render skipped, the inputs are unchanged", "input_sha256", inputs)
+			return nil
+		}
+	}
+	d.Set("input_sha256", inputs)
+	var pipeline []string
+	for _, x := range d.Get("post_process").([]interface{}) {
+		pipeline = append(pipeline, x.(string))
+	}
+	textSteps, binarySteps, err := splitPostProcess(pipeline)
 	if err != nil {
 		return err
 	}
+	if step := trailingNewlinePolicies[d.Get("trailing_newline").(string)]; step != "" {
+		textSteps = append(textSteps, step)
+	}
+	// step: wait for a render slot, then account for the memory held by the render against the
+	// provider budget
+	semaphore := semaphoreOf(meta)
+	semaphore.acquire()
+	defer semaphore.release()
+	reservation := budgetOf(meta).reservation()
+	defer reservation.release()
+
+	// step: the binary post processors are compressors, applied ahead of the compression
+	payload := new(bytes.Buffer)
+	stream, err := newOutputStream(reservation.writer(payload), outputOptions{
+		encoding:         d.Get("output_encoding").(string),
+		byteOrderMark:    d.Get("byte_order_mark").(bool),
+		codecs:           append(binarySteps, d.Get("compression").(string)),
+		sizeLimitProfile: d.Get("size_limit_profile").(string),
+		sizeLimitAction:  d.Get("size_limit_action").(string),
+	})
+	if err != nil {
+		return err
+	}
+
+	// step: without text post processing the payload is produced as the template executes,
+	// otherwise the post processors need the whole of the rendered template; the errors of the
+	// steps handling the rendered content are redacted as the render was
+	var scoped *redactor
+	buffer := new(bytes.Buffer)
+	if len(textSteps) == 0 {
+		if scoped, err = renderGoTemplateTo(d, meta, io.MultiWriter(reservation.writer(buffer), stream)); err != nil {
+			return err
+		}
+	} else {
+		if scoped, err = renderGoTemplateTo(d, meta, reservation.writer(buffer)); err != nil {
+			return err
+		}
+		processed, err := postProcess(buffer.Bytes(), textSteps)
+		if err != nil {
+			return scoped.redactError(err)
+		}
+		if err := reservation.reserve(int64(len(processed))); err != nil {
+			return err
+		}
+		buffer = bytes.NewBuffer(processed)
+		if _, err := stream.Write(processed); err != nil {
+			return err
+		}
+	}
+	if err := stream.Close(); err != nil {
+		return err
+	}
+	rendered := buffer.String()
+	logEvent(logDebug, "output encoded", "rendered_bytes", len(rendered), "payload_bytes", payload.Len(), "post_process", strings.Join(pipeline, ","))
+	addWarnings(d, sizeLimitWarning(d.Get("size_limit_profile").(string), payload.Len()))
+	if d.Get("error_on_empty").(bool) && strings.TrimSpace(rendered) == "" {
+		return fmt.Errorf("the rendered template is empty")
+	}
+	if mode := d.Get("validate").(string); mode != "" {
+		options := validate.Options{
+			KubernetesSchemas: resolvePath(d.Get("base_path").(string), d.Get("kubernetes_schemas").(string)),
+			KubernetesVersion: d.Get("kubernetes_version").(string),
+		}
+		if err := validate.Validate(mode, rendered, options); err != nil {
+			return scoped.redactError(err)
+		}
+	}
+	if err := runValidateCommand(d, meta, rendered); err != nil {
+		return scoped.redactError(err)
+	}
+	var parts []string
+	if separator := d.Get("split_on").(string); separator != "" {
+		parts = splitOutput(rendered, separator)
+	}
+	var documents, decoded []string
+	if d.Get("split_yaml").(bool) {
+		if documents, decoded, err = splitYAML(rendered, d.Get("decode_yaml").(bool)); err != nil {
+			return scoped.redactError(err)
+		}
+	}
 	d.Set("rendered", rendered)
-	d.SetId(hash(rendered))
+	d.Set("rendered_parts", parts)
+	d.Set("yaml_documents", documents)
+	d.Set("yaml_documents_json", decoded)
+	d.Set("rendered_base64", base64.StdEncoding.EncodeToString(payload.Bytes()))
+	d.Set("checksum", checksum(d.Get("checksum_algorithm").(string), rendered))
+	id, err := dataSourceID(d, inputs)
+	if err != nil {
+		return err
+	}
+	d.SetId(id)
 	return nil
 }
 
-// renderGoTemplate is responsible for generating the template
-func renderGoTemplate(d *schema.ResourceData) (string, error) {
-	templateName := d.Get("template").(string)
-	snippetsPath := d.Get("snippets").(string)
-	vars := d.Get("vars").(map[string]interface{})
+// templateData is the resource data read by a render, the computed outputs being set upon it
+type templateData interface {
+	attributeGetter
+	GetOkExists(string) (interface{}, bool)
+	Set(string, interface{}) error
+}
 
-	// step: read in the template content or file
-	content, _, err := pathorcontents.Read(templateName)
-	if err != nil {
-		return "", err
+// renderGoTemplateTo is responsible for generating the template, streaming it to the writer; the
+// redactor returned is scoped to the render, removing the values of its sensitive_vars and
+// secret_vars from any output derived from the render, and has already been applied to the error
+func renderGoTemplateTo(d templateData, meta interface{}, w io.Writer) (scoped *redactor, err error) {
+	scoped = redactions.withValues()
+	defer func() { err = scoped.redactError(err) }()
+	sensitive := toStrings(d.Get("sensitive_vars").([]interface{}))
+	for name := range d.Get("secret_vars").(map[string]interface{}) {
+		sensitive = append(sensitive, name)
+	}
+
+	basePath := d.Get("base_path").(string)
+	templateName := d.Get("template").(string)
+	snippetsPath := resolvePath(basePath, d.Get("snippets").(string))
+	var registered registeredTemplate
+	if ref := refOf(d); ref != "" {
+		if registered, err = registryOf(meta).lookup(ref); err != nil {
+			return scoped, err
+		}
 	}
-	// step: load the main template
-	tmpl, err := template.New("base").Funcs(templateFuncs()).Parse(content)
+	vars, err := templateVars(d, defaultsOf(meta).vars, registered.vars)
 	if err != nil {
-		return "", err
+		return scoped, err
 	}
-	// step: load any snippits if required
-	if snippetsPath != "" {
-		var files []string
-		// build a list of files under the directory
-		list, err := ioutil.ReadDir(snippetsPath)
-		if err != nil {
-			return "", err
+	scoped.addValues(sensitiveValues(vars, sensitive)...)
+
+	// step: read in the template content or file, unless referencing a registered template
+	content := registered.content
+	if refOf(d) == "" {
+		if content, err = readPathOrContents(basePath, templateName); err != nil {
+			return scoped, err
 		}
-		trimmed := strings.TrimRight(snippetsPath, "/")
-		for _, x := range list {
-			files = append(files, fmt.Sprintf("%s/%s", trimmed, x.Name()))
+	}
+	if encoded := d.Get("content_base64").(string); encoded != "" {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return scoped, fmt.Errorf("unable to decode content_base64, error: %s", err)
 		}
+		content = string(decoded)
+	}
+	logEvent(logDebug, "template loaded", "base_path", basePath, "bytes", len(content), "vars", len(vars))
 
-		// step: parse the snippit files and add to the template
-		if len(files) > 0 {
-			tmpl, err = tmpl.ParseFiles(files...)
-			if err != nil {
-				return "", fmt.Errorf("failed to parse snippets at: %s, error: %s", snippetsPath, err)
-			}
-		}
+	// step: refuse to render the placeholders of values unknown until apply
+	if err := checkUnknown(content, vars); err != nil {
+		return scoped, err
 	}
 
-	// step: render the template
-	rendered := new(bytes.Buffer)
-	if err := tmpl.ExecuteTemplate(rendered, "base", vars); err != nil {
-		return "", fmt.Errorf("unable to generate content, snippets: %d, error: %s", len(tmpl.Templates()), ",", err)
+	var snippetPaths []string
+	for _, x := range d.Get("snippet_paths").([]interface{}) {
+		snippetPaths = append(snippetPaths, resolvePath(basePath, x.(string)))
 	}
 
-	return rendered.String(), nil
-}
+	options := render.Options{
+		Library:            libraryOf(meta),
+		SnippetCache:       snippetCacheOf(meta),
+		Snippets:           snippetsPath,
+		SnippetPaths:       snippetPaths,
+		FollowSymlinks:     d.Get("follow_symlinks").(bool),
+		IncludeHidden:      d.Get("include_hidden").(bool),
+		MaxSnippetSize:     int64(d.Get("max_snippet_size").(int)),
+		SkipOversized:      d.Get("oversize_action").(string) == oversizeSkip,
+		AllowOverrides:     d.Get("allow_overrides").(bool),
+		TrimBlocks:         d.Get("trim_blocks").(bool),
+		LstripBlocks:       d.Get("lstrip_blocks").(bool),
+		StripComments:      d.Get("strip_comments").(bool),
+		CommentPrefix:      d.Get("comment_prefix").(string),
+		LegacySnippetNames: d.Get("legacy_snippet_names").(bool),
+	}
+	if err := renderSettings(d, meta, &options); err != nil {
+		return scoped, err
+	}
+	options.Overrides = render.NewOverrides()
+	options.Warnings = render.NewWarnings()
+	if d.Get("debug").(bool) {
+		options.Trace = render.NewTrace()
+	}
+	if d.Get("frontmatter").(bool) {
+		frontmatter, body, err := render.ParseFrontmatter(content)
+		if err != nil {
+			return scoped, err
+		}
+		if vars, err = frontmatter.Apply(&options, vars); err != nil {
+			return scoped, err
+		}
+		content = body
+	}
+	renderer := render.New(options)
+	if d.Get("resolve_vars").(bool) {
+		if vars, err = renderer.ResolveVars(vars); err != nil {
+			return scoped, err
+		}
+	}
+	if computed := d.Get("computed_vars").(map[string]interface{}); len(computed) > 0 {
+		expressions := make(map[string]string, len(computed))
+		for k, v := range computed {
+			expressions[k] = v.(string)
+		}
+		if vars, err = renderer.ComputeVars(expressions, vars); err != nil {
+			return scoped, err
+		}
+	}
+	// step: the resolved and computed values of the sensitive vars differ from those given
+	scoped.addValues(sensitiveValues(vars, sensitive)...)
+	if d.Get("coerce_types").(bool) {
+		vars = values.CoerceStrings(vars).(map[string]interface{})
+	}
+	if declared := d.Get("var_types").(map[string]interface{}); len(declared) > 0 {
+		if vars, err = values.Coerce(vars, varTypes(declared)); err != nil {
+			return scoped, err
+		}
+	}
+	// step: inject the render context once the vars are final, so it takes precedence over any
+	// variable of the same name and is not itself resolved, computed or coerced
+	if vars[contextVar], err = templateContext(basePath, d.Get("render_timestamp").(string), defaultsOf(meta).profile); err != nil {
+		return scoped, err
+	}
+	started := time.Now()
+	tmpl, err := renderer.Parse(content)
+	if err != nil {
+		logEvent(logError, "template parse failed", "duration", time.Since(started), "error", scoped.redactError(err))
+		return scoped, err
+	}
+	logEvent(logDebug, "template parsed", "duration", time.Since(started), "snippets", len(render.Snippets(tmpl)), "defines", len(render.Defines(tmpl)))
+	d.Set("snippets_loaded", render.Snippets(tmpl))
+	d.Set("overridden", options.Overrides.Entries())
+	d.Set("warnings", []string{})
+	addWarnings(d, options.Warnings.Entries()...)
+	effective, err := effectiveVarsHash(vars)
+	if err != nil {
+		return scoped, err
+	}
+	d.Set("effective_vars_sha256", effective)
+	used := render.Variables(tmpl)
+	d.Set("vars_used", used)
+	d.Set("vars_missing", render.Missing(used, vars))
 
-// templateFuncs is a list of templates methods we support
-func templateFuncs() template.FuncMap {
-	return template.FuncMap{
-		"upper": func(s string) string {
-			return strings.ToUpper(s)
-		},
-		"lower": func(s string) string {
-			return strings.ToLower(s)
-		},
-		"split": func(s, delim string) []string {
-			return strings.Split(s, delim)
-		},
-		"join": func(s []string, sep string) string {
-			return strings.Join(s, sep)
-		},
-		"empty": func(s string) bool {
-			return s == ""
-		},
-		"keys": func(m map[string]interface{}) []string {
-			var keys []string
-			for k := range m {
-				keys = append(keys, k)
-			}
-			return keys
-		},
-		"is_true": func(s string) bool {
-			if s == "1" || s == "true" || s == "True" {
-				return true
-			}
-			return false
-		},
-		"is_false": func(s string) bool {
-			if s == "0" || s == "false" || s == "False" {
-				return false
-			}
-			return false
-		},
-		"values": func(m map[string]interface{}) []interface{} {
-			var values []interface{}
-			for _, v := range m {
-				values = append(values, v)
-			}
-			return values
-		},
+	started = time.Now()
+	counter := &countingWriter{w: w}
+	err = renderer.ExecutePasses(counter, tmpl, vars, d.Get("render_passes").(int))
+	if err != nil {
+		logEvent(logError, "template execution failed", "duration", time.Since(started), "bytes", counter.n, "error", scoped.redactError(err))
+	} else {
+		logEvent(logDebug, "template executed", "duration", time.Since(started), "bytes", counter.n)
+	}
+	defines := make(map[string]string)
+	if err == nil && d.Get("render_defines").(bool) {
+		if defines, err = renderer.ExecuteDefines(tmpl, vars); err != nil {
+			return scoped, err
+		}
 	}
+	d.Set("defines", defines)
+	if options.Trace != nil {
+		trace := scoped.redact(options.Trace.String())
+		logEvent(logDebug, "template execution trace", "trace", trace)
+		d.Set("trace", trace)
+	}
+
+	return scoped, err
 }
 
 // hash is responsible for calculating the hash of a string
@@ -16,7 +16,11 @@ limitations under the License.
 package pkg
 
 import (
+	"encoding/base64"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -81,6 +85,538 @@ func TestGoTemplate(t *testing.T) {
 	}
 }
 
+func TestGoTemplateDebug(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "Hello {{ .name }}"
+						debug    = true
+						vars     = { name = "rohith" }
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "trace", "base (base): calls: 1, keys: [gotemplate, name]\n"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateSnippetsLoaded(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"greeting.tmpl": `{{ define "greeting" }}Hello {{ .name }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						template = "{{ template \"greeting\" . }}"
+						snippets = "%s"
+						vars     = { name = "rohith" }
+					}`, filepath.ToSlash(dir)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "Hello rohith"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "snippets_loaded.#", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "snippets_loaded.0", "greeting"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "snippets_loaded.1", "greeting.tmpl"),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateBasePath(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"templates/hello.tmpl":   `{{ template "greeting" . }}`,
+		"snippets/greeting.tmpl": `{{ define "greeting" }}Hello {{ .name }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path = "%s"
+						template  = "templates/hello.tmpl"
+						snippets  = "snippets"
+						vars      = { name = "rohith" }
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "Hello rohith"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateOutputEncoding(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template        = "hi"
+						output_encoding = "utf-16le"
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "hi"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered_base64", "aABpAA=="),
+				),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template        = "hi"
+						output_encoding = "utf-16le"
+						byte_order_mark = true
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered_base64", "//5oAGkA"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateSizeLimit(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template           = "{{ printf \"%016000d\" 0 }}"
+						size_limit_profile = "aws_user_data"
+					}`,
+				Check: resource.TestCheckResourceAttrSet("data.gotemplate_file.test", "rendered"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template           = "{{ printf \"%017000d\" 0 }}"
+						size_limit_profile = "aws_user_data"
+					}`,
+				ExpectError: regexp.MustCompile("exceeding the aws_user_data limit"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateValidate(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{\"ignition\": {\"version\": \"{{ .version }}\"}}"
+						validate = "ignition"
+						vars     = { version = "3.3.0" }
+					}`,
+				Check: resource.TestCheckResourceAttrSet("data.gotemplate_file.test", "rendered"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{\"ignition\": {\"version\": \"{{ .version }}\"}}"
+						validate = "ignition"
+						vars     = { version = "latest" }
+					}`,
+				ExpectError: regexp.MustCompile("not a valid ignition document"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateVarsUsed(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ .name }}{{ .region }}"
+						vars     = { name = "rohith" }
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "vars_used.#", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "vars_used.0", "name"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "vars_used.1", "region"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "vars_missing.#", "1"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "vars_missing.0", "region"),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateTrimBlocks(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template      = "{{ if .enabled }}\n  enabled: true\n  {{ end }}\ndone"
+						vars          = { enabled = "yes" }
+						trim_blocks   = true
+						lstrip_blocks = true
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "  enabled: true\ndone"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateStripComments(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template       = "#cloud-config\n{{/* the packages */}}\n## installed on boot\npackages:\n  - {{ .name }}\n"
+						vars           = { name = "nginx" }
+						strip_comments = true
+						comment_prefix = "##"
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "#cloud-config\npackages:\n  - nginx\n"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateRenderDefines(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template       = "{{ define \"a.conf\" }}a={{ .name }}{{ end }}{{ define \"b.conf\" }}b{{ end }}main"
+						vars           = { name = "rohith" }
+						render_defines = true
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "main"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "defines.%", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "defines.a.conf", "a=rohith"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "defines.b.conf", "b"),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateOverridden(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"base/layout.tmpl": `{{ define "layout" }}[{{ block "env" . }}dev{{ end }}]{{ end }}`,
+		"prod/env.tmpl":    `{{ define "env" }}prod{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path     = "%s"
+						template      = "{{ template \"layout\" . }}"
+						snippet_paths = ["base", "prod"]
+					}`, filepath.ToSlash(dir)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "[prod]"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "overridden.%", "1"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "overridden.env", filepath.Join(dir, "prod", "env.tmpl")),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateContentBase64(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						content_base64 = "%s"
+						vars           = { name = "rohith" }
+					}`, base64.StdEncoding.EncodeToString([]byte("${literal}\t{{ .name }}\x01"))),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "${literal}\trohith\x01"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						content_base64 = "not base64!"
+					}`,
+				ExpectError: regexp.MustCompile("unable to decode content_base64"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateFrontmatter(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"app.tmpl": "---\nrequired: [name]\ndefaults:\n  replicas: 1\n---\n{{ .name }} x{{ .replicas }}",
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path   = "%s"
+						template    = "app.tmpl"
+						frontmatter = true
+						vars        = { name = "web" }
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "web x1"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path   = "%s"
+						template    = "app.tmpl"
+						frontmatter = true
+					}`, filepath.ToSlash(dir)),
+				ExpectError: regexp.MustCompile("the template requires the variables: name"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateDisabled(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						enabled   = false
+						base_path = "/does/not/exist"
+						template  = "{{ .missing"
+						snippets  = "snippets"
+						validate  = "ignition"
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", ""),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered_base64", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateErrorOnEmpty(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template       = "{{ if .enabled }}config{{ end }}\n"
+						vars           = { enabled = "" }
+						error_on_empty = true
+					}`,
+				ExpectError: regexp.MustCompile("the rendered template is empty"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template       = "{{ if .enabled }}config{{ end }}"
+						vars           = { enabled = "yes" }
+						error_on_empty = true
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "config"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateResolveVars(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template     = "{{ .api_url }}"
+						resolve_vars = true
+						vars {
+							base_domain = "example.com"
+							api_url     = "https://api.{{ .base_domain }}"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "https://api.example.com"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ .api_url }}"
+						vars {
+							base_domain = "example.com"
+							api_url     = "https://api.{{ .base_domain }}"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "https://api.{{ .base_domain }}"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template     = "{{ .a }}"
+						resolve_vars = true
+						vars {
+							a = "{{ .b }}"
+							b = "{{ .a }}"
+						}
+					}`,
+				ExpectError: regexp.MustCompile("variables reference each other in a cycle: a -> b -> a"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateComputedVars(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ .fqdn }} {{ range .zones }}[{{ . }}]{{ end }}"
+						vars {
+							name   = "web"
+							domain = "example.com"
+						}
+						computed_vars {
+							fqdn  = "printf \"%s.%s\" .name .domain"
+							zones = "split \"a,b\" \",\""
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "web.example.com [a][b]"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ .a }}"
+						computed_vars {
+							a = ".b | upper"
+							b = "nonexistent"
+						}
+					}`,
+				ExpectError: regexp.MustCompile("unable to parse computed variable: b"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateVarTypes(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ printf \"%T %T %T\" .replicas .enabled .tags }}"
+						vars {
+							replicas = "2"
+							enabled  = "true"
+							tags     = "{\"env\": \"prod\"}"
+						}
+						var_types {
+							replicas = "number"
+							enabled  = "bool"
+							tags     = "map(string)"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "int bool map[string]interface {}"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ .replicas }}"
+						vars {
+							replicas = "many"
+						}
+						var_types {
+							replicas = "number"
+						}
+					}`,
+				ExpectError: regexp.MustCompile(`variable replicas: expected number, got "many"`),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ .replicas }}"
+						var_types {
+							replicas = "integer"
+						}
+					}`,
+				ExpectError: regexp.MustCompile(`variable replicas, invalid type: "integer"`),
+			},
+		},
+	})
+}
+
+func TestGoTemplateCoerceTypes(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template     = "{{ printf \"%T %T %T\" .replicas .enabled .zone }}{{ if not .debug }} quiet{{ end }}"
+						coerce_types = true
+						vars {
+							replicas = "2"
+							enabled  = "true"
+							debug    = "false"
+							zone     = "01"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "int bool string quiet"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateRenderPasses(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ \"{{ .name }}\" }}"
+						vars {
+							name = "web"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "{{ .name }}"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template      = "{{ \"{{ .name }}\" }}"
+						render_passes = 3
+						vars {
+							name = "web"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "web"),
+			},
+		},
+	})
+}
+
 func testTemplateConfig(template, vars string) string {
 	return fmt.Sprintf(`
 		data "gotemplate_file" "test" {
@@ -0,0 +1,72 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+func goDataSourceFunctions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFunctionsRead,
+		Schema: map[string]*schema.Schema{
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "A sorted list of the template functions available",
+			},
+			"descriptions": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "A map of the template function name to a one line description",
+			},
+			"signatures": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "A map of the template function name to its signature",
+			},
+		},
+	}
+}
+
+// dataSourceFunctionsRead is responsible for listing the template functions of the configured
+// provider, those removed by its disable_functions being left out
+func dataSourceFunctionsRead(d *schema.ResourceData, meta interface{}) error {
+	funcs := render.Funcs()
+	for _, name := range defaultsOf(meta).disableFuncs {
+		delete(funcs, name)
+	}
+	signatures := render.Signatures(funcs)
+
+	var names []string
+	for name := range signatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	d.Set("descriptions", render.Descriptions(funcs))
+	d.Set("names", names)
+	d.Set("signatures", signatures)
+	d.SetId(hash(strings.Join(names, ",")))
+
+	return nil
+}
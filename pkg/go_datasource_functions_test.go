@@ -0,0 +1,70 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+func TestGoDataSourceFunctions(t *testing.T) {
+	resource := goDataSourceFunctions()
+	if resource == nil {
+		t.Error("we should have recieved the provider schema")
+	}
+}
+
+func TestGoTemplateFunctions(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "gotemplate_functions" "test" {}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_functions.test", "names.#", fmt.Sprintf("%d", len(render.Funcs()))),
+					resource.TestCheckResourceAttr("data.gotemplate_functions.test", "signatures.upper", "func(string) string"),
+					resource.TestCheckResourceAttr("data.gotemplate_functions.test", "descriptions.upper", "converts the string to uppercase"),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateFunctionsDisabled(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "gotemplate" {
+						disable_functions = ["upper"]
+					}
+
+					data "gotemplate_functions" "test" {}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_functions.test", "names.#", fmt.Sprintf("%d", len(render.Funcs())-1)),
+					resource.TestCheckNoResourceAttr("data.gotemplate_functions.test", "signatures.upper"),
+					resource.TestCheckNoResourceAttr("data.gotemplate_functions.test", "descriptions.upper"),
+					resource.TestCheckResourceAttr("data.gotemplate_functions.test", "signatures.lower", "func(string) string"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/jsonpatch"
+)
+
+func goDataSourceJSONPatch() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceJSONPatchRead,
+		Schema: map[string]*schema.Schema{
+			"base_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path relative document and patch paths are resolved against, i.e. path.module",
+			},
+			"document": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The json document, or a path to it, to be patched, i.e. a rendered gotemplate_file",
+			},
+			"patch": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"merge_patch"},
+				Description:   "A rfc 6902 json patch, or a path to one, applied to the document",
+			},
+			"merge_patch": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"patch"},
+				Description:   "A rfc 7386 json merge patch, or a path to one, applied to the document",
+			},
+			"pretty": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Indicates the patched document should be indented",
+			},
+			"rendered": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The patched document",
+			},
+		},
+	}
+}
+
+// dataSourceJSONPatchRead is responsible for applying the patch to the document
+func dataSourceJSONPatchRead(d *schema.ResourceData, meta interface{}) error {
+	basePath := d.Get("base_path").(string)
+
+	document, err := readPathOrContents(basePath, d.Get("document").(string))
+	if err != nil {
+		return fmt.Errorf("unable to read the document, error: %s", err)
+	}
+
+	// step: apply whichever of the patches has been given
+	var patched []byte
+	if patch, found := d.GetOk("patch"); found {
+		content, err := readPathOrContents(basePath, patch.(string))
+		if err != nil {
+			return fmt.Errorf("unable to read the patch, error: %s", err)
+		}
+		if patched, err = jsonpatch.Apply([]byte(document), []byte(content)); err != nil {
+			return fmt.Errorf("unable to apply the patch, error: %s", err)
+		}
+	} else if patch, found := d.GetOk("merge_patch"); found {
+		content, err := readPathOrContents(basePath, patch.(string))
+		if err != nil {
+			return fmt.Errorf("unable to read the merge patch, error: %s", err)
+		}
+		if patched, err = jsonpatch.MergePatch([]byte(document), []byte(content)); err != nil {
+			return fmt.Errorf("unable to apply the merge patch, error: %s", err)
+		}
+	} else {
+		return fmt.Errorf("either patch or merge_patch must be set")
+	}
+
+	if d.Get("pretty").(bool) {
+		indented := new(bytes.Buffer)
+		if err := json.Indent(indented, patched, "", "  "); err != nil {
+			return fmt.Errorf("unable to indent the document, error: %s", err)
+		}
+		patched = append(indented.Bytes(), '\n')
+	}
+	rendered := string(patched)
+
+	d.Set("rendered", rendered)
+	d.SetId(hash(rendered))
+
+	return nil
+}
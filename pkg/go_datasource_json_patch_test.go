@@ -0,0 +1,74 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoTemplateJSONPatch(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"base.json":  `{"name": "app", "replicas": 1, "ports": [80]}`,
+		"patch.json": `[{"op": "replace", "path": "/replicas", "value": 3}, {"op": "add", "path": "/ports/-", "value": 443}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_json_patch" "test" {
+						base_path = "%s"
+						document  = "base.json"
+						patch     = "patch.json"
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_json_patch.test", "rendered", `{"name":"app","ports":[80,443],"replicas":3}`),
+			},
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_json_patch" "test" {
+						base_path   = "%s"
+						document    = "base.json"
+						merge_patch = "{\"replicas\": null, \"labels\": {\"team\": \"web\"}}"
+						pretty      = true
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_json_patch.test", "rendered", "{\n  \"labels\": {\n    \"team\": \"web\"\n  },\n  \"name\": \"app\",\n  \"ports\": [\n    80\n  ]\n}\n"),
+			},
+			{
+				Config: `
+					data "gotemplate_json_patch" "test" {
+						document = "{\"a\": 1}"
+						patch    = "[{\"op\": \"test\", \"path\": \"/a\", \"value\": 2}]"
+					}`,
+				ExpectError: regexp.MustCompile("unable to apply the patch"),
+			},
+			{
+				Config: `
+					data "gotemplate_json_patch" "test" {
+						document = "{}"
+					}`,
+				ExpectError: regexp.MustCompile("either patch or merge_patch must be set"),
+			},
+		},
+	})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+const (
+	// mergeFormatYAML outputs the merged document as yaml
+	mergeFormatYAML = "yaml"
+	// mergeFormatJSON outputs the merged document as json
+	mergeFormatJSON = "json"
+)
+
+func goDataSourceMerge() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceMergeRead,
+		Schema: map[string]*schema.Schema{
+			"base_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path relative document paths are resolved against, i.e. path.module",
+			},
+			"documents": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "An ordered list of the yaml or json documents, or paths to them, later documents taking precedence",
+			},
+			"strategy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      values.StrategyOverride,
+				Description:  "How values other than maps are merged: override, append-lists or fail-on-conflict",
+				ValidateFunc: validation.StringInSlice(values.Strategies, false),
+			},
+			"output_format": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      mergeFormatYAML,
+				Description:  "The format of the merged document, either yaml or json",
+				ValidateFunc: validation.StringInSlice([]string{mergeFormatYAML, mergeFormatJSON}, false),
+			},
+			"rendered": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The merged document",
+			},
+		},
+	}
+}
+
+// dataSourceMergeRead is responsible for deep merging the documents
+func dataSourceMergeRead(d *schema.ResourceData, meta interface{}) error {
+	basePath := d.Get("base_path").(string)
+
+	var layers []map[string]interface{}
+	for i, x := range d.Get("documents").([]interface{}) {
+		content, err := readPathOrContents(basePath, x.(string))
+		if err != nil {
+			return fmt.Errorf("unable to read document %d, error: %s", i, err)
+		}
+		layer, err := values.Parse(content)
+		if err != nil {
+			return fmt.Errorf("unable to decode document %d, error: %s", i, err)
+		}
+		layers = append(layers, layer)
+	}
+	merged, err := values.MergeWithStrategy(d.Get("strategy").(string), layers...)
+	if err != nil {
+		return err
+	}
+	rendered, err := encodeDocument(merged, d.Get("output_format").(string))
+	if err != nil {
+		return err
+	}
+
+	d.Set("rendered", rendered)
+	d.SetId(hash(rendered))
+
+	return nil
+}
+
+// encodeDocument is responsible for encoding the document in the format, the keys being sorted
+func encodeDocument(document map[string]interface{}, format string) (string, error) {
+	switch format {
+	case mergeFormatJSON:
+		encoded, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("unable to encode the document, error: %s", err)
+		}
+		return string(encoded) + "\n", nil
+	case mergeFormatYAML, "":
+		encoded, err := yaml.Marshal(document)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode the document, error: %s", err)
+		}
+		return string(encoded), nil
+	}
+
+	return "", fmt.Errorf("unsupported output format: %s", format)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoTemplateMerge(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"base.yaml": "image:\n  name: app\n  tag: v1\nports: [80]\n",
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_merge" "test" {
+						base_path = "%s"
+						documents = ["base.yaml", "{\"image\": {\"tag\": \"v2\"}, \"ports\": [443]}"]
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_merge.test", "rendered", "image:\n  name: app\n  tag: v2\nports:\n- 443\n"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_merge" "test" {
+						base_path     = "%s"
+						documents     = ["base.yaml", "ports: [443]"]
+						strategy      = "append-lists"
+						output_format = "json"
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_merge.test", "rendered", "{\n  \"image\": {\n    \"name\": \"app\",\n    \"tag\": \"v1\"\n  },\n  \"ports\": [\n    80,\n    443\n  ]\n}\n"),
+			},
+			{
+				Config: `
+					data "gotemplate_merge" "test" {
+						documents = ["a: 1", "a: 2"]
+						strategy  = "fail-on-conflict"
+					}`,
+				ExpectError: regexp.MustCompile("conflicting values for key: a"),
+			},
+			{
+				Config: `
+					data "gotemplate_merge" "test" {
+						documents = ["- a"]
+					}`,
+				ExpectError: regexp.MustCompile("unable to decode document 0"),
+			},
+		},
+	})
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+func goDataSourceSnippetIndex() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceSnippetIndexRead,
+		Schema: map[string]*schema.Schema{
+			"base_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path a relative snippets path is resolved against, i.e. path.module",
+			},
+			"snippets": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The path to a directory containing snippets",
+			},
+			"follow_symlinks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Follow symbolic links when scanning the snippets, by default they are skipped",
+			},
+			"include_hidden": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Scan snippets whose name begins with a dot, by default they are skipped",
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "A sorted list of the names of the defined templates",
+			},
+			"templates": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The defined templates along with their source file and doc comment",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the define or block",
+						},
+						"file": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The path of the file relative to the snippets directory",
+						},
+						"doc": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The comment immediately preceding the define",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceSnippetIndexRead is responsible for cataloguing the defines in the snippets directory
+func dataSourceSnippetIndexRead(d *schema.ResourceData, meta interface{}) error {
+	dir := resolvePath(d.Get("base_path").(string), d.Get("snippets").(string))
+	options := render.Options{
+		FollowSymlinks: d.Get("follow_symlinks").(bool),
+		IncludeHidden:  d.Get("include_hidden").(bool),
+	}
+	entries, err := render.Index(dir, options)
+	if err != nil {
+		return fmt.Errorf("failed to index snippets at: %s, error: %s", dir, err)
+	}
+
+	var names []string
+	var templates []map[string]interface{}
+	var content string
+	seen := make(map[string]bool)
+	for _, x := range entries {
+		if !seen[x.Name] {
+			names = append(names, x.Name)
+			seen[x.Name] = true
+		}
+		templates = append(templates, map[string]interface{}{
+			"name": x.Name,
+			"file": x.File,
+			"doc":  x.Doc,
+		})
+		content += fmt.Sprintf("%s:%s:%s:", x.Name, x.File, hash(x.Doc))
+	}
+
+	d.Set("names", names)
+	d.Set("templates", templates)
+	d.SetId(hash(dir + ":" + content))
+
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoDataSourceSnippetIndex(t *testing.T) {
+	resource := goDataSourceSnippetIndex()
+	if resource == nil {
+		t.Error("we should have recieved the provider schema")
+	}
+}
+
+func TestGoTemplateSnippetIndex(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"snippets/banner.tmpl": "{{/* the managed by header */}}\n{{ define \"banner\" }}# managed{{ end }}",
+		"snippets/a/b.tmpl":    `{{ define "another" }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_snippet_index" "test" {
+						base_path = "%s"
+						snippets  = "snippets"
+					}`, filepath.ToSlash(dir)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_snippet_index.test", "names.#", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_snippet_index.test", "names.0", "another"),
+					resource.TestCheckResourceAttr("data.gotemplate_snippet_index.test", "templates.0.file", "a/b.tmpl"),
+					resource.TestCheckResourceAttr("data.gotemplate_snippet_index.test", "templates.1.name", "banner"),
+					resource.TestCheckResourceAttr("data.gotemplate_snippet_index.test", "templates.1.doc", "the managed by header"),
+				),
+			},
+		},
+	})
+}
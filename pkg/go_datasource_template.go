@@ -0,0 +1,79 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func goDataSourceTemplate() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceTemplateRead,
+		Schema: map[string]*schema.Schema{
+			"base_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path a relative template path is resolved against, i.e. path.module",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name the template is registered under, referenced by the ref attribute of gotemplate_file",
+			},
+			"template": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The contents of, or path to, the template registered",
+			},
+			"vars": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Default:     make(map[string]interface{}),
+				Description: "The default variables of the template, overridden by those of the referencing template",
+			},
+			"content_sha256": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The sha256 of the registered template content",
+			},
+		},
+	}
+}
+
+// dataSourceTemplateRead is responsible for registering the template with the provider
+func dataSourceTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	registry := registryOf(meta)
+	if registry == nil {
+		return fmt.Errorf("the provider holds no template registry")
+	}
+	name := d.Get("name").(string)
+	content, err := readPathOrContents(d.Get("base_path").(string), d.Get("template").(string))
+	if err != nil {
+		return err
+	}
+	template := registeredTemplate{content: content, vars: d.Get("vars").(map[string]interface{})}
+	if err := registry.register(name, template); err != nil {
+		return err
+	}
+	logEvent(logDebug, "template registered", "name", name, "bytes", len(content), "vars", len(template.vars))
+
+	d.Set("content_sha256", hash(content))
+	d.SetId(name)
+
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestGoTemplateTemplate(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_template" "banner" {
+						name     = "banner"
+						template = "# {{ .name }} ({{ .env }})"
+						vars {
+							name = "default"
+							env  = "dev"
+						}
+					}
+					data "gotemplate_file" "web" {
+						ref  = "${data.gotemplate_template.banner.name}"
+						vars = { name = "web" }
+					}
+					data "gotemplate_file" "db" {
+						ref  = "${data.gotemplate_template.banner.id}"
+						vars = { env = "prod" }
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_template.banner", "content_sha256", hash("# {{ .name }} ({{ .env }})")),
+					resource.TestCheckResourceAttr("data.gotemplate_file.web", "rendered", "# web (dev)"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.db", "rendered", "# default (prod)"),
+				),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						ref = "unregistered"
+					}`,
+				ExpectError: regexp.MustCompile("template unregistered is not registered"),
+			},
+		},
+	})
+}
+
+// TestGoTemplateTemplateFreshProvider configures a fresh provider for every walk, as terraform does
+// between a plan and the apply, so the registry holds only the templates read by the walk
+func TestGoTemplateTemplateFreshProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "banner.txt")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: map[string]terraform.ResourceProviderFactory{
+			"gotemplate": func() (terraform.ResourceProvider, error) { return Provider(), nil },
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_template" "banner" {
+						name     = "banner"
+						template = "# {{ .name }}"
+					}
+					data "gotemplate_file" "web" {
+						ref  = "${data.gotemplate_template.banner.name}"
+						vars = { name = "web" }
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.web", "rendered", "# web"),
+			},
+			{
+				// a resource is rendered again by the apply, which does not read the data sources
+				Config: fmt.Sprintf(`
+					data "gotemplate_template" "banner" {
+						name     = "banner"
+						template = "# {{ .name }}"
+					}
+					resource "gotemplate_local_file" "web" {
+						ref      = "${data.gotemplate_template.banner.name}"
+						filename = "%s"
+						vars     = { name = "web" }
+					}`, filepath.ToSlash(filename)),
+				ExpectError: regexp.MustCompile("invalid or unknown key: ref"),
+			},
+			{
+				// the gotemplate_file resource is the data source rendered by the apply as well
+				Config: `
+					data "gotemplate_template" "banner" {
+						name     = "banner"
+						template = "# {{ .name }}"
+					}
+					resource "gotemplate_file" "web" {
+						ref  = "${data.gotemplate_template.banner.name}"
+						vars = { name = "web" }
+					}`,
+				ExpectError: regexp.MustCompile("invalid or unknown key: ref"),
+			},
+		},
+	})
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func goResourceConsulKey() *schema.Resource {
+	s := templateSchema()
+	s["path"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The consul kv path the rendered template is written to",
+	}
+	s["datacenter"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "The datacenter of the key, defaulting to the provider setting or that of the agent",
+	}
+	s["cas"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Use check-and-set writes, failing rather than overwriting a key created or modified outside of terraform",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The content of the key as last read",
+	}
+	s["modify_index"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "The consul modify index of the key as last written by terraform, the index a check-and-set write expects",
+	}
+	s["content_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The sha256 of the content as last written by terraform",
+	}
+	s["content_diff"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "A unified diff of the content planned by the last change, redacted and truncated, or a note while the inputs are not known until apply",
+	}
+	s["input_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The hash of the inputs of the render as last written",
+	}
+
+	return &schema.Resource{
+		Create:        resourceConsulKeyWrite,
+		Read:          resourceConsulKeyRead,
+		Update:        resourceConsulKeyWrite,
+		Delete:        resourceConsulKeyDelete,
+		CustomizeDiff: renderedDiff(s, writtenAsIs),
+		Schema:        s,
+	}
+}
+
+// resourceConsulKeyWrite is responsible for rendering the template and writing it to the key
+func resourceConsulKeyWrite(d *schema.ResourceData, meta interface{}) error {
+	inputs, err := inputHash(d, meta, goResourceConsulKey().Schema)
+	if err != nil {
+		return fmt.Errorf("unable to hash the inputs, error: %s", err)
+	}
+	rendered, _, err := renderResource(d, meta)
+	if err != nil {
+		return err
+	}
+	client, err := consulOf(meta)
+	if err != nil {
+		return fmt.Errorf("unable to create the consul client, error: %s", err)
+	}
+	path := d.Get("path").(string)
+
+	// step: skip the write when the key already holds the content as written by terraform; the diff
+	// marks both computed, so they are compared with the prior state
+	current, _ := d.GetChange("rendered")
+	checksum, _ := d.GetChange("content_sha256")
+	if d.Id() != "" && rendered == current.(string) && hash(rendered) == checksum.(string) {
+		d.Set("content_sha256", hash(rendered))
+		d.Set("input_sha256", inputs)
+		return resourceConsulKeyRead(d, meta)
+	}
+	// step: write the key in a transaction, which returns the index of our write; a check-and-set
+	// with a modify index of zero only permits the write when the key does not exist
+	op := &api.KVTxnOp{Verb: api.KVSet, Key: path, Value: []byte(rendered)}
+	if d.Get("cas").(bool) {
+		op.Verb = api.KVCAS
+		if d.Id() != "" {
+			op.Index = uint64(d.Get("modify_index").(int))
+		}
+	}
+	written, response, _, err := client.KV().Txn(api.KVTxnOps{op}, &api.QueryOptions{Datacenter: d.Get("datacenter").(string)})
+	if err != nil {
+		return fmt.Errorf("unable to write the consul key: %s, error: %s", path, err)
+	}
+	if !written {
+		if op.Verb == api.KVCAS {
+			return fmt.Errorf("consul key: %s has been created or modified outside of terraform", path)
+		}
+		var errs []string
+		for _, x := range response.Errors {
+			errs = append(errs, x.What)
+		}
+		return fmt.Errorf("unable to write the consul key: %s, error: %s", path, strings.Join(errs, ", "))
+	}
+	logEvent(logDebug, "consul key written", "path", path, "bytes", len(rendered))
+
+	// step: record the index of our write, which the next check-and-set write expects; the read
+	// does not refresh it, so a change made outside of terraform fails the next write
+	if len(response.Results) > 0 {
+		d.Set("modify_index", int(response.Results[0].ModifyIndex))
+	}
+	d.SetId(path)
+	d.Set("content_sha256", hash(rendered))
+	d.Set("input_sha256", inputs)
+
+	return resourceConsulKeyRead(d, meta)
+}
+
+// resourceConsulKeyRead is responsible for reading the content of the key, any divergence from
+// what was written being planned as an update by the diff
+func resourceConsulKeyRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := consulOf(meta)
+	if err != nil {
+		return fmt.Errorf("unable to create the consul client, error: %s", err)
+	}
+	pair, _, err := client.KV().Get(d.Id(), &api.QueryOptions{Datacenter: d.Get("datacenter").(string)})
+	if err != nil {
+		return fmt.Errorf("unable to read the consul key: %s, error: %s", d.Id(), err)
+	}
+	if pair == nil {
+		logEvent(logWarn, "consul key no longer exists, removing from state", "path", d.Id())
+		d.SetId("")
+		return nil
+	}
+	d.Set("path", d.Id())
+	d.Set("rendered", string(pair.Value))
+
+	return nil
+}
+
+// resourceConsulKeyDelete is responsible for deleting the key
+func resourceConsulKeyDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := consulOf(meta)
+	if err != nil {
+		return fmt.Errorf("unable to create the consul client, error: %s", err)
+	}
+	options := &api.WriteOptions{Datacenter: d.Get("datacenter").(string)}
+	if d.Get("cas").(bool) {
+		pair := &api.KVPair{Key: d.Id(), ModifyIndex: uint64(d.Get("modify_index").(int))}
+		deleted, _, err := client.KV().DeleteCAS(pair, options)
+		if err != nil {
+			return fmt.Errorf("unable to delete the consul key: %s, error: %s", d.Id(), err)
+		}
+		if !deleted {
+			return fmt.Errorf("consul key: %s has been modified outside of terraform", d.Id())
+		}
+	} else if _, err := client.KV().Delete(d.Id(), options); err != nil {
+		return fmt.Errorf("unable to delete the consul key: %s, error: %s", d.Id(), err)
+	}
+	d.SetId("")
+
+	return nil
+}
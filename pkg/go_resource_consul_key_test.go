@@ -0,0 +1,300 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// fakeConsul is a minimal in memory implementation of the consul kv api
+type fakeConsul struct {
+	sync.Mutex
+	index uint64
+	keys  map[string]*api.KVPair
+	// race is a value written by another writer straight after the next transaction
+	race string
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if r.URL.Path == "/v1/txn" {
+		f.txn(w, r)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	pair, found := f.keys[key]
+	// step: a check-and-set request requires the key to be at the given index, zero meaning absent
+	if cas := r.URL.Query().Get("cas"); cas != "" {
+		index, _ := strconv.ParseUint(cas, 10, 64)
+		if (index == 0 && found) || (index != 0 && (!found || pair.ModifyIndex != index)) {
+			fmt.Fprint(w, "false")
+			return
+		}
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]*api.KVPair{pair})
+	case http.MethodPut:
+		value, _ := ioutil.ReadAll(r.Body)
+		f.index++
+		f.keys[key] = &api.KVPair{Key: key, Value: value, ModifyIndex: f.index}
+		fmt.Fprint(w, "true")
+	case http.MethodDelete:
+		delete(f.keys, key)
+		fmt.Fprint(w, "true")
+	}
+}
+
+// txn applies the set and check-and-set operations of a transaction, returning the entries written
+func (f *fakeConsul) txn(w http.ResponseWriter, r *http.Request) {
+	var ops api.TxnOps
+	json.NewDecoder(r.Body).Decode(&ops)
+
+	response := api.TxnResponse{}
+	for i, x := range ops {
+		pair, found := f.keys[x.KV.Key]
+		if x.KV.Verb == api.KVCAS && ((x.KV.Index == 0 && found) || (x.KV.Index != 0 && (!found || pair.ModifyIndex != x.KV.Index))) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(api.TxnResponse{Errors: api.TxnErrors{{OpIndex: i, What: "failed to set key"}}})
+			return
+		}
+		f.index++
+		f.keys[x.KV.Key] = &api.KVPair{Key: x.KV.Key, Value: x.KV.Value, ModifyIndex: f.index}
+		response.Results = append(response.Results, &api.TxnResult{KV: &api.KVPair{Key: x.KV.Key, ModifyIndex: f.index}})
+		if f.race != "" {
+			f.index++
+			f.keys[x.KV.Key] = &api.KVPair{Key: x.KV.Key, Value: []byte(f.race), ModifyIndex: f.index}
+			f.race = ""
+		}
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// value returns the content of the key, if any
+func (f *fakeConsul) value(key string) (string, bool) {
+	f.Lock()
+	defer f.Unlock()
+	if pair, found := f.keys[key]; found {
+		return string(pair.Value), true
+	}
+
+	return "", false
+}
+
+// set writes the key as a change made outside of terraform would
+func (f *fakeConsul) set(key, value string) {
+	f.Lock()
+	defer f.Unlock()
+	f.index++
+	f.keys[key] = &api.KVPair{Key: key, Value: []byte(value), ModifyIndex: f.index}
+}
+
+// testConsul returns a fake consul server and the provider block pointing at it
+func testConsul(t *testing.T) (*fakeConsul, *httptest.Server, string) {
+	consul := &fakeConsul{keys: make(map[string]*api.KVPair)}
+	server := httptest.NewServer(consul)
+
+	return consul, server, fmt.Sprintf(`
+		provider "gotemplate" {
+			consul {
+				address = "%s"
+			}
+		}`, server.URL)
+}
+
+func TestGoTemplateConsulKey(t *testing.T) {
+	consul, server, provider := testConsul(t)
+	defer server.Close()
+
+	config := func(name string) string {
+		return provider + fmt.Sprintf(`
+			resource "gotemplate_consul_key" "test" {
+				path     = "app/config"
+				template = "name: {{ .name }}"
+				cas      = true
+				vars {
+					name = "%s"
+				}
+			}`, name)
+	}
+	checkValue := func(expected string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if value, _ := consul.value("app/config"); value != expected {
+				return fmt.Errorf("consul key holds: %q, want: %q", value, expected)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(*terraform.State) error {
+			if _, found := consul.value("app/config"); found {
+				return fmt.Errorf("expected the consul key to be deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("web"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_consul_key.test", "rendered", "name: web"),
+					resource.TestCheckResourceAttr("gotemplate_consul_key.test", "modify_index", "1"),
+					checkValue("name: web"),
+				),
+			},
+			{
+				Config: config("api"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_consul_key.test", "rendered", "name: api"),
+					resource.TestCheckResourceAttr("gotemplate_consul_key.test", "modify_index", "2"),
+					checkValue("name: api"),
+				),
+			},
+			{
+				// a change of the inputs rendering the same content skips the write
+				Config: provider + `
+					resource "gotemplate_consul_key" "test" {
+						path     = "app/config"
+						template = "name: {{ .name }}"
+						cas      = true
+						vars {
+							name   = "api"
+							unused = "x"
+						}
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_consul_key.test", "rendered", "name: api"),
+					resource.TestCheckResourceAttr("gotemplate_consul_key.test", "modify_index", "2"),
+					checkValue("name: api"),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateConsulKeyCAS(t *testing.T) {
+	consul, server, provider := testConsul(t)
+	defer server.Close()
+	consul.keys["app/taken"] = &api.KVPair{Key: "app/taken", Value: []byte("manual"), ModifyIndex: 1}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + `
+					resource "gotemplate_consul_key" "test" {
+						path     = "app/taken"
+						template = "generated"
+						cas      = true
+					}`,
+				ExpectError: regexp.MustCompile("consul key: app/taken has been created or modified outside of terraform"),
+			},
+		},
+	})
+	if value, _ := consul.value("app/taken"); value != "manual" {
+		t.Errorf("expected the key to be left untouched, got: %q", value)
+	}
+}
+
+func TestGoTemplateConsulKeyDrift(t *testing.T) {
+	consul, server, provider := testConsul(t)
+	defer server.Close()
+	dir := testTemplateDir(t, map[string]string{"app.tmpl": "name: web"})
+	defer os.RemoveAll(dir)
+	template := filepath.Join(dir, "app.tmpl")
+
+	config := func(cas bool) string {
+		return provider + fmt.Sprintf(`
+			resource "gotemplate_consul_key" "test" {
+				path     = "app/config"
+				template = "%s"
+				cas      = %t
+			}`, filepath.ToSlash(template), cas)
+	}
+	checkValue := func(expected string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if value, _ := consul.value("app/config"); value != expected {
+				return fmt.Errorf("consul key holds: %q, want: %q", value, expected)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config(true),
+				Check:  checkValue("name: web"),
+			},
+			{
+				// another writer straight after our write, before its index could be read back
+				PreConfig: func() {
+					ioutil.WriteFile(template, []byte("name: race"), 0644)
+					consul.Lock()
+					consul.race = "racer"
+					consul.Unlock()
+				},
+				Config:             config(true),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// the index recorded is that of our write, so the check-and-set refuses the change
+				PreConfig:   func() { ioutil.WriteFile(template, []byte("name: web"), 0644) },
+				Config:      config(true),
+				ExpectError: regexp.MustCompile("consul key: app/config has been created or modified outside of terraform"),
+			},
+			{
+				// with check-and-set the change is refused rather than overwritten
+				PreConfig:   func() { consul.set("app/config", "manual") },
+				Config:      config(true),
+				ExpectError: regexp.MustCompile("consul key: app/config has been created or modified outside of terraform"),
+			},
+			{
+				// without check-and-set the key is taken back over, so may be deleted
+				PreConfig: func() {
+					if value, _ := consul.value("app/config"); value != "manual" {
+						t.Errorf("expected the key to be left untouched, got: %q", value)
+					}
+				},
+				Config: config(false),
+				Check:  checkValue("name: web"),
+			},
+		},
+	})
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func goResourceFileBlock() *schema.Resource {
+	s := templateSchema()
+	s["filename"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The path of the file holding the block",
+	}
+	s["marker"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		Default:      "# {mark} gotemplate",
+		ValidateFunc: validateBlockMarker,
+		Description:  "The line marking the block, {mark} being replaced by BEGIN and END",
+	}
+	s["target_os"] = targetOSSchema()
+	s["post_write_command"] = postWriteCommandSchema()
+	s["command_timeout"] = commandTimeoutSchema()
+	s["lock"] = lockSchema()
+	s["lock_timeout"] = lockTimeoutSchema()
+	s["create"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Create the file when it does not exist, otherwise failing",
+	}
+	s["file_permission"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "0644",
+		ValidateFunc: validateFileMode,
+		Description:  "The octal permissions of the file when created, an existing file keeping its own",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The content of the block as last read",
+	}
+	s["content_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The sha256 of the content of the block as last written by terraform",
+	}
+	s["content_diff"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "A unified diff of the content of the block planned by the last change, redacted and truncated, or a note while the inputs are not known until apply",
+	}
+	s["input_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The hash of the inputs of the render as last written",
+	}
+
+	return &schema.Resource{
+		Create:        resourceFileBlockWrite,
+		Read:          resourceFileBlockRead,
+		Update:        resourceFileBlockWrite,
+		Delete:        resourceFileBlockDelete,
+		CustomizeDiff: renderedDiff(s, writtenAsBlock),
+		Importer:      &schema.ResourceImporter{State: resourceFileBlockImport},
+		Schema:        s,
+	}
+}
+
+// resourceFileBlockWrite is responsible for rendering the template and writing it to the block,
+// the rest of the file being left untouched
+func resourceFileBlockWrite(d *schema.ResourceData, meta interface{}) error {
+	inputs, err := inputHash(d, meta, goResourceFileBlock().Schema)
+	if err != nil {
+		return fmt.Errorf("unable to hash the inputs, error: %s", err)
+	}
+	rendered, scoped, err := renderResource(d, meta)
+	if err != nil {
+		return err
+	}
+	filename := d.Get("filename").(string)
+	begin, end := blockMarkers(d.Get("marker").(string))
+
+	mode := parseFileMode(d.Get("file_permission").(string))
+	if _, err := os.Stat(filename); os.IsNotExist(err) && !d.Get("create").(bool) {
+		return fmt.Errorf("the file: %s does not exist", filename)
+	}
+	// step: the lock is held across the read and write so a concurrent edit is not lost
+	release, err := lockResourceFile(d, filename, mode)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	current, err := ioutil.ReadFile(filename)
+	switch {
+	case err == nil:
+		info, err := os.Stat(filename)
+		if err != nil {
+			return fmt.Errorf("unable to stat the file: %s, error: %s", filename, err)
+		}
+		mode = info.Mode().Perm()
+	case !os.IsNotExist(err):
+		return fmt.Errorf("unable to read the file: %s, error: %s", filename, err)
+	}
+	body := writtenAsBlock(d, rendered)
+	content, err := writeBlock(string(current), begin, end, body, lineEnding(d.Get("target_os").(string)))
+	if err != nil {
+		return fmt.Errorf("unable to update the block in the file: %s, error: %s", filename, err)
+	}
+	if err := ioutil.WriteFile(filename, []byte(content), mode); err != nil {
+		return fmt.Errorf("unable to write the file: %s, error: %s", filename, err)
+	}
+	logEvent(logDebug, "file block written", "filename", filename, "bytes", len(rendered))
+	d.SetId(fileBlockID(filename, d.Get("marker").(string)))
+	d.Set("content_sha256", hash(body))
+	d.Set("input_sha256", inputs)
+	if err := resourceFileBlockRead(d, meta); err != nil {
+		return err
+	}
+
+	return scoped.redactError(runPostWriteCommand(d, meta, filename))
+}
+
+// resourceFileBlockRead is responsible for reading the content of the block, the resource being
+// removed from state when the file or the block no longer exists
+func resourceFileBlockRead(d *schema.ResourceData, meta interface{}) error {
+	filename, marker := parseFileBlockID(d.Id(), d.Get("marker").(string))
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		logEvent(logWarn, "file no longer exists, removing from state", "filename", filename)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the file: %s, error: %s", filename, err)
+	}
+	begin, end := blockMarkers(marker)
+	body, found, err := readBlock(string(content), begin, end)
+	if err != nil {
+		return fmt.Errorf("unable to read the block in the file: %s, error: %s", filename, err)
+	}
+	if !found {
+		logEvent(logWarn, "block no longer exists in the file, removing from state", "filename", filename, "marker", begin)
+		d.SetId("")
+		return nil
+	}
+	d.SetId(fileBlockID(filename, marker))
+	d.Set("filename", filename)
+	d.Set("marker", marker)
+	d.Set("rendered", body)
+
+	return nil
+}
+
+// resourceFileBlockImport is responsible for adopting an existing block, the id being given as
+// filename|marker, or the filename alone for the block found by the default marker
+func resourceFileBlockImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	filename, marker := parseFileBlockID(d.Id(), goResourceFileBlock().Schema["marker"].Default.(string))
+	if _, errs := validateBlockMarker(marker, "marker"); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	d.SetId(fileBlockID(filename, marker))
+	d.Set("marker", marker)
+	if err := resourceFileBlockRead(d, meta); err != nil {
+		return nil, err
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("the block %q does not exist in the file: %s", marker, filename)
+	}
+	d.Set("content_sha256", hash(d.Get("rendered").(string)))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceFileBlockDelete is responsible for removing the block from the file
+func resourceFileBlockDelete(d *schema.ResourceData, meta interface{}) error {
+	filename, marker := parseFileBlockID(d.Id(), d.Get("marker").(string))
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		d.SetId("")
+		return nil
+	}
+	release, err := lockResourceFile(d, filename, parseFileMode(d.Get("file_permission").(string)))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	current, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("unable to read the file: %s, error: %s", filename, err)
+	}
+	begin, end := blockMarkers(marker)
+	content, err := removeBlock(string(current), begin, end)
+	if err != nil {
+		return fmt.Errorf("unable to remove the block from the file: %s, error: %s", filename, err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("unable to stat the file: %s, error: %s", filename, err)
+	}
+	if err := ioutil.WriteFile(filename, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("unable to write the file: %s, error: %s", filename, err)
+	}
+	d.SetId("")
+
+	return nil
+}
+
+// fileBlockID returns the id of the block, the filename and marker joined by a |, so the blocks of
+// different markers in the same file are told apart
+func fileBlockID(filename, marker string) string {
+	return filename + "|" + marker
+}
+
+// parseFileBlockID returns the filename and marker of the id, the marker defaulting to the one
+// given when the id is the filename alone
+func parseFileBlockID(id, marker string) (string, string) {
+	if i := strings.Index(id, "|"); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+
+	return id, marker
+}
+
+// writtenAsBlock is the transformation of the write of a block, ending the body in a newline and
+// converting the line endings for the target_os
+func writtenAsBlock(d attributeGetter, rendered string) string {
+	return convertLineEndings(blockBody(rendered), d.Get("target_os").(string))
+}
+
+// validateBlockMarker checks the marker holds the {mark} placeholder on a single line
+func validateBlockMarker(v interface{}, k string) ([]string, []error) {
+	if marker := v.(string); !strings.Contains(marker, "{mark}") || strings.ContainsAny(marker, "\r\n") {
+		return nil, []error{fmt.Errorf("%s: expected a single line holding {mark}, got: %q", k, marker)}
+	}
+
+	return nil, nil
+}
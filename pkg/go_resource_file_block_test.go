@@ -0,0 +1,198 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestGoTemplateFileBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-block")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "hosts")
+	if err := ioutil.WriteFile(filename, []byte("127.0.0.1 localhost\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	config := func(address string) string {
+		return fmt.Sprintf(`
+			resource "gotemplate_file_block" "test" {
+				filename = "%s"
+				template = "{{ .address }} web"
+				vars {
+					address = "%s"
+				}
+			}`, filename, address)
+	}
+	checkFile := func(expected string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			content, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return err
+			}
+			if string(content) != expected {
+				return fmt.Errorf("file holds: %q, want: %q", content, expected)
+			}
+			if info, err := os.Stat(filename); err != nil || info.Mode().Perm() != 0600 {
+				return fmt.Errorf("expected the file to keep its permissions, info: %v, error: %v", info, err)
+			}
+			return nil
+		}
+	}
+	block := func(address string) string {
+		return "127.0.0.1 localhost\n# BEGIN gotemplate\n" + address + " web\n# END gotemplate\n"
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(*terraform.State) error {
+			return checkFile("127.0.0.1 localhost\n")(nil)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("10.0.0.1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_file_block.test", "rendered", "10.0.0.1 web\n"),
+					checkFile(block("10.0.0.1")),
+				),
+			},
+			{
+				Config: config("10.0.0.2"),
+				Check:  checkFile(block("10.0.0.2")),
+			},
+			{
+				// step: a render holding a marker line is refused rather than corrupting the file
+				Config:      config(`10.0.0.3\n# END gotemplate\n10.0.0.4`),
+				ExpectError: regexp.MustCompile(`the body contains the marker "# END gotemplate"`),
+			},
+			{
+				Config: config("10.0.0.2"),
+				Check:  checkFile(block("10.0.0.2")),
+			},
+			{
+				// step: edits outside the block are kept, those within it are corrected
+				PreConfig: func() {
+					content := "# managed elsewhere\n" + block("10.9.9.9")
+					if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+				},
+				Config: config("10.0.0.2"),
+				Check:  checkFile("# managed elsewhere\n" + block("10.0.0.2")),
+			},
+			{
+				PreConfig: func() {
+					if err := ioutil.WriteFile(filename, []byte("127.0.0.1 localhost\n"), 0600); err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+				},
+				Config: config("10.0.0.2"),
+				Check:  checkFile(block("10.0.0.2")),
+			},
+		},
+	})
+}
+
+func TestGoTemplateFileBlockImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-block")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "hosts")
+	content := "127.0.0.1 localhost\n// BEGIN hosts\n10.0.0.1 web\n// END hosts\n"
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	config := fmt.Sprintf(`
+		resource "gotemplate_file_block" "test" {
+			filename = "%s"
+			marker   = "// {mark} hosts"
+			template = "10.0.0.2 web"
+		}`, filename)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:        config,
+				ResourceName:  "gotemplate_file_block.test",
+				ImportState:   true,
+				ImportStateId: filename,
+				ExpectError:   regexp.MustCompile("does not exist in the file"),
+			},
+			{
+				Config:        config,
+				ResourceName:  "gotemplate_file_block.test",
+				ImportState:   true,
+				ImportStateId: filename + "|// {mark} hosts",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].ID != filename+"|// {mark} hosts" || states[0].Attributes["rendered"] != "10.0.0.1 web\n" {
+						return fmt.Errorf("unexpected imported states: %v", states)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestGoTemplateFileBlockMarkers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-block")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "hosts")
+	if err := ioutil.WriteFile(filename, []byte("127.0.0.1 localhost\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "gotemplate_file_block" "web" {
+						filename = "%[1]s"
+						marker   = "# {mark} web"
+						template = "10.0.0.1 web"
+					}
+					resource "gotemplate_file_block" "db" {
+						filename = "%[1]s"
+						marker   = "# {mark} db"
+						template = "10.0.0.2 db"
+						depends_on = ["gotemplate_file_block.web"]
+					}`, filename),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_file_block.web", "id", filename+"|# {mark} web"),
+					resource.TestCheckResourceAttr("gotemplate_file_block.db", "id", filename+"|# {mark} db"),
+				),
+			},
+		},
+	})
+}
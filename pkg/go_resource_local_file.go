@@ -0,0 +1,305 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// backupTimeFormat is the layout of the time included in the filename of a timestamped backup
+const backupTimeFormat = "20060102T150405.000Z"
+
+func goResourceLocalFile() *schema.Resource {
+	s := templateSchema()
+	s["filename"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The path of the file the rendered template is written to",
+	}
+	s["file_permission"] = &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Default:          "0644",
+		ValidateFunc:     validateFileMode,
+		DiffSuppressFunc: suppressFileModeDiff,
+		Description:      "The octal permissions of the file, restored when changed outside of terraform",
+	}
+	s["directory_permission"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "0755",
+		ValidateFunc: validateFileMode,
+		Description:  "The octal permissions of any parent directories created",
+	}
+	s["target_os"] = targetOSSchema()
+	s["output_encoding"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      encodingUTF8,
+		Description:  "The character encoding the file is written in, either utf-8, utf-16le or latin-1",
+		ValidateFunc: validation.StringInSlice(outputEncodings, false),
+	}
+	s["byte_order_mark"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Prefix the file with the byte order mark of the output encoding",
+	}
+	s["post_write_command"] = postWriteCommandSchema()
+	s["command_timeout"] = commandTimeoutSchema()
+	s["lock"] = lockSchema()
+	s["lock_timeout"] = lockTimeoutSchema()
+	s["backup"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Copy the previous content of the file next to it before overwriting",
+	}
+	s["backup_suffix"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      ".bak",
+		ValidateFunc: validation.NoZeroValues,
+		Description:  "The suffix appended to the filename of the backup",
+	}
+	s["backup_timestamp"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Include the time of the backup in its filename, keeping every previous version",
+	}
+	s["backup_file"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The path of the last backup taken",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The content of the file as last read",
+	}
+	s["content_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The sha256 of the content as last written by terraform",
+	}
+	s["content_diff"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "A unified diff of the content planned by the last change, redacted and truncated, or a note while the inputs are not known until apply",
+	}
+	s["input_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The hash of the inputs of the render as last written",
+	}
+
+	return &schema.Resource{
+		Create:        resourceLocalFileWrite,
+		Read:          resourceLocalFileRead,
+		Update:        resourceLocalFileWrite,
+		Delete:        resourceLocalFileDelete,
+		CustomizeDiff: renderedDiff(s, writtenForTarget),
+		Importer:      &schema.ResourceImporter{State: resourceLocalFileImport},
+		Schema:        s,
+	}
+}
+
+// resourceLocalFileWrite is responsible for rendering the template and writing it to the file
+func resourceLocalFileWrite(d *schema.ResourceData, meta interface{}) error {
+	inputs, err := inputHash(d, meta, goResourceLocalFile().Schema)
+	if err != nil {
+		return fmt.Errorf("unable to hash the inputs, error: %s", err)
+	}
+	rendered, scoped, err := renderResource(d, meta)
+	if err != nil {
+		return err
+	}
+	rendered = writtenForTarget(d, rendered)
+	content, err := encodeFile(d, rendered)
+	if err != nil {
+		return err
+	}
+	filename := d.Get("filename").(string)
+
+	if err := os.MkdirAll(filepath.Dir(filename), parseFileMode(d.Get("directory_permission").(string))); err != nil {
+		return fmt.Errorf("unable to create the directory of the file: %s, error: %s", filename, err)
+	}
+	mode := parseFileMode(d.Get("file_permission").(string))
+	release, err := lockResourceFile(d, filename, mode)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if d.Get("backup").(bool) {
+		if err := backupFile(d, filename, content); err != nil {
+			return err
+		}
+	}
+	// step: the permissions are only applied by the write when the file is created
+	if err := ioutil.WriteFile(filename, content, mode); err != nil {
+		return fmt.Errorf("unable to write the file: %s, error: %s", filename, err)
+	}
+	if err := os.Chmod(filename, mode); err != nil {
+		return fmt.Errorf("unable to set the permissions of the file: %s, error: %s", filename, err)
+	}
+	logEvent(logDebug, "file written", "filename", filename, "bytes", len(content))
+	d.SetId(filename)
+	d.Set("content_sha256", hash(rendered))
+	d.Set("input_sha256", inputs)
+	if err := resourceLocalFileRead(d, meta); err != nil {
+		return err
+	}
+
+	return scoped.redactError(runPostWriteCommand(d, meta, filename))
+}
+
+// encodeFile is responsible for encoding the content written to the file in the output_encoding,
+// prefixed by the byte order mark when requested
+func encodeFile(d *schema.ResourceData, rendered string) ([]byte, error) {
+	encoding := d.Get("output_encoding").(string)
+	content, err := encodeOutput(rendered, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the file, error: %s", err)
+	}
+	if d.Get("byte_order_mark").(bool) {
+		bom, err := byteOrderMark(encoding)
+		if err != nil {
+			return nil, err
+		}
+		content = append(bom, content...)
+	}
+
+	return content, nil
+}
+
+// backupFile is responsible for copying the current content of the file to the backup, unless the
+// file does not exist, is empty, as when just created by the lock, or already holds the content
+func backupFile(d *schema.ResourceData, filename string, content []byte) error {
+	current, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) || (err == nil && (len(current) == 0 || bytes.Equal(current, content))) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the file: %s for backup, error: %s", filename, err)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("unable to stat the file: %s, error: %s", filename, err)
+	}
+	backup := filename
+	if d.Get("backup_timestamp").(bool) {
+		backup += "." + time.Now().UTC().Format(backupTimeFormat)
+	}
+	backup += d.Get("backup_suffix").(string)
+	if err := ioutil.WriteFile(backup, current, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("unable to write the backup: %s, error: %s", backup, err)
+	}
+	logEvent(logDebug, "file backed up", "filename", filename, "backup", backup)
+	d.Set("backup_file", backup)
+
+	return nil
+}
+
+// resourceLocalFileRead is responsible for reading the content and permissions of the file, the
+// content being decoded from the output_encoding; any divergence from what was written is planned
+// as an update by the diff
+func resourceLocalFileRead(d *schema.ResourceData, meta interface{}) error {
+	info, err := os.Stat(d.Id())
+	if os.IsNotExist(err) {
+		logEvent(logWarn, "file no longer exists, removing from state", "filename", d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to stat the file: %s, error: %s", d.Id(), err)
+	}
+	content, err := ioutil.ReadFile(d.Id())
+	if err != nil {
+		return fmt.Errorf("unable to read the file: %s, error: %s", d.Id(), err)
+	}
+	// step: content which no longer decodes is kept as is, so is planned as drift
+	rendered, err := decodeOutput(content, d.Get("output_encoding").(string), d.Get("byte_order_mark").(bool))
+	if err != nil {
+		rendered = string(content)
+	}
+	d.Set("filename", d.Id())
+	d.Set("rendered", rendered)
+	d.Set("file_permission", fmt.Sprintf("%04o", info.Mode().Perm()))
+
+	return nil
+}
+
+// resourceLocalFileImport is responsible for adopting an existing file, the id being the filename
+// as configured; the content is recorded as written by terraform, so the first apply updates the
+// file in place with a diff of the render rather than replacing it
+func resourceLocalFileImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	filename := d.Id()
+	if err := resourceLocalFileRead(d, meta); err != nil {
+		return nil, err
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("the file: %s does not exist", filename)
+	}
+	d.Set("content_sha256", hash(d.Get("rendered").(string)))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceLocalFileDelete is responsible for removing the file
+func resourceLocalFileDelete(d *schema.ResourceData, meta interface{}) error {
+	if err := os.Remove(d.Id()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to delete the file: %s, error: %s", d.Id(), err)
+	}
+	d.SetId("")
+
+	return nil
+}
+
+// writtenForTarget is the transformation of a write converting the line endings for the target_os
+func writtenForTarget(d attributeGetter, rendered string) string {
+	return convertLineEndings(rendered, d.Get("target_os").(string))
+}
+
+// validateFileMode checks the value is octal file permissions
+func validateFileMode(v interface{}, k string) ([]string, []error) {
+	if mode, err := strconv.ParseUint(v.(string), 8, 32); err != nil || mode > 0777 {
+		return nil, []error{fmt.Errorf("%s: expected octal file permissions, got: %s", k, v)}
+	}
+
+	return nil, nil
+}
+
+// suppressFileModeDiff ignores a difference in the way the same file permissions are written,
+// i.e. 644 and 0644
+func suppressFileModeDiff(k, old, new string, d *schema.ResourceData) bool {
+	return parseFileMode(old) == parseFileMode(new)
+}
+
+// parseFileMode returns the octal permissions, having been validated by the schema
+func parseFileMode(value string) os.FileMode {
+	mode, _ := strconv.ParseUint(value, 8, 32)
+
+	return os.FileMode(mode)
+}
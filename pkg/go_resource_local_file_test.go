@@ -0,0 +1,440 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestGoTemplateLocalFileDrift(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "conf", "app.conf")
+	template := filepath.Join(dir, "app.tmpl")
+	if err := ioutil.WriteFile(template, []byte("name: {{ .name }}"), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	config := fmt.Sprintf(`
+		resource "gotemplate_local_file" "test" {
+			filename = "%s"
+			template = "%s"
+			vars {
+				name = "web"
+			}
+		}`, filename, template)
+	checkFile := func(expected string, mode os.FileMode) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			content, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return err
+			}
+			if string(content) != expected {
+				return fmt.Errorf("file holds: %q, want: %q", content, expected)
+			}
+			info, err := os.Stat(filename)
+			if err != nil {
+				return err
+			}
+			if info.Mode().Perm() != mode {
+				return fmt.Errorf("file mode: %o, want: %o", info.Mode().Perm(), mode)
+			}
+			return nil
+		}
+	}
+	write := func(path, content string) func() {
+		return func() {
+			if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := os.Stat(filename); !os.IsNotExist(err) {
+				return fmt.Errorf("expected the file to be deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "rendered", "name: web"),
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "content_sha256", hash("name: web")),
+					checkFile("name: web", 0644),
+				),
+			},
+			{
+				PreConfig:          write(filename, "edited"),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "content_diff", diffOf(filename, "edited", "name: web")),
+					checkFile("name: web", 0644),
+				),
+			},
+			{
+				PreConfig: func() { os.Chmod(filename, 0600) },
+				Config:    config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "file_permission", "0644"),
+					checkFile("name: web", 0644),
+				),
+			},
+			{
+				PreConfig: write(template, "app: {{ .name }}"),
+				Config:    config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "rendered", "app: web"),
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "content_diff", diffOf(filename, "name: web", "app: web")),
+					checkFile("app: web", 0644),
+				),
+			},
+			{
+				PreConfig: func() { os.Remove(filename) },
+				Config:    config,
+				Check:     checkFile("app: web", 0644),
+			},
+		},
+	})
+}
+
+func TestGoTemplateLocalFileBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.conf")
+
+	config := func(name string, timestamp bool) string {
+		return fmt.Sprintf(`
+			resource "gotemplate_local_file" "test" {
+				filename         = "%s"
+				template         = "name: {{ .name }}"
+				backup           = true
+				backup_timestamp = %t
+				lock             = true
+				vars {
+					name = "%s"
+				}
+			}`, filename, timestamp, name)
+	}
+	checkBackups := func(expected ...string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			backups, err := filepath.Glob(filename + "*.bak")
+			if err != nil {
+				return err
+			}
+			if len(backups) != len(expected) {
+				return fmt.Errorf("expected %d backups, got: %v", len(expected), backups)
+			}
+			for i, x := range backups {
+				content, err := ioutil.ReadFile(x)
+				if err != nil {
+					return err
+				}
+				if string(content) != expected[i] {
+					return fmt.Errorf("backup %s holds: %q, want: %q", x, content, expected[i])
+				}
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("web", false),
+				Check:  checkBackups(),
+			},
+			{
+				Config: config("api", false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "backup_file", filename+".bak"),
+					checkBackups("name: web"),
+				),
+			},
+			{
+				Config: config("db", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("gotemplate_local_file.test", "backup_file", regexp.MustCompile(`app\.conf\.\d{8}T\d{6}\.\d{3}Z\.bak$`)),
+					checkBackups("name: api", "name: web"),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateLocalFileTargetOS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.bat")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "gotemplate_local_file" "test" {
+						filename  = "%s"
+						template  = "echo {{ .name }}\n{{ .name }}.exe\n"
+						target_os = "windows"
+						vars {
+							name = "app"
+						}
+					}`, filename),
+				Check: func(*terraform.State) error {
+					content, err := ioutil.ReadFile(filename)
+					if err != nil {
+						return err
+					}
+					if string(content) != "echo app\r\napp.exe\r\n" {
+						return fmt.Errorf("file holds: %q, want windows line endings", content)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestGoTemplateLocalFileEncoding(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.ini")
+	config := fmt.Sprintf(`
+		resource "gotemplate_local_file" "test" {
+			filename        = "%s"
+			template        = "name=h\u00e9"
+			file_permission = "600"
+			output_encoding = "utf-16le"
+			byte_order_mark = true
+		}`, filename)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "rendered", "name=hé"),
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "file_permission", "0600"),
+					func(*terraform.State) error {
+						content, err := ioutil.ReadFile(filename)
+						if err != nil {
+							return err
+						}
+						expected := []byte{0xff, 0xfe, 'n', 0, 'a', 0, 'm', 0, 'e', 0, '=', 0, 'h', 0, 0xe9, 0}
+						if string(content) != string(expected) {
+							return fmt.Errorf("file holds: %v, want: %v", content, expected)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// the permissions and content read back match the config, so nothing is planned
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestGoTemplateLocalFileLeadingBOM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.ini")
+	config := fmt.Sprintf(`
+		resource "gotemplate_local_file" "test" {
+			filename = "%s"
+			template = "\ufeffname=web"
+		}`, filename)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("gotemplate_local_file.test", "rendered", "\ufeffname=web"),
+			},
+			{
+				// the rendered U+FEFF is read back as content rather than a byte order mark
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestGoTemplateLocalFileImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.conf")
+	if err := ioutil.WriteFile(filename, []byte("name: old"), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	config := fmt.Sprintf(`
+		resource "gotemplate_local_file" "test" {
+			filename = "%s"
+			template = "name: web"
+		}`, filename)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:        config,
+				ResourceName:  "gotemplate_local_file.test",
+				ImportState:   true,
+				ImportStateId: filepath.Join(dir, "missing.conf"),
+				ExpectError:   regexp.MustCompile("does not exist"),
+			},
+			{
+				Config:        config,
+				ResourceName:  "gotemplate_local_file.test",
+				ImportState:   true,
+				ImportStateId: filename,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected a single state, got: %d", len(states))
+					}
+					expected := map[string]string{
+						"filename":        filename,
+						"rendered":        "name: old",
+						"file_permission": "0600",
+						"content_sha256":  hash("name: old"),
+					}
+					for k, v := range expected {
+						if states[0].Attributes[k] != v {
+							return fmt.Errorf("attribute %s, got: %q, want: %q", k, states[0].Attributes[k], v)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// diffOf returns the unified diff of the single line contents
+func diffOf(filename, from, to string) string {
+	return fmt.Sprintf("--- %[1]s\n+++ %[1]s\n@@ -1,1 +1,1 @@\n-%[2]s\n\\ No newline at end of file\n+%[3]s\n\\ No newline at end of file\n", filename, from, to)
+}
+
+func TestValidateFileMode(t *testing.T) {
+	for _, x := range []string{"0644", "600", "0755"} {
+		if _, errs := validateFileMode(x, "mode"); len(errs) > 0 {
+			t.Errorf("unexpected errors for %s: %v", x, errs)
+		}
+	}
+	for _, x := range []string{"", "rw", "0999", "01777"} {
+		if _, errs := validateFileMode(x, "mode"); len(errs) == 0 {
+			t.Errorf("expected an error for %q", x)
+		}
+	}
+}
+
+func TestGoTemplateLocalFileUnknownVars(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	source, filename := filepath.Join(dir, "source.txt"), filepath.Join(dir, "app.conf")
+
+	config := func(content string) string {
+		return fmt.Sprintf(`
+			resource "gotemplate_local_file" "source" {
+				filename = "%s"
+				template = "%s"
+			}
+			resource "gotemplate_local_file" "test" {
+				filename = "%s"
+				template = "checksum: {{ .checksum }}"
+				vars {
+					checksum = "${gotemplate_local_file.source.content_sha256}"
+				}
+			}
+			resource "gotemplate_file" "test" {
+				template = "{{ .checksum }}"
+				vars {
+					checksum = "${gotemplate_local_file.source.content_sha256}"
+				}
+			}`, filepath.ToSlash(source), content, filepath.ToSlash(filename))
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("one"),
+				Check:  resource.TestCheckResourceAttr("gotemplate_local_file.test", "rendered", "checksum: "+hash("one")),
+			},
+			{
+				// the checksum of the source is unknown until its apply, deferring the render
+				Config: config("two"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "rendered", "checksum: "+hash("two")),
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "content_sha256", hash("checksum: "+hash("two"))),
+					resource.TestCheckResourceAttr("gotemplate_local_file.test", "content_diff", diffOf(filename, "checksum: "+hash("one"), "checksum: "+hash("two"))),
+					resource.TestCheckResourceAttr("gotemplate_file.test", "rendered", hash("two")),
+				),
+			},
+		},
+	})
+}
+
+func TestSuppressFileModeDiff(t *testing.T) {
+	if !suppressFileModeDiff("file_permission", "0600", "600", nil) {
+		t.Error("expected 0600 and 600 to be equivalent")
+	}
+	if suppressFileModeDiff("file_permission", "0644", "0600", nil) {
+		t.Error("expected 0644 and 0600 to differ")
+	}
+}
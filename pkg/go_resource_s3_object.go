@@ -0,0 +1,221 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func goResourceS3Object() *schema.Resource {
+	s := templateSchema()
+	s["bucket"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The name of the bucket the rendered template is uploaded to",
+	}
+	s["key"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The key of the object within the bucket",
+	}
+	s["content_type"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "The mime type of the object, i.e. application/json",
+	}
+	s["acl"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+		Default:  s3.ObjectCannedACLPrivate,
+		ValidateFunc: validation.StringInSlice([]string{
+			s3.ObjectCannedACLPrivate,
+			s3.ObjectCannedACLPublicRead,
+			s3.ObjectCannedACLPublicReadWrite,
+			s3.ObjectCannedACLAuthenticatedRead,
+			s3.ObjectCannedACLAwsExecRead,
+			s3.ObjectCannedACLBucketOwnerRead,
+			s3.ObjectCannedACLBucketOwnerFullControl,
+		}, false),
+		Description: "The canned acl applied to the object",
+	}
+	s["server_side_encryption"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringInSlice([]string{s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms}, false),
+		Description:  "The server side encryption of the object, either AES256 or aws:kms",
+	}
+	s["kms_key_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The kms key used to encrypt the object when server_side_encryption is aws:kms",
+	}
+	s["content_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The sha256 of the content as last uploaded by terraform, which unlike the body is held in the state",
+	}
+	s["input_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The hash of the inputs of the render as last uploaded",
+	}
+	s["etag"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The entity tag of the object",
+	}
+	s["upload_etag"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The entity tag of the object as last uploaded by terraform, differing from etag when the object was modified outside of terraform",
+	}
+	s["version_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The version of the object when the bucket is versioned",
+	}
+
+	return &schema.Resource{
+		Create: resourceS3ObjectWrite,
+		Read:   resourceS3ObjectRead,
+		Update: resourceS3ObjectWrite,
+		Delete: resourceS3ObjectDelete,
+		Schema: s,
+
+		CustomizeDiff: s3ObjectDiff(s),
+	}
+}
+
+// s3ObjectDiff returns the custom diff planning an update when the object was modified outside of
+// terraform or any of the files read by the render have changed; the body is not held in the state
+// so drift is detected from the entity tag
+func s3ObjectDiff(attributes map[string]*schema.Schema) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, meta interface{}) error {
+		if d.Id() == "" {
+			return nil
+		}
+		drifted := d.Get("etag").(string) != d.Get("upload_etag").(string)
+		_, err := updatePlanned(d, meta, attributes, drifted, "content_sha256", "input_sha256", "etag", "upload_etag", "version_id")
+
+		return err
+	}
+}
+
+// s3Of returns a s3 client from the provider configuration
+func s3Of(meta interface{}) (*s3.S3, error) {
+	settings := awsOf(meta)
+	session, err := settings.session()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the aws session, error: %s", err)
+	}
+	config := endpoint(settings.s3Endpoint)
+	config.S3ForcePathStyle = aws.Bool(settings.s3ForcePathStyle)
+
+	return s3.New(session, config), nil
+}
+
+// resourceS3ObjectWrite is responsible for rendering the template and uploading it to the object
+func resourceS3ObjectWrite(d *schema.ResourceData, meta interface{}) error {
+	inputs, err := inputHash(d, meta, goResourceS3Object().Schema)
+	if err != nil {
+		return fmt.Errorf("unable to hash the inputs, error: %s", err)
+	}
+	rendered, _, err := renderResource(d, meta)
+	if err != nil {
+		return err
+	}
+	client, err := s3Of(meta)
+	if err != nil {
+		return err
+	}
+	bucket, key := d.Get("bucket").(string), d.Get("key").(string)
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		ACL:    aws.String(d.Get("acl").(string)),
+		Body:   strings.NewReader(rendered),
+	}
+	if contentType := d.Get("content_type").(string); contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if encryption := d.Get("server_side_encryption").(string); encryption != "" {
+		input.ServerSideEncryption = aws.String(encryption)
+		if keyID := d.Get("kms_key_id").(string); keyID != "" && encryption == s3.ServerSideEncryptionAwsKms {
+			input.SSEKMSKeyId = aws.String(keyID)
+		}
+	}
+	output, err := client.PutObject(input)
+	if err != nil {
+		return fmt.Errorf("unable to upload the s3 object: s3://%s/%s, error: %s", bucket, key, err)
+	}
+	logEvent(logDebug, "s3 object uploaded", "bucket", bucket, "key", key, "bytes", len(rendered))
+	d.SetId(bucket + "/" + key)
+	d.Set("content_sha256", hash(rendered))
+	d.Set("input_sha256", inputs)
+	d.Set("upload_etag", strings.Trim(aws.StringValue(output.ETag), `"`))
+
+	return resourceS3ObjectRead(d, meta)
+}
+
+// resourceS3ObjectRead is responsible for reading the metadata of the object
+func resourceS3ObjectRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := s3Of(meta)
+	if err != nil {
+		return err
+	}
+	bucket, key := d.Get("bucket").(string), d.Get("key").(string)
+
+	output, err := client.HeadObject(&s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if isAWSError(err, "NotFound") {
+		logEvent(logWarn, "s3 object no longer exists, removing from state", "bucket", bucket, "key", key)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the s3 object: s3://%s/%s, error: %s", bucket, key, err)
+	}
+	d.Set("content_type", aws.StringValue(output.ContentType))
+	d.Set("etag", strings.Trim(aws.StringValue(output.ETag), `"`))
+	d.Set("version_id", aws.StringValue(output.VersionId))
+
+	return nil
+}
+
+// resourceS3ObjectDelete is responsible for deleting the object
+func resourceS3ObjectDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := s3Of(meta)
+	if err != nil {
+		return err
+	}
+	bucket, key := d.Get("bucket").(string), d.Get("key").(string)
+
+	if _, err := client.DeleteObject(&s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}); err != nil {
+		return fmt.Errorf("unable to delete the s3 object: s3://%s/%s, error: %s", bucket, key, err)
+	}
+	d.SetId("")
+
+	return nil
+}
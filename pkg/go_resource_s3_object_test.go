@@ -0,0 +1,179 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// fakeObject is an object held by the fake s3 api
+type fakeObject struct {
+	Body        string
+	ContentType string
+	ACL         string
+	Encryption  string
+	KMSKeyID    string
+}
+
+// fakeS3 is a minimal in memory implementation of the s3 object api, addressed by path
+type fakeS3 struct {
+	sync.Mutex
+	objects map[string]fakeObject
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	object, found := f.objects[r.URL.Path]
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		// step: default the content type as s3 does when none is given
+		contentType := r.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "binary/octet-stream"
+		}
+		f.objects[r.URL.Path] = fakeObject{
+			Body:        string(body),
+			ContentType: contentType,
+			ACL:         r.Header.Get("X-Amz-Acl"),
+			Encryption:  r.Header.Get("X-Amz-Server-Side-Encryption"),
+			KMSKeyID:    r.Header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"),
+		}
+		w.Header().Set("ETag", fmt.Sprintf(`"%s"`, etag(string(body))))
+	case http.MethodHead:
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.ContentType)
+		w.Header().Set("ETag", fmt.Sprintf(`"%s"`, etag(object.Body)))
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// object returns the object at the path, if any
+func (f *fakeS3) object(path string) (fakeObject, bool) {
+	f.Lock()
+	defer f.Unlock()
+	object, found := f.objects[path]
+
+	return object, found
+}
+
+// set replaces the body of the object at the path, as a change made outside of terraform
+func (f *fakeS3) set(path, body string) {
+	f.Lock()
+	defer f.Unlock()
+	object := f.objects[path]
+	object.Body = body
+	f.objects[path] = object
+}
+
+// etag returns the md5 entity tag of the content
+func etag(content string) string {
+	sum := md5.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// testS3 returns a fake s3 server and the provider block pointing at it
+func testS3(t *testing.T) (*fakeS3, *httptest.Server, string) {
+	s3 := &fakeS3{objects: make(map[string]fakeObject)}
+	server := httptest.NewServer(s3)
+
+	return s3, server, fmt.Sprintf(`
+		provider "gotemplate" {
+			aws {
+				region              = "eu-west-2"
+				access_key          = "access"
+				secret_key          = "secret"
+				s3_endpoint         = "%s"
+				s3_force_path_style = true
+			}
+		}`, server.URL)
+}
+
+func TestGoTemplateS3Object(t *testing.T) {
+	s3, server, provider := testS3(t)
+	defer server.Close()
+
+	config := func(name string) string {
+		return provider + fmt.Sprintf(`
+			resource "gotemplate_s3_object" "test" {
+				bucket                 = "configs"
+				key                    = "app/config.json"
+				content_type           = "application/json"
+				server_side_encryption = "aws:kms"
+				kms_key_id             = "alias/app"
+				template               = "{\"name\": \"{{ .name }}\"}"
+				vars {
+					name = "%s"
+				}
+			}`, name)
+	}
+	checkObject := func(body string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			expected := fakeObject{Body: body, ContentType: "application/json", ACL: "private", Encryption: "aws:kms", KMSKeyID: "alias/app"}
+			if object, _ := s3.object("/configs/app/config.json"); object != expected {
+				return fmt.Errorf("s3 object is: %+v, want: %+v", object, expected)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(*terraform.State) error {
+			if _, found := s3.object("/configs/app/config.json"); found {
+				return fmt.Errorf("expected the s3 object to be deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("web"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_s3_object.test", "id", "configs/app/config.json"),
+					resource.TestCheckResourceAttr("gotemplate_s3_object.test", "content_sha256", hash(`{"name": "web"}`)),
+					resource.TestCheckResourceAttr("gotemplate_s3_object.test", "etag", etag(`{"name": "web"}`)),
+					resource.TestCheckResourceAttr("gotemplate_s3_object.test", "upload_etag", etag(`{"name": "web"}`)),
+					resource.TestCheckNoResourceAttr("gotemplate_s3_object.test", "rendered"),
+					checkObject(`{"name": "web"}`),
+				),
+			},
+			{
+				Config: config("api"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_s3_object.test", "content_sha256", hash(`{"name": "api"}`)),
+					checkObject(`{"name": "api"}`),
+				),
+			},
+		},
+	})
+}
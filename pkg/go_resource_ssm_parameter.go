@@ -0,0 +1,209 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func goResourceSSMParameter() *schema.Resource {
+	s := templateSchema()
+	s["name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The name of the parameter the rendered template is written to, i.e. /app/config",
+	}
+	s["type"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      ssm.ParameterTypeString,
+		ValidateFunc: validation.StringInSlice([]string{ssm.ParameterTypeString, ssm.ParameterTypeSecureString}, false),
+		Description:  "The type of the parameter, either String or SecureString",
+	}
+	s["key_id"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The kms key used to encrypt a SecureString, defaulting to the account key",
+	}
+	s["tier"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "Standard",
+		ValidateFunc: validation.StringInSlice([]string{"Standard", "Advanced", "Intelligent-Tiering"}, false),
+		Description:  "The tier of the parameter, Advanced being required for content over 4KB",
+	}
+	s["description"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The description of the parameter",
+	}
+	s["overwrite"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Permit the create to overwrite a parameter which already exists",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Sensitive:   true,
+		Description: "The value of the parameter as last read",
+	}
+	s["version"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "The version of the parameter as last written or read",
+	}
+	s["content_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The sha256 of the value as last written by terraform",
+	}
+	s["content_diff"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Sensitive:   true,
+		Description: "A unified diff of the value planned by the last change, redacted and truncated, or a note while the inputs are not known until apply",
+	}
+	s["input_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The hash of the inputs of the render as last written",
+	}
+
+	return &schema.Resource{
+		Create:        resourceSSMParameterWrite,
+		Read:          resourceSSMParameterRead,
+		Update:        resourceSSMParameterWrite,
+		Delete:        resourceSSMParameterDelete,
+		CustomizeDiff: renderedDiff(s, writtenAsIs),
+		Schema:        s,
+	}
+}
+
+// ssmOf returns a ssm client from the provider configuration
+func ssmOf(meta interface{}) (*ssm.SSM, error) {
+	settings := awsOf(meta)
+	session, err := settings.session()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the aws session, error: %s", err)
+	}
+
+	return ssm.New(session, endpoint(settings.ssmEndpoint)), nil
+}
+
+// resourceSSMParameterWrite is responsible for rendering the template and writing it to the parameter
+func resourceSSMParameterWrite(d *schema.ResourceData, meta interface{}) error {
+	inputs, err := inputHash(d, meta, goResourceSSMParameter().Schema)
+	if err != nil {
+		return fmt.Errorf("unable to hash the inputs, error: %s", err)
+	}
+	rendered, _, err := renderResource(d, meta)
+	if err != nil {
+		return err
+	}
+	if rendered == "" {
+		return fmt.Errorf("the rendered template is empty, ssm parameters cannot be empty")
+	}
+	client, err := ssmOf(meta)
+	if err != nil {
+		return err
+	}
+	name := d.Get("name").(string)
+
+	input := &ssm.PutParameterInput{
+		Name:      aws.String(name),
+		Type:      aws.String(d.Get("type").(string)),
+		Value:     aws.String(rendered),
+		Overwrite: aws.Bool(d.Id() != "" || d.Get("overwrite").(bool)),
+	}
+	if description := d.Get("description").(string); description != "" {
+		input.Description = aws.String(description)
+	}
+	if keyID := d.Get("key_id").(string); keyID != "" && d.Get("type").(string) == ssm.ParameterTypeSecureString {
+		input.KeyId = aws.String(keyID)
+	}
+	if tier := d.Get("tier").(string); tier != "" {
+		input.Tier = aws.String(tier)
+	}
+	output, err := client.PutParameter(input)
+	if err != nil {
+		return fmt.Errorf("unable to write the ssm parameter: %s, error: %s", name, err)
+	}
+	logEvent(logDebug, "ssm parameter written", "name", name, "version", aws.Int64Value(output.Version), "bytes", len(rendered))
+	d.SetId(name)
+	d.Set("content_sha256", hash(rendered))
+	d.Set("input_sha256", inputs)
+
+	return resourceSSMParameterRead(d, meta)
+}
+
+// resourceSSMParameterRead is responsible for reading the value and version of the parameter, any
+// divergence from what was written being planned as an update by the diff
+func resourceSSMParameterRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := ssmOf(meta)
+	if err != nil {
+		return err
+	}
+	output, err := client.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(d.Id()),
+		WithDecryption: aws.Bool(true),
+	})
+	if isAWSError(err, ssm.ErrCodeParameterNotFound) {
+		logEvent(logWarn, "ssm parameter no longer exists, removing from state", "name", d.Id())
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the ssm parameter: %s, error: %s", d.Id(), err)
+	}
+	d.Set("name", d.Id())
+	d.Set("type", aws.StringValue(output.Parameter.Type))
+	d.Set("rendered", aws.StringValue(output.Parameter.Value))
+	d.Set("version", int(aws.Int64Value(output.Parameter.Version)))
+
+	return nil
+}
+
+// resourceSSMParameterDelete is responsible for deleting the parameter
+func resourceSSMParameterDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := ssmOf(meta)
+	if err != nil {
+		return err
+	}
+	_, err = client.DeleteParameter(&ssm.DeleteParameterInput{Name: aws.String(d.Id())})
+	if err != nil && !isAWSError(err, ssm.ErrCodeParameterNotFound) {
+		return fmt.Errorf("unable to delete the ssm parameter: %s, error: %s", d.Id(), err)
+	}
+	d.SetId("")
+
+	return nil
+}
+
+// isAWSError checks if the error is an aws error with the code
+func isAWSError(err error, code string) bool {
+	if e, ok := err.(awserr.Error); ok {
+		return e.Code() == code
+	}
+
+	return false
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// fakeParameter is a parameter held by the fake ssm api
+type fakeParameter struct {
+	Name    string
+	Type    string
+	Value   string
+	KeyID   string
+	Tier    string
+	Version int
+}
+
+// fakeSSM is a minimal in memory implementation of the ssm parameter api
+type fakeSSM struct {
+	sync.Mutex
+	parameters map[string]*fakeParameter
+}
+
+func (f *fakeSSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	var input struct {
+		Name      string
+		Type      string
+		Value     string
+		KeyId     string
+		Tier      string
+		Overwrite bool
+	}
+	json.NewDecoder(r.Body).Decode(&input)
+	failure := func(code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"__type": "%s", "message": "%s"}`, code, input.Name)
+	}
+
+	parameter, found := f.parameters[input.Name]
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AmazonSSM.") {
+	case "PutParameter":
+		if found && !input.Overwrite {
+			failure("ParameterAlreadyExists")
+			return
+		}
+		version := 1
+		if found {
+			version = parameter.Version + 1
+		}
+		f.parameters[input.Name] = &fakeParameter{Name: input.Name, Type: input.Type, Value: input.Value, KeyID: input.KeyId, Tier: input.Tier, Version: version}
+		fmt.Fprintf(w, `{"Version": %d}`, version)
+	case "GetParameter":
+		if !found {
+			failure("ParameterNotFound")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Parameter": parameter})
+	case "DeleteParameter":
+		if !found {
+			failure("ParameterNotFound")
+			return
+		}
+		delete(f.parameters, input.Name)
+		fmt.Fprint(w, `{}`)
+	}
+}
+
+// parameter returns a copy of the parameter, if any
+func (f *fakeSSM) parameter(name string) (fakeParameter, bool) {
+	f.Lock()
+	defer f.Unlock()
+	if parameter, found := f.parameters[name]; found {
+		return *parameter, true
+	}
+
+	return fakeParameter{}, false
+}
+
+// set writes the value of the parameter as a change made outside of terraform would
+func (f *fakeSSM) set(name, value string) {
+	f.Lock()
+	defer f.Unlock()
+	parameter := f.parameters[name]
+	parameter.Value = value
+	parameter.Version++
+}
+
+// testSSM returns a fake ssm server and the provider block pointing at it
+func testSSM(t *testing.T) (*fakeSSM, *httptest.Server, string) {
+	ssm := &fakeSSM{parameters: make(map[string]*fakeParameter)}
+	server := httptest.NewServer(ssm)
+
+	return ssm, server, fmt.Sprintf(`
+		provider "gotemplate" {
+			aws {
+				region       = "eu-west-2"
+				access_key   = "access"
+				secret_key   = "secret"
+				ssm_endpoint = "%s"
+			}
+		}`, server.URL)
+}
+
+func TestGoTemplateSSMParameter(t *testing.T) {
+	ssm, server, provider := testSSM(t)
+	defer server.Close()
+
+	config := func(name string) string {
+		return provider + fmt.Sprintf(`
+			resource "gotemplate_ssm_parameter" "test" {
+				name     = "/app/config"
+				type     = "SecureString"
+				key_id   = "alias/app"
+				tier     = "Advanced"
+				template = "name: {{ .name }}"
+				vars {
+					name = "%s"
+				}
+			}`, name)
+	}
+	checkParameter := func(expected fakeParameter) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if parameter, _ := ssm.parameter("/app/config"); parameter != expected {
+				return fmt.Errorf("ssm parameter is: %+v, want: %+v", parameter, expected)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(*terraform.State) error {
+			if _, found := ssm.parameter("/app/config"); found {
+				return fmt.Errorf("expected the ssm parameter to be deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("web"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_ssm_parameter.test", "rendered", "name: web"),
+					resource.TestCheckResourceAttr("gotemplate_ssm_parameter.test", "version", "1"),
+					checkParameter(fakeParameter{Name: "/app/config", Type: "SecureString", Value: "name: web", KeyID: "alias/app", Tier: "Advanced", Version: 1}),
+				),
+			},
+			{
+				Config: config("api"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_ssm_parameter.test", "rendered", "name: api"),
+					resource.TestCheckResourceAttr("gotemplate_ssm_parameter.test", "version", "2"),
+					checkParameter(fakeParameter{Name: "/app/config", Type: "SecureString", Value: "name: api", KeyID: "alias/app", Tier: "Advanced", Version: 2}),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateSSMParameterExists(t *testing.T) {
+	ssm, server, provider := testSSM(t)
+	defer server.Close()
+	ssm.parameters["/app/taken"] = &fakeParameter{Name: "/app/taken", Type: "String", Value: "manual", Version: 1}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + `
+					resource "gotemplate_ssm_parameter" "test" {
+						name     = "/app/taken"
+						template = "generated"
+					}`,
+				ExpectError: regexp.MustCompile("ParameterAlreadyExists"),
+			},
+			{
+				Config: provider + `
+					resource "gotemplate_ssm_parameter" "test" {
+						name     = "/app/empty"
+						template = ""
+					}`,
+				ExpectError: regexp.MustCompile("ssm parameters cannot be empty"),
+			},
+		},
+	})
+}
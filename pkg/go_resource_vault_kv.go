@@ -0,0 +1,240 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+const (
+	// vaultFormatRaw stores the rendered document as a single field of the secret
+	vaultFormatRaw = "raw"
+	// vaultFormatMap decodes the rendered yaml or json document into the fields of the secret
+	vaultFormatMap = "map"
+)
+
+func goResourceVaultKV() *schema.Resource {
+	s := templateSchema()
+	s["mount"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+		Default:     "secret",
+		Description: "The path the kv version 2 secrets engine is mounted at",
+	}
+	s["path"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The path of the secret within the mount",
+	}
+	s["format"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      vaultFormatRaw,
+		ValidateFunc: validation.StringInSlice([]string{vaultFormatRaw, vaultFormatMap}, false),
+		Description:  "Store the rendered document in a single field (raw) or decode the yaml or json into the fields (map)",
+	}
+	s["field"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "content",
+		Description: "The field of the secret holding the rendered document in the raw format",
+	}
+	s["cas"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Use check-and-set writes, failing rather than overwriting a secret created or modified outside of terraform",
+	}
+	s["delete_all_versions"] = &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Destroy every version and the metadata of the secret on delete, rather than soft deleting the latest",
+	}
+	s["rendered"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Sensitive:   true,
+		Description: "The rendered document as last written",
+	}
+	s["version"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "The version of the secret as last written by terraform, the version a check-and-set write expects",
+	}
+	s["content_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The sha256 of the rendered document as last written by terraform",
+	}
+	s["content_diff"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Sensitive:   true,
+		Description: "A unified diff of the document planned by the last change, redacted and truncated, or a note while the inputs are not known until apply",
+	}
+	s["input_sha256"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The hash of the inputs of the render as last written",
+	}
+
+	return &schema.Resource{
+		Create:        resourceVaultKVWrite,
+		Read:          resourceVaultKVRead,
+		Update:        resourceVaultKVWrite,
+		Delete:        resourceVaultKVDelete,
+		CustomizeDiff: renderedDiff(s, writtenAsIs),
+		Schema:        s,
+	}
+}
+
+// vaultSecretPath returns the api path of the secret under the kv version 2 prefix
+func vaultSecretPath(d *schema.ResourceData, prefix string) string {
+	return strings.Trim(d.Get("mount").(string), "/") + "/" + prefix + "/" + strings.Trim(d.Get("path").(string), "/")
+}
+
+// resourceVaultKVWrite is responsible for rendering the template and writing it to the secret
+func resourceVaultKVWrite(d *schema.ResourceData, meta interface{}) error {
+	inputs, err := inputHash(d, meta, goResourceVaultKV().Schema)
+	if err != nil {
+		return fmt.Errorf("unable to hash the inputs, error: %s", err)
+	}
+	rendered, _, err := renderResource(d, meta)
+	if err != nil {
+		return err
+	}
+	client, err := vaultOf(meta)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{d.Get("field").(string): rendered}
+	if d.Get("format").(string) == vaultFormatMap {
+		if data, err = values.Parse(rendered); err != nil {
+			return fmt.Errorf("unable to decode the rendered document, error: %s", err)
+		}
+	}
+	input := map[string]interface{}{"data": data}
+	if d.Get("cas").(bool) {
+		// a cas of zero only permits the write when the secret does not exist
+		cas := 0
+		if d.Id() != "" {
+			cas = d.Get("version").(int)
+		}
+		input["options"] = map[string]interface{}{"cas": cas}
+	}
+
+	path := vaultSecretPath(d, "data")
+	var output struct {
+		Data struct {
+			Version int `json:"version"`
+		} `json:"data"`
+	}
+	if err := client.do("POST", path, input, &output); err != nil {
+		return fmt.Errorf("unable to write the vault secret: %s, error: %s", path, err)
+	}
+	logEvent(logDebug, "vault secret written", "path", path, "version", output.Data.Version, "bytes", len(rendered))
+	// step: the read does not refresh the version, so a change made outside of terraform fails
+	// the next check-and-set write
+	d.SetId(path)
+	d.Set("rendered", rendered)
+	d.Set("version", output.Data.Version)
+	d.Set("content_sha256", hash(rendered))
+	d.Set("input_sha256", inputs)
+
+	return resourceVaultKVRead(d, meta)
+}
+
+// resourceVaultKVRead is responsible for reading the content of the secret, any divergence from
+// what was written being planned as an update by the diff
+func resourceVaultKVRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := vaultOf(meta)
+	if err != nil {
+		return err
+	}
+	path := vaultSecretPath(d, "data")
+
+	var output struct {
+		Data struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	err = client.do("GET", path, nil, &output)
+	if err == errVaultNotFound {
+		logEvent(logWarn, "vault secret no longer exists, removing from state", "path", path)
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the vault secret: %s, error: %s", path, err)
+	}
+	// step: in the raw format the document is read back; in the map format the fields are compared
+	// with those decoded from the document written, a difference leaving the fields as json
+	field, _ := output.Data.Data[d.Get("field").(string)].(string)
+	if d.Get("format").(string) == vaultFormatMap {
+		if field, err = vaultMapDrift(d.Get("rendered").(string), output.Data.Data); err != nil {
+			return err
+		}
+	}
+	d.Set("rendered", field)
+
+	return nil
+}
+
+// vaultMapDrift returns the document when the fields of the secret match those decoded from it,
+// otherwise the json encoding of the fields
+func vaultMapDrift(rendered string, fields map[string]interface{}) (string, error) {
+	remote, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode the vault secret, error: %s", err)
+	}
+	if decoded, err := values.Parse(rendered); err == nil {
+		if written, err := json.Marshal(decoded); err == nil && string(written) == string(remote) {
+			return rendered, nil
+		}
+	}
+
+	return string(remote), nil
+}
+
+// resourceVaultKVDelete is responsible for deleting the secret
+func resourceVaultKVDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := vaultOf(meta)
+	if err != nil {
+		return err
+	}
+	path := vaultSecretPath(d, "data")
+	if d.Get("delete_all_versions").(bool) {
+		path = vaultSecretPath(d, "metadata")
+	}
+	if err := client.do("DELETE", path, nil, nil); err != nil && err != errVaultNotFound {
+		return fmt.Errorf("unable to delete the vault secret: %s, error: %s", path, err)
+	}
+	d.SetId("")
+
+	return nil
+}
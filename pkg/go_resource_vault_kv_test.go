@@ -0,0 +1,293 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// fakeSecret is a secret held by the fake vault server
+type fakeSecret struct {
+	data    map[string]interface{}
+	version int
+	deleted bool
+}
+
+// fakeVault is a minimal in memory implementation of the vault kv version 2 api
+type fakeVault struct {
+	sync.Mutex
+	secrets map[string]*fakeSecret
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	failure := func(code int, message string) {
+		w.WriteHeader(code)
+		fmt.Fprintf(w, `{"errors": ["%s"]}`, message)
+	}
+	if r.Header.Get("X-Vault-Token") != "root" {
+		failure(http.StatusForbidden, "permission denied")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	name := strings.Replace(strings.Replace(path, "/data/", "/", 1), "/metadata/", "/", 1)
+	secret, found := f.secrets[name]
+
+	switch {
+	case r.Method == http.MethodPost:
+		var input struct {
+			Data    map[string]interface{} `json:"data"`
+			Options map[string]int         `json:"options"`
+		}
+		json.NewDecoder(r.Body).Decode(&input)
+		current := 0
+		if found {
+			current = secret.version
+		}
+		if cas, set := input.Options["cas"]; set && cas != current {
+			failure(http.StatusBadRequest, "check-and-set parameter did not match the current version")
+			return
+		}
+		f.secrets[name] = &fakeSecret{data: input.Data, version: current + 1}
+		fmt.Fprintf(w, `{"data": {"version": %d}}`, current+1)
+	case r.Method == http.MethodGet:
+		if !found || secret.deleted {
+			failure(http.StatusNotFound, "")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": secret.data, "metadata": map[string]interface{}{"version": secret.version}},
+		})
+	case r.Method == http.MethodDelete && strings.Contains(path, "/metadata/"):
+		delete(f.secrets, name)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete && found:
+		secret.deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// secret returns the data of the secret, if any
+func (f *fakeVault) secret(name string) (map[string]interface{}, bool) {
+	f.Lock()
+	defer f.Unlock()
+	if secret, found := f.secrets[name]; found {
+		return secret.data, !secret.deleted
+	}
+
+	return nil, false
+}
+
+// set writes the secret as a change made outside of terraform would
+func (f *fakeVault) set(name string, data map[string]interface{}) {
+	f.Lock()
+	defer f.Unlock()
+	version := 0
+	if secret, found := f.secrets[name]; found {
+		version = secret.version
+	}
+	f.secrets[name] = &fakeSecret{data: data, version: version + 1}
+}
+
+// testVault returns a fake vault server and the provider block pointing at it
+func testVault(t *testing.T) (*fakeVault, *httptest.Server, string) {
+	vault := &fakeVault{secrets: make(map[string]*fakeSecret)}
+	server := httptest.NewServer(vault)
+
+	return vault, server, fmt.Sprintf(`
+		provider "gotemplate" {
+			vault {
+				address = "%s"
+				token   = "root"
+			}
+		}`, server.URL)
+}
+
+func TestGoTemplateVaultKV(t *testing.T) {
+	vault, server, provider := testVault(t)
+	defer server.Close()
+
+	config := func(name string) string {
+		return provider + fmt.Sprintf(`
+			resource "gotemplate_vault_kv" "test" {
+				path                = "app/config"
+				cas                 = true
+				delete_all_versions = true
+				template            = "name: {{ .name }}"
+				vars {
+					name = "%s"
+				}
+			}`, name)
+	}
+	checkSecret := func(expected map[string]interface{}) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if data, _ := vault.secret("secret/app/config"); !reflect.DeepEqual(data, expected) {
+				return fmt.Errorf("vault secret holds: %v, want: %v", data, expected)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(*terraform.State) error {
+			if _, found := vault.secrets["secret/app/config"]; found {
+				return fmt.Errorf("expected the vault secret to be destroyed")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("web"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_vault_kv.test", "id", "secret/data/app/config"),
+					resource.TestCheckResourceAttr("gotemplate_vault_kv.test", "rendered", "name: web"),
+					resource.TestCheckResourceAttr("gotemplate_vault_kv.test", "version", "1"),
+					checkSecret(map[string]interface{}{"content": "name: web"}),
+				),
+			},
+			{
+				Config: config("api"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_vault_kv.test", "version", "2"),
+					checkSecret(map[string]interface{}{"content": "name: api"}),
+				),
+			},
+		},
+	})
+}
+
+func TestGoTemplateVaultKVMap(t *testing.T) {
+	vault, server, provider := testVault(t)
+	defer server.Close()
+	vault.secrets["secret/app/taken"] = &fakeSecret{data: map[string]interface{}{"manual": "true"}, version: 1}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(*terraform.State) error {
+			if _, found := vault.secret("secret/app/map"); found {
+				return fmt.Errorf("expected the vault secret to be deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: provider + `
+					resource "gotemplate_vault_kv" "test" {
+						path     = "app/map"
+						format   = "map"
+						template = "{\"user\": \"{{ .user }}\", \"port\": 5432}"
+						vars {
+							user = "app"
+						}
+					}`,
+				Check: func(*terraform.State) error {
+					expected := map[string]interface{}{"user": "app", "port": float64(5432)}
+					if data, _ := vault.secret("secret/app/map"); !reflect.DeepEqual(data, expected) {
+						return fmt.Errorf("vault secret holds: %v, want: %v", data, expected)
+					}
+					return nil
+				},
+			},
+			{
+				Config: provider + `
+					resource "gotemplate_vault_kv" "test" {
+						path     = "app/taken"
+						cas      = true
+						template = "generated"
+					}`,
+				ExpectError: regexp.MustCompile("check-and-set parameter did not match the current version"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateVaultKVDrift(t *testing.T) {
+	vault, server, provider := testVault(t)
+	defer server.Close()
+	dir := testTemplateDir(t, map[string]string{"app.tmpl": "name: web"})
+	defer os.RemoveAll(dir)
+	template := filepath.Join(dir, "app.tmpl")
+
+	config := func(format string, cas bool) string {
+		return provider + fmt.Sprintf(`
+			resource "gotemplate_vault_kv" "test" {
+				path     = "app/config"
+				format   = "%s"
+				cas      = %t
+				template = "%s"
+			}`, format, cas, filepath.ToSlash(template))
+	}
+	checkSecret := func(expected map[string]interface{}) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if data, _ := vault.secret("secret/app/config"); !reflect.DeepEqual(data, expected) {
+				return fmt.Errorf("vault secret holds: %v, want: %v", data, expected)
+			}
+			return nil
+		}
+	}
+	manual := map[string]interface{}{"name": "manual"}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("map", false),
+				Check:  checkSecret(map[string]interface{}{"name": "web"}),
+			},
+			{
+				// the fields of the map format are compared with those written
+				PreConfig: func() { vault.set("secret/app/config", manual) },
+				Config:    config("map", false),
+				Check:     checkSecret(map[string]interface{}{"name": "web"}),
+			},
+			{
+				Config: config("map", true),
+				Check:  checkSecret(map[string]interface{}{"name": "web"}),
+			},
+			{
+				// with check-and-set the change is refused rather than overwritten
+				PreConfig:   func() { vault.set("secret/app/config", manual) },
+				Config:      config("map", true),
+				ExpectError: regexp.MustCompile("check-and-set parameter did not match the current version"),
+			},
+			{
+				PreConfig: func() {
+					if data, _ := vault.secret("secret/app/config"); !reflect.DeepEqual(data, manual) {
+						t.Errorf("expected the secret to be left untouched, got: %v", data)
+					}
+				},
+				Config: config("map", false),
+				Check:  checkSecret(map[string]interface{}{"name": "web"}),
+			},
+		},
+	})
+}
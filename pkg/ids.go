@@ -0,0 +1,57 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	// idSchemeOutputHash identifies the data source by the checksum of the rendered template
+	idSchemeOutputHash = "output-hash"
+	// idSchemeInputHash identifies the data source by the hash of its inputs, stable across
+	// renders of the same inputs whose output varies
+	idSchemeInputHash = "input-hash"
+	// idSchemeUUID identifies the data source by a random uuid, kept by the resource across reads;
+	// the arguments of the resource force a new one, so a change of them draws a new uuid
+	idSchemeUUID = "uuid"
+	// idSchemeStatic identifies the data source by a constant, never changing
+	idSchemeStatic = "static"
+)
+
+// idSchemes are the supported id schemes
+var idSchemes = []string{idSchemeOutputHash, idSchemeInputHash, idSchemeUUID, idSchemeStatic}
+
+// dataSourceID returns the id of the data source in its id_scheme, given the hash of its inputs;
+// a disabled template reads no inputs, so without them is identified by its checksum
+func dataSourceID(d *schema.ResourceData, inputs string) (string, error) {
+	switch d.Get("id_scheme").(string) {
+	case idSchemeInputHash:
+		if inputs != "" {
+			return inputs, nil
+		}
+	case idSchemeUUID:
+		if d.Id() != "" {
+			return d.Id(), nil
+		}
+		return uuid.GenerateUUID()
+	case idSchemeStatic:
+		return idSchemeStatic, nil
+	}
+
+	return d.Get("checksum").(string), nil
+}
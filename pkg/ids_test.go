@@ -0,0 +1,97 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestGoTemplateIDScheme(t *testing.T) {
+	config := func(scheme string) string {
+		return fmt.Sprintf(`
+			data "gotemplate_file" "test" {
+				template  = "hello {{ .name }}"
+				id_scheme = "%s"
+				vars {
+					name = "web"
+				}
+			}`, scheme)
+	}
+	checkInputHash := func(s *terraform.State) error {
+		attributes := s.RootModule().Resources["data.gotemplate_file.test"].Primary.Attributes
+		if attributes["input_sha256"] == "" || attributes["id"] != attributes["input_sha256"] {
+			return fmt.Errorf("expected the id to be the input hash, got: %s, input_sha256: %s", attributes["id"], attributes["input_sha256"])
+		}
+		return nil
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config(idSchemeOutputHash),
+				Check:  resource.TestCheckResourceAttr("data.gotemplate_file.test", "id", hash("hello web")),
+			},
+			{
+				Config: config(idSchemeInputHash),
+				Check:  checkInputHash,
+			},
+			{
+				Config: config(idSchemeStatic),
+				Check:  resource.TestCheckResourceAttr("data.gotemplate_file.test", "id", "static"),
+			},
+			{
+				Config: config(idSchemeUUID),
+				Check:  resource.TestMatchResourceAttr("data.gotemplate_file.test", "id", regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$")),
+			},
+		},
+	})
+}
+
+func TestGoTemplateIDSchemeUUIDKept(t *testing.T) {
+	var id string
+	config := func(name string) string {
+		return fmt.Sprintf(`
+			resource "gotemplate_file" "test" {
+				template  = "hello {{ .name }}"
+				id_scheme = "uuid"
+				vars {
+					name = "%s"
+				}
+			}`, name)
+	}
+	checkID := func(s *terraform.State) error {
+		current := s.RootModule().Resources["gotemplate_file.test"].Primary.ID
+		if id != "" && current != id {
+			return fmt.Errorf("expected the uuid to be kept, got: %s, want: %s", current, id)
+		}
+		id = current
+		return nil
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{Config: config("web"), Check: checkID},
+			{Config: config("web"), Check: checkID},
+		},
+	})
+}
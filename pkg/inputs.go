@@ -0,0 +1,134 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+// inputHash is responsible for the canonical hash of everything a render reads: the arguments,
+// the provider defaults, the template registered under any ref, and the contents of the template,
+// the vars, values and override files and every file of the snippet directories. The shared
+// library is loaded once by the provider so is not included
+func inputHash(d attributeGetter, meta interface{}, attributes map[string]*schema.Schema) (string, error) {
+	h := sha256.New()
+
+	// step: the arguments, json encoding the maps with their keys sorted; an optional argument which
+	// is also computed holds the remote value when not configured, so is left to the diff of the
+	// argument rather than seen as a change of the inputs
+	var names []string
+	for name, x := range attributes {
+		if (x.Optional || x.Required) && !x.Computed {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		encoded, err := json.Marshal(d.Get(name))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s=%s\n", name, encoded)
+	}
+	defaults := defaultsOf(meta)
+	fmt.Fprintf(h, "defaults=%+v\nworkspace=%s\n", defaults, os.Getenv("TF_WORKSPACE"))
+
+	// step: the contents read by the render
+	basePath := d.Get("base_path").(string)
+	content := ""
+	if ref := refOf(d); ref != "" {
+		registered, err := registryOf(meta).lookup(ref)
+		if err != nil {
+			return "", err
+		}
+		encoded, err := json.Marshal(registered.vars)
+		if err != nil {
+			return "", err
+		}
+		content = registered.content + string(encoded)
+	} else {
+		var err error
+		if content, err = readPathOrContents(basePath, d.Get("template").(string)); err != nil {
+			return "", err
+		}
+	}
+	fmt.Fprintf(h, "template=%s\n", hash(content))
+
+	files := toStrings(d.Get("override_files").([]interface{}))
+	files = append(files, toStrings(d.Get("vars_files").([]interface{}))...)
+	if filename := d.Get("values_file").(string); filename != "" {
+		files = append(files, filename)
+	}
+	for _, x := range files {
+		if err := hashFile(h, resolvePath(basePath, x)); err != nil {
+			return "", err
+		}
+	}
+
+	snippets := resolvePath(basePath, d.Get("snippets").(string))
+	if snippets == "" {
+		snippets = defaults.snippets
+	}
+	dirs := append([]string{snippets}, defaults.snippetPaths...)
+	for _, x := range toStrings(d.Get("snippet_paths").([]interface{})) {
+		dirs = append(dirs, resolvePath(basePath, x))
+	}
+	walk := render.WalkOptions{
+		FollowSymlinks: d.Get("follow_symlinks").(bool),
+		IncludeHidden:  d.Get("include_hidden").(bool),
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		err := render.WalkFiles(dir, walk, func(path, relative string) error {
+			fmt.Fprintf(h, "snippet=%s\n", relative)
+			return hashFile(h, path)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// attributeGetter is satisfied by both the resource data and the diff of a plan
+type attributeGetter interface {
+	Get(string) interface{}
+}
+
+// hashFile writes the path and the hash of the content of the file to the hash
+func hashFile(h io.Writer, path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "file=%s:%s\n", path, hash(string(content)))
+
+	return nil
+}
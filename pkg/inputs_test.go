@@ -0,0 +1,114 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestInputHash(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"snippets/name.tmpl": `{{ define "name" }}{{ .name }}{{ end }}`,
+		"vars.yaml":          "name: web\n",
+	})
+	defer os.RemoveAll(dir)
+
+	config := func(changes map[string]interface{}) map[string]interface{} {
+		raw := map[string]interface{}{
+			"base_path":  dir,
+			"template":   `{{ template "name" . }}`,
+			"snippets":   "snippets",
+			"vars_files": []interface{}{"vars.yaml"},
+		}
+		for k, v := range changes {
+			raw[k] = v
+		}
+		return raw
+	}
+	inputs := func(raw map[string]interface{}) string {
+		h, err := inputHash(schema.TestResourceDataRaw(t, goDataSourceFile().Schema, raw), nil, goDataSourceFile().Schema)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return h
+	}
+	original := inputs(config(nil))
+	if inputs(config(nil)) != original {
+		t.Error("expected the same inputs to hash the same")
+	}
+	if inputs(config(map[string]interface{}{"vars": map[string]interface{}{"extra": "1"}})) == original {
+		t.Error("expected a change of vars to change the hash")
+	}
+	if inputs(config(map[string]interface{}{"trim_blocks": true})) == original {
+		t.Error("expected a change of arguments to change the hash")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "vars.yaml"), []byte("name: db\n"), 0644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+	changed := inputs(config(nil))
+	if changed == original {
+		t.Error("expected a change of a vars file to change the hash")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "snippets", "extra.tmpl"), []byte("x"), 0644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+	if inputs(config(nil)) == changed {
+		t.Error("expected a new snippet to change the hash")
+	}
+}
+
+func TestSkipUnchanged(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{"template.tmpl": "{{ .name }}"})
+	defer os.RemoveAll(dir)
+
+	d := schema.TestResourceDataRaw(t, goDataSourceFile().Schema, map[string]interface{}{
+		"base_path":      dir,
+		"template":       "template.tmpl",
+		"vars":           map[string]interface{}{"name": "web"},
+		"skip_unchanged": true,
+	})
+	if err := dataSourceFileRead(d, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d.Get("rendered").(string) != "web" || d.Get("input_sha256").(string) == "" {
+		t.Fatalf("unexpected state, rendered: %q, input_sha256: %q", d.Get("rendered"), d.Get("input_sha256"))
+	}
+
+	// step: a read with unchanged inputs keeps the state as it was
+	d.Set("rendered", "previous")
+	if err := dataSourceFileRead(d, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d.Get("rendered").(string) != "previous" {
+		t.Errorf("expected the render to be skipped, got: %q", d.Get("rendered"))
+	}
+
+	// step: a change to the template file renders again
+	if err := ioutil.WriteFile(filepath.Join(dir, "template.tmpl"), []byte("{{ .name }}!"), 0644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+	if err := dataSourceFileRead(d, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d.Get("rendered").(string) != "web!" {
+		t.Errorf("expected the changed template rendered, got: %q", d.Get("rendered"))
+	}
+}
@@ -0,0 +1,399 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonpatch applies rfc 6902 json patches and rfc 7386 merge patches to json documents
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// operation is a single operation of a json patch
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Apply is responsible for applying the rfc 6902 json patch to the document; the operations are
+// applied in order and the first to fail aborts the patch
+func Apply(document, patch []byte) ([]byte, error) {
+	doc, err := decode(document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document, error: %s", err)
+	}
+	var operations []operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("invalid patch, expected a list of operations, error: %s", err)
+	}
+	for i, x := range operations {
+		if doc, err = x.apply(doc); err != nil {
+			return nil, fmt.Errorf("operation %d (%s), error: %s", i, x.Op, err)
+		}
+	}
+
+	return encode(doc)
+}
+
+// MergePatch is responsible for applying the rfc 7386 merge patch to the document: objects are
+// merged recursively, a null removes the member and any other value replaces it
+func MergePatch(document, patch []byte) ([]byte, error) {
+	doc, err := decode(document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document, error: %s", err)
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch, error: %s", err)
+	}
+
+	return encode(mergePatch(doc, p))
+}
+
+// mergePatch merges the patch into the target
+func mergePatch(target, patch interface{}) interface{} {
+	p, isMap := patch.(map[string]interface{})
+	if !isMap {
+		return patch
+	}
+	t, isMap := target.(map[string]interface{})
+	if !isMap {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+
+	return t
+}
+
+// apply is responsible for applying the operation to the document
+func (o operation) apply(doc interface{}) (interface{}, error) {
+	if o.Path == nil {
+		return nil, fmt.Errorf("the operation has no path")
+	}
+	path, err := parsePointer(*o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return nil, fmt.Errorf("the operation has no value")
+		}
+		value, err := decode(o.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value, error: %s", err)
+		}
+		switch o.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		}
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, fmt.Errorf("the value at %s does not match", *o.Path)
+		}
+		return doc, nil
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		if o.From == nil {
+			return nil, fmt.Errorf("the operation has no from")
+		}
+		from, err := parsePointer(*o.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if o.Op == "copy" {
+			return add(doc, path, deepCopy(value))
+		}
+		if strings.HasPrefix(*o.Path, *o.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into one of its children", *o.From)
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	}
+
+	return nil, fmt.Errorf("unsupported operation: %q", o.Op)
+}
+
+// parsePointer splits the rfc 6901 json pointer into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path: %q, must begin with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, x := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(x, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	return tokens, nil
+}
+
+// get returns the value at the path
+func get(doc interface{}, path []string) (interface{}, error) {
+	current := doc
+	for _, key := range path {
+		switch n := current.(type) {
+		case map[string]interface{}:
+			value, found := n[key]
+			if !found {
+				return nil, fmt.Errorf("path not found: /%s", strings.Join(path, "/"))
+			}
+			current = value
+		case []interface{}:
+			i, err := arrayIndex(key, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = n[i]
+		default:
+			return nil, fmt.Errorf("path not found: /%s", strings.Join(path, "/"))
+		}
+	}
+
+	return current, nil
+}
+
+// modify walks to the parent of the path and calls the function with it and the last token,
+// returning the updated document
+func modify(doc interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	switch n := doc.(type) {
+	case map[string]interface{}:
+		child, found := n[path[0]]
+		if !found {
+			return nil, fmt.Errorf("path not found: %s", path[0])
+		}
+		updated, err := modify(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = updated
+		return n, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := modify(n[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = updated
+		return n, nil
+	}
+
+	return nil, fmt.Errorf("path not found: %s", path[0])
+}
+
+// add inserts the value at the path, the last token of an array path may be - to append
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch n := parent.(type) {
+		case map[string]interface{}:
+			n[key] = value
+			return n, nil
+		case []interface{}:
+			i := len(n)
+			if key != "-" {
+				var err error
+				if i, err = arrayIndex(key, len(n)); err != nil {
+					return nil, err
+				}
+			}
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			return n, nil
+		}
+		return nil, fmt.Errorf("cannot add %s to a value which is neither an object or array", key)
+	})
+}
+
+// remove deletes the value at the path
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch n := parent.(type) {
+		case map[string]interface{}:
+			if _, found := n[key]; !found {
+				return nil, fmt.Errorf("path not found: %s", key)
+			}
+			delete(n, key)
+			return n, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(n[:i], n[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("path not found: %s", key)
+	})
+}
+
+// replace sets the value at the path, which must already exist
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if _, err := get(doc, path); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch n := parent.(type) {
+		case map[string]interface{}:
+			n[key] = value
+			return n, nil
+		case []interface{}:
+			i, _ := arrayIndex(key, len(n)-1)
+			n[i] = value
+			return n, nil
+		}
+		return nil, fmt.Errorf("path not found: %s", key)
+	})
+}
+
+// arrayIndex parses the token as an index no greater than max
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index: %q", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index out of bounds: %d", i)
+	}
+
+	return i, nil
+}
+
+// equal compares the values, numbers being compared by their value rather than representation
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			other, found := y[k]
+			if !found || !equal(v, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, okx := new(big.Float).SetString(string(x))
+		fy, oky := new(big.Float).SetString(string(y))
+		return okx && oky && fx.Cmp(fy) == 0
+	}
+
+	return a == b
+}
+
+// deepCopy returns a copy of the value sharing no maps or slices
+func deepCopy(value interface{}) interface{} {
+	switch x := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(x))
+		for k, v := range x {
+			copied[k] = deepCopy(v)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(x))
+		for i, v := range x {
+			copied[i] = deepCopy(v)
+		}
+		return copied
+	}
+
+	return value
+}
+
+// decode decodes the json, keeping numbers as written
+func decode(content []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// encode encodes the value as compact json without escaping html characters
+func encode(value interface{}) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonpatch
+
+import (
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		Document string
+		Patch    string
+		Expected string
+	}{
+		{Document: `{"a":1}`, Patch: `[{"op":"add","path":"/b","value":[1,2]}]`, Expected: `{"a":1,"b":[1,2]}`},
+		{Document: `{"a":[1,3]}`, Patch: `[{"op":"add","path":"/a/1","value":2}]`, Expected: `{"a":[1,2,3]}`},
+		{Document: `{"a":[1]}`, Patch: `[{"op":"add","path":"/a/-","value":2}]`, Expected: `{"a":[1,2]}`},
+		{Document: `{"a":1,"b":2}`, Patch: `[{"op":"remove","path":"/a"}]`, Expected: `{"b":2}`},
+		{Document: `{"a":[1,2,3]}`, Patch: `[{"op":"remove","path":"/a/1"}]`, Expected: `{"a":[1,3]}`},
+		{Document: `{"a":{"b":1}}`, Patch: `[{"op":"replace","path":"/a/b","value":"<x>"}]`, Expected: `{"a":{"b":"<x>"}}`},
+		{Document: `{"a":{"b":1}}`, Patch: `[{"op":"move","from":"/a/b","path":"/c"}]`, Expected: `{"a":{},"c":1}`},
+		{Document: `{"a":{"b":1}}`, Patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/d","value":2}]`, Expected: `{"a":{"b":1},"c":{"b":1,"d":2}}`},
+		{Document: `{"a":1.0}`, Patch: `[{"op":"test","path":"/a","value":1},{"op":"add","path":"/b","value":null}]`, Expected: `{"a":1.0,"b":null}`},
+		{Document: `{"a/b":{"~c":1}}`, Patch: `[{"op":"replace","path":"/a~1b/~0c","value":2}]`, Expected: `{"a/b":{"~c":2}}`},
+		{Document: `{"a":1}`, Patch: `[{"op":"replace","path":"","value":[1]}]`, Expected: `[1]`},
+		{Document: `{"big":12345678901234567890}`, Patch: `[]`, Expected: `{"big":12345678901234567890}`},
+	}
+	for i, x := range cases {
+		patched, err := Apply([]byte(x.Document), []byte(x.Patch))
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if string(patched) != x.Expected {
+			t.Errorf("case %d, got: %s, want: %s", i, patched, x.Expected)
+		}
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	cases := []struct {
+		Document string
+		Patch    string
+	}{
+		{Document: `{`, Patch: `[]`},
+		{Document: `{}`, Patch: `{}`},
+		{Document: `{}`, Patch: `[{"op":"remove","path":"/a"}]`},
+		{Document: `{}`, Patch: `[{"op":"replace","path":"/a","value":1}]`},
+		{Document: `{}`, Patch: `[{"op":"add","path":"/a/b","value":1}]`},
+		{Document: `{}`, Patch: `[{"op":"add","path":"/a"}]`},
+		{Document: `{}`, Patch: `[{"op":"add","value":1}]`},
+		{Document: `{}`, Patch: `[{"op":"add","path":"a","value":1}]`},
+		{Document: `{"a":[1]}`, Patch: `[{"op":"add","path":"/a/5","value":1}]`},
+		{Document: `{"a":[1]}`, Patch: `[{"op":"add","path":"/a/01","value":1}]`},
+		{Document: `{"a":1}`, Patch: `[{"op":"test","path":"/a","value":2}]`},
+		{Document: `{"a":{"b":1}}`, Patch: `[{"op":"move","from":"/a","path":"/a/c"}]`},
+		{Document: `{"a":1}`, Patch: `[{"op":"copy","path":"/b"}]`},
+		{Document: `{"a":1}`, Patch: `[{"op":"unknown","path":"/a"}]`},
+	}
+	for i, x := range cases {
+		if _, err := Apply([]byte(x.Document), []byte(x.Patch)); err == nil {
+			t.Errorf("case %d, expected an error", i)
+		}
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		Document string
+		Patch    string
+		Expected string
+	}{
+		{Document: `{"a":"b"}`, Patch: `{"a":"c"}`, Expected: `{"a":"c"}`},
+		{Document: `{"a":"b"}`, Patch: `{"b":"c"}`, Expected: `{"a":"b","b":"c"}`},
+		{Document: `{"a":"b"}`, Patch: `{"a":null}`, Expected: `{}`},
+		{Document: `{"a":{"b":"c","d":1}}`, Patch: `{"a":{"b":"e","d":null}}`, Expected: `{"a":{"b":"e"}}`},
+		{Document: `{"a":[1,2]}`, Patch: `{"a":[3]}`, Expected: `{"a":[3]}`},
+		{Document: `{"a":"b"}`, Patch: `{"a":{"c":null,"d":1}}`, Expected: `{"a":{"d":1}}`},
+		{Document: `{"a":"b"}`, Patch: `["c"]`, Expected: `["c"]`},
+	}
+	for i, x := range cases {
+		patched, err := MergePatch([]byte(x.Document), []byte(x.Patch))
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if string(patched) != x.Expected {
+			t.Errorf("case %d, got: %s, want: %s", i, patched, x.Expected)
+		}
+	}
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); err == nil {
+		t.Errorf("expected an error for an invalid merge patch")
+	}
+}
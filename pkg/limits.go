@@ -0,0 +1,99 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	// sizeLimitError fails the read when the limit is exceeded
+	sizeLimitError = "error"
+	// sizeLimitWarn logs a warning when the limit is exceeded
+	sizeLimitWarn = "warn"
+)
+
+// sizeLimitProfiles is a map of platform profile to the maximum payload size in bytes, the size
+// being that of the raw payload before it is base64 encoded
+var sizeLimitProfiles = map[string]int{
+	"aws_user_data":     16384,
+	"azure_custom_data": 65535,
+	"gcp_metadata":      262144,
+}
+
+// sizeLimitProfileNames returns a sorted list of the profiles
+func sizeLimitProfileNames() []string {
+	var names []string
+	for name := range sizeLimitProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// checkSizeLimit is responsible for checking the payload size against the profile; exceeding
+// the limit with the warn action is reported by sizeLimitWarning instead
+func checkSizeLimit(profile, action string, size int) error {
+	limit, found := sizeLimitProfiles[profile]
+	if !found || size <= limit || action == sizeLimitWarn {
+		return nil
+	}
+
+	return fmt.Errorf("the rendered output is %d bytes, exceeding the %s limit of %d bytes", size, profile, limit)
+}
+
+// sizeLimitWarning returns a warning when the payload exceeds the limit of the profile, or comes
+// within ten percent of it, otherwise an empty string
+func sizeLimitWarning(profile string, size int) string {
+	limit, found := sizeLimitProfiles[profile]
+	switch {
+	case !found:
+		return ""
+	case size > limit:
+		return fmt.Sprintf("the rendered output is %d bytes, exceeding the %s limit of %d bytes", size, profile, limit)
+	case size*10 >= limit*9:
+		return fmt.Sprintf("the rendered output is %d bytes, within 10%% of the %s limit of %d bytes", size, profile, limit)
+	}
+
+	return ""
+}
+
+// limitWriter counts the bytes written through it, failing the write which exceeds the limit of
+// the profile unless the action is to warn
+type limitWriter struct {
+	w       io.Writer
+	profile string
+	action  string
+	size    int
+}
+
+// Write passes the content through while it is within the limit
+func (l *limitWriter) Write(p []byte) (int, error) {
+	l.size += len(p)
+	if limit, found := sizeLimitProfiles[l.profile]; found && l.size > limit && l.action != sizeLimitWarn {
+		return 0, fmt.Errorf("the rendered output is at least %d bytes, exceeding the %s limit of %d bytes", l.size, l.profile, limit)
+	}
+
+	return l.w.Write(p)
+}
+
+// Close checks the final size against the limit
+func (l *limitWriter) Close() error {
+	return checkSizeLimit(l.profile, l.action, l.size)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"testing"
+)
+
+func TestCheckSizeLimit(t *testing.T) {
+	cases := []struct {
+		Profile string
+		Action  string
+		Size    int
+		Error   bool
+	}{
+		{Profile: "", Action: sizeLimitError, Size: 1 << 30},
+		{Profile: "aws_user_data", Action: sizeLimitError, Size: 16384},
+		{Profile: "aws_user_data", Action: sizeLimitError, Size: 16385, Error: true},
+		{Profile: "aws_user_data", Action: sizeLimitWarn, Size: 16385},
+		{Profile: "azure_custom_data", Action: sizeLimitError, Size: 65536, Error: true},
+		{Profile: "gcp_metadata", Action: sizeLimitError, Size: 65536},
+	}
+	for i, x := range cases {
+		err := checkSizeLimit(x.Profile, x.Action, x.Size)
+		if x.Error && err == nil {
+			t.Errorf("case %d, expected an error", i)
+		}
+		if !x.Error && err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+		}
+	}
+}
+
+func TestSizeLimitWarning(t *testing.T) {
+	cases := map[int]string{
+		100:   "",
+		14745: "",
+		14746: "the rendered output is 14746 bytes, within 10% of the aws_user_data limit of 16384 bytes",
+		16385: "the rendered output is 16385 bytes, exceeding the aws_user_data limit of 16384 bytes",
+	}
+	for size, expected := range cases {
+		if got := sizeLimitWarning("aws_user_data", size); got != expected {
+			t.Errorf("size: %d, got: %q, want: %q", size, got, expected)
+		}
+	}
+	if got := sizeLimitWarning("", 1<<30); got != "" {
+		t.Errorf("expected no warning without a profile, got: %q", got)
+	}
+}
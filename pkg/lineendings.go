@@ -0,0 +1,63 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+const (
+	// targetOSLinux writes the content with unix line endings
+	targetOSLinux = "linux"
+	// targetOSWindows writes the content with windows line endings
+	targetOSWindows = "windows"
+)
+
+// lineEnding returns the line ending of the target operating system, defaulting to unix
+func lineEnding(targetOS string) string {
+	if targetOS == targetOSWindows {
+		return "\r\n"
+	}
+
+	return "\n"
+}
+
+// convertLineEndings returns the content with the line endings of the target operating system,
+// the content being left as rendered when no target is given
+func convertLineEndings(content, targetOS string) string {
+	if targetOS == "" {
+		return content
+	}
+	content = strings.Replace(content, "\r\n", "\n", -1)
+	if targetOS == targetOSWindows {
+		content = strings.Replace(content, "\n", "\r\n", -1)
+	}
+
+	return content
+}
+
+// targetOSSchema returns the schema of the target_os attribute of the file resources
+func targetOSSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringInSlice([]string{targetOSLinux, targetOSWindows}, false),
+		Description:  "Convert the line endings of the content to those of the operating system, linux or windows, by default written as rendered",
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"testing"
+)
+
+func TestConvertLineEndings(t *testing.T) {
+	cases := []struct {
+		Content  string
+		TargetOS string
+		Expected string
+	}{
+		{Content: "a\r\nb\n", Expected: "a\r\nb\n"},
+		{Content: "a\r\nb\n", TargetOS: targetOSLinux, Expected: "a\nb\n"},
+		{Content: "a\r\nb\n", TargetOS: targetOSWindows, Expected: "a\r\nb\r\n"},
+		{Content: "a", TargetOS: targetOSWindows, Expected: "a"},
+	}
+	for i, x := range cases {
+		if converted := convertLineEndings(x.Content, x.TargetOS); converted != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, converted, x.Expected)
+		}
+	}
+}
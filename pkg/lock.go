@@ -0,0 +1,87 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// lockRetryInterval is the interval between attempts to take a lock held elsewhere
+const lockRetryInterval = 100 * time.Millisecond
+
+// lockFile is responsible for taking an exclusive advisory lock on the file, creating it with the
+// mode when missing, and waiting up to the timeout for a lock held elsewhere; the returned
+// function releases the lock
+func lockFile(filename string, mode os.FileMode, timeout time.Duration) (func(), error) {
+	file, err := os.OpenFile(filename, os.O_RDONLY|os.O_CREATE, mode)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the file: %s for locking, error: %s", filename, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("unable to lock the file: %s, error: %s", filename, err)
+		}
+		if locked {
+			return func() {
+				unlock(file)
+				file.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for the lock on the file: %s", timeout, filename)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// lockResourceFile takes the lock on the file when enabled on the resource, otherwise returning
+// a release doing nothing
+func lockResourceFile(d *schema.ResourceData, filename string, mode os.FileMode) (func(), error) {
+	if !d.Get("lock").(bool) {
+		return func() {}, nil
+	}
+
+	return lockFile(filename, mode, time.Duration(d.Get("lock_timeout").(int))*time.Second)
+}
+
+// lockSchema returns the schema of the lock attribute of the file resources
+func lockSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Description: "Hold an exclusive advisory lock (flock) on the file while it is written, failing on the platforms without flock such as windows",
+	}
+}
+
+// lockTimeoutSchema returns the schema of the lock_timeout attribute of the file resources
+func lockTimeoutSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      30,
+		ValidateFunc: validation.IntAtLeast(0),
+		Description:  "The seconds to wait for a lock held elsewhere before failing",
+	}
+}
@@ -0,0 +1,54 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.conf")
+
+	release, err := lockFile(filename, 0600, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if info, err := os.Stat(filename); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the file to be created, info: %v, error: %v", info, err)
+	}
+	if _, err := lockFile(filename, 0600, 2*lockRetryInterval); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected the lock to be held, got: %v", err)
+	}
+	release()
+
+	release, err = lockFile(filename, 0600, 0)
+	if err != nil {
+		t.Fatalf("expected the lock to be released, error: %s", err)
+	}
+	release()
+}
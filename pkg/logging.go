@@ -0,0 +1,74 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// logTrace is the most verbose level
+	logTrace = "TRACE"
+	// logDebug is for diagnosing renders
+	logDebug = "DEBUG"
+	// logWarn is for conditions which do not fail the render
+	logWarn = "WARN"
+	// logError is for failed renders
+	logError = "ERROR"
+)
+
+// logEvent writes a leveled log line of the message followed by the fields as key=value pairs;
+// terraform filters the lines by the level prefix according to TF_LOG, and any secrets are redacted
+func logEvent(level, message string, fields ...interface{}) {
+	log.Print(redactions.redact(formatEvent(level, message, fields...)))
+}
+
+// formatEvent formats the log line, values containing spaces or quotes being quoted
+func formatEvent(level, message string, fields ...interface{}) string {
+	line := new(bytes.Buffer)
+	fmt.Fprintf(line, "[%s] gotemplate: %s", level, message)
+	for i := 0; i+1 < len(fields); i += 2 {
+		value := fmt.Sprintf("%v", fields[i+1])
+		if d, ok := fields[i+1].(time.Duration); ok {
+			value = d.Round(time.Microsecond).String()
+		}
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(line, " %v=%s", fields[i], value)
+	}
+
+	return line.String()
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+// Write writes the content, counting the bytes written
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+
+	return n, err
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestFormatEvent(t *testing.T) {
+	cases := []struct {
+		Fields   []interface{}
+		Expected string
+	}{
+		{Expected: "[DEBUG] gotemplate: template parsed"},
+		{Fields: []interface{}{"bytes", 12, "duration", 1500 * time.Microsecond}, Expected: "[DEBUG] gotemplate: template parsed bytes=12 duration=1.5ms"},
+		{Fields: []interface{}{"error", "bad template", "file", ""}, Expected: `[DEBUG] gotemplate: template parsed error="bad template" file=""`},
+		{Fields: []interface{}{"odd"}, Expected: "[DEBUG] gotemplate: template parsed"},
+	}
+	for i, x := range cases {
+		if got := formatEvent(logDebug, "template parsed", x.Fields...); got != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, got, x.Expected)
+		}
+	}
+}
+
+func TestCountingWriter(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	w := &countingWriter{w: buffer}
+	w.Write([]byte("hello"))
+	w.Write([]byte(" world"))
+	if w.n != 11 || buffer.String() != "hello world" {
+		t.Errorf("unexpected count: %d, content: %q", w.n, buffer.String())
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// stateUpgrader migrates the flattened attributes of a state from the version before it
+type stateUpgrader func(attributes map[string]string) error
+
+// the upgraders of each resource are indexed by the version they upgrade from, so the schema
+// version of a resource is always the length of its own list; renaming or changing the meaning
+// of an attribute requires appending an upgrader to the list of that resource only
+var (
+	consulKeyStateUpgraders    = []stateUpgrader{}
+	fileStateUpgraders         = []stateUpgrader{}
+	fileBlockStateUpgraders    = []stateUpgrader{}
+	localFileStateUpgraders    = []stateUpgrader{}
+	s3ObjectStateUpgraders     = []stateUpgrader{}
+	ssmParameterStateUpgraders = []stateUpgrader{}
+	vaultKVStateUpgraders      = []stateUpgrader{}
+)
+
+// withStateMigration is responsible for versioning the schema of the resource by its upgraders
+// and migrating older states to it
+func withStateMigration(r *schema.Resource, upgraders []stateUpgrader) *schema.Resource {
+	r.SchemaVersion = len(upgraders)
+	r.MigrateState = func(version int, state *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+		return migrateState(upgraders, version, state)
+	}
+
+	return r
+}
+
+// migrateState is responsible for applying the upgraders from the version of the state onwards
+func migrateState(upgraders []stateUpgrader, version int, state *terraform.InstanceState) (*terraform.InstanceState, error) {
+	if state == nil || state.Empty() {
+		return state, nil
+	}
+	if version > len(upgraders) {
+		return nil, fmt.Errorf("state schema version %d is newer than the provider supports (%d), please upgrade the provider", version, len(upgraders))
+	}
+	if state.Attributes == nil {
+		state.Attributes = make(map[string]string)
+	}
+	for i := version; i < len(upgraders); i++ {
+		if err := upgraders[i](state.Attributes); err != nil {
+			return nil, fmt.Errorf("unable to migrate the state from version %d, error: %s", i, err)
+		}
+	}
+
+	return state, nil
+}
+
+// renameAttribute moves the attribute, along with the flattened entries of a list, set or map,
+// to the new name
+func renameAttribute(attributes map[string]string, from, to string) {
+	for k, v := range attributes {
+		if k != from && !strings.HasPrefix(k, from+".") {
+			continue
+		}
+		delete(attributes, k)
+		attributes[to+strings.TrimPrefix(k, from)] = v
+	}
+}
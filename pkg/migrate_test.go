@@ -0,0 +1,131 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestMigrateState(t *testing.T) {
+	upgraders := []stateUpgrader{
+		func(attributes map[string]string) error {
+			renameAttribute(attributes, "rendered", "content")
+			return nil
+		},
+		func(attributes map[string]string) error {
+			attributes["upgraded"] = "true"
+			return nil
+		},
+	}
+	cases := []struct {
+		Version  int
+		Expected map[string]string
+	}{
+		{Version: 0, Expected: map[string]string{"id": "x", "content": "hello", "upgraded": "true"}},
+		{Version: 1, Expected: map[string]string{"id": "x", "rendered": "hello", "upgraded": "true"}},
+		{Version: 2, Expected: map[string]string{"id": "x", "rendered": "hello"}},
+	}
+	for i, x := range cases {
+		state := &terraform.InstanceState{ID: "x", Attributes: map[string]string{"id": "x", "rendered": "hello"}}
+		migrated, err := migrateState(upgraders, x.Version, state)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(migrated.Attributes, x.Expected) {
+			t.Errorf("case %d, got: %v, want: %v", i, migrated.Attributes, x.Expected)
+		}
+	}
+}
+
+func TestMigrateStateNewer(t *testing.T) {
+	state := &terraform.InstanceState{ID: "x", Attributes: map[string]string{"id": "x"}}
+	if _, err := migrateState(nil, 1, state); err == nil {
+		t.Errorf("expected an error for a state newer than the provider")
+	}
+	if migrated, err := migrateState(nil, 5, &terraform.InstanceState{}); err != nil || !migrated.Empty() {
+		t.Errorf("expected an empty state to be returned unchanged")
+	}
+}
+
+func TestRenameAttribute(t *testing.T) {
+	attributes := map[string]string{
+		"vars.%":       "1",
+		"vars.name":    "app",
+		"vars_files.#": "0",
+		"rendered":     "x",
+	}
+	renameAttribute(attributes, "vars", "values")
+	expected := map[string]string{
+		"values.%":     "1",
+		"values.name":  "app",
+		"vars_files.#": "0",
+		"rendered":     "x",
+	}
+	if !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("got: %v, want: %v", attributes, expected)
+	}
+}
+
+func TestWithStateMigration(t *testing.T) {
+	upgraders := []stateUpgrader{
+		func(attributes map[string]string) error {
+			renameAttribute(attributes, "rendered", "content")
+			return nil
+		},
+	}
+	r := withStateMigration(goDataSourceFile(), upgraders)
+	if r.SchemaVersion != 1 || r.MigrateState == nil {
+		t.Fatalf("expected the resource to be versioned by its upgraders")
+	}
+	state := &terraform.InstanceState{ID: "x", Attributes: map[string]string{"id": "x", "rendered": "hello"}}
+	migrated, err := r.MigrateState(0, state, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := map[string]string{"id": "x", "content": "hello"}; !reflect.DeepEqual(migrated.Attributes, expected) {
+		t.Errorf("got: %v, want: %v", migrated.Attributes, expected)
+	}
+	if other := withStateMigration(goResourceLocalFile(), nil); other.SchemaVersion != 0 {
+		t.Errorf("expected the upgraders of one resource not to version another")
+	}
+}
+
+func TestProviderResourcesMigrate(t *testing.T) {
+	upgraders := map[string][]stateUpgrader{
+		"gotemplate_consul_key":    consulKeyStateUpgraders,
+		"gotemplate_file":          fileStateUpgraders,
+		"gotemplate_file_block":    fileBlockStateUpgraders,
+		"gotemplate_local_file":    localFileStateUpgraders,
+		"gotemplate_s3_object":     s3ObjectStateUpgraders,
+		"gotemplate_ssm_parameter": ssmParameterStateUpgraders,
+		"gotemplate_vault_kv":      vaultKVStateUpgraders,
+	}
+	for name, r := range Provider().(*schema.Provider).ResourcesMap {
+		list, found := upgraders[name]
+		if !found {
+			t.Errorf("resource: %s, has no state upgraders", name)
+			continue
+		}
+		if r.SchemaVersion != len(list) || r.MigrateState == nil {
+			t.Errorf("resource: %s, expected the resource to be versioned", name)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/pathorcontents"
+)
+
+// resolvePath resolves a relative path against the base path; absolute, home directory and, on
+// windows, drive relative paths are not resolved. Paths may use either forward slashes or the
+// native separator
+func resolvePath(base, path string) string {
+	if path == "" {
+		return path
+	}
+	path = filepath.FromSlash(path)
+	if base == "" || filepath.IsAbs(path) || filepath.VolumeName(path) != "" || strings.HasPrefix(path, "~") {
+		return path
+	}
+
+	return filepath.Join(filepath.FromSlash(base), path)
+}
+
+// readPathOrContents reads the file if the value is a path, relative paths being resolved against
+// the base path, otherwise the value itself is returned as the content
+func readPathOrContents(base, value string) (string, error) {
+	if resolved := resolvePath(base, value); resolved != value {
+		if info, err := os.Stat(resolved); err == nil && !info.IsDir() {
+			value = resolved
+		}
+	}
+	content, _, err := pathorcontents.Read(value)
+
+	return content, err
+}
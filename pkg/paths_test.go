@@ -0,0 +1,67 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	base := filepath.Join("modules", "app")
+	cases := []struct {
+		Base     string
+		Path     string
+		Expected string
+	}{
+		{Base: "", Path: "templates/a.tmpl", Expected: "templates/a.tmpl"},
+		{Base: base, Path: "", Expected: ""},
+		{Base: base, Path: "templates/a.tmpl", Expected: filepath.Join(base, "templates", "a.tmpl")},
+		{Base: base, Path: "~/a.tmpl", Expected: "~/a.tmpl"},
+		{Base: base, Path: "../shared", Expected: filepath.Join("modules", "shared")},
+	}
+	for i, x := range cases {
+		if got := resolvePath(x.Base, filepath.FromSlash(x.Path)); got != filepath.FromSlash(x.Expected) {
+			t.Errorf("case %d, got: %s, want: %s", i, got, x.Expected)
+		}
+	}
+}
+
+func TestReadPathOrContents(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{"templates/a.tmpl": "from file"})
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		Base     string
+		Value    string
+		Expected string
+	}{
+		{Base: dir, Value: "templates/a.tmpl", Expected: "from file"},
+		{Base: dir, Value: "Hello {{ .name }}", Expected: "Hello {{ .name }}"},
+		{Base: "", Value: filepath.Join(dir, "templates", "a.tmpl"), Expected: "from file"},
+	}
+	for i, x := range cases {
+		content, err := readPathOrContents(x.Base, x.Value)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if content != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, content, x.Expected)
+		}
+	}
+}
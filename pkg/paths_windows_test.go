@@ -0,0 +1,41 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"testing"
+)
+
+func TestResolvePathWindows(t *testing.T) {
+	cases := []struct {
+		Base     string
+		Path     string
+		Expected string
+	}{
+		{Base: `C:\modules\app`, Path: "templates/a.tmpl", Expected: `C:\modules\app\templates\a.tmpl`},
+		{Base: "C:/modules/app", Path: `templates\a.tmpl`, Expected: `C:\modules\app\templates\a.tmpl`},
+		{Base: `C:\modules\app`, Path: `D:\templates\a.tmpl`, Expected: `D:\templates\a.tmpl`},
+		{Base: `C:\modules\app`, Path: "D:/templates/a.tmpl", Expected: `D:\templates\a.tmpl`},
+		{Base: `C:\modules\app`, Path: `D:templates`, Expected: `D:templates`},
+		{Base: `C:\modules\app`, Path: `\\server\share\a.tmpl`, Expected: `\\server\share\a.tmpl`},
+		{Base: `C:\modules\app`, Path: `..\shared\`, Expected: `C:\modules\shared`},
+	}
+	for i, x := range cases {
+		if got := resolvePath(x.Base, x.Path); got != x.Expected {
+			t.Errorf("case %d, got: %s, want: %s", i, got, x.Expected)
+		}
+	}
+}
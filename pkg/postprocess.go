@@ -0,0 +1,202 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/validate"
+)
+
+const (
+	// trailingNewlineKeep leaves the end of the output as rendered
+	trailingNewlineKeep = "keep"
+	// trailingNewlineEnsure ends the output with a single line break
+	trailingNewlineEnsure = "ensure"
+	// trailingNewlineStrip removes the line breaks from the end of the output
+	trailingNewlineStrip = "strip"
+)
+
+// trailingNewlinePolicies maps the trailing newline policy to its post processor
+var trailingNewlinePolicies = map[string]string{
+	trailingNewlineKeep:   "",
+	trailingNewlineEnsure: "ensure_newline",
+	trailingNewlineStrip:  "strip_newline",
+}
+
+// postProcessor is a single step of the post processing pipeline
+type postProcessor struct {
+	// binary indicates the output is no longer text, so only other binary steps may follow
+	binary bool
+	// process transforms the content
+	process func([]byte) ([]byte, error)
+}
+
+// postProcessors is a map of the name to post processor
+var postProcessors = map[string]postProcessor{
+	"trim": {process: func(content []byte) ([]byte, error) {
+		return bytes.TrimSpace(content), nil
+	}},
+	"ensure_newline": {process: ensureNewline},
+	"strip_newline": {process: func(content []byte) ([]byte, error) {
+		return bytes.TrimRight(content, "\r\n"), nil
+	}},
+	"minify_json":    {process: minifyJSON},
+	"pretty_json":    {process: prettyJSON},
+	"sort_keys":      {process: sortKeys},
+	"sort_keys_yaml": {process: sortKeysYAML},
+	compressionGzip: {binary: true, process: func(content []byte) ([]byte, error) {
+		return compressOutput(content, compressionGzip)
+	}},
+	compressionZstd: {binary: true, process: func(content []byte) ([]byte, error) {
+		return compressOutput(content, compressionZstd)
+	}},
+	compressionBrotli: {binary: true, process: func(content []byte) ([]byte, error) {
+		return compressOutput(content, compressionBrotli)
+	}},
+}
+
+// postProcessorNames returns a sorted list of the post processors
+func postProcessorNames() []string {
+	var names []string
+	for name := range postProcessors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// splitPostProcess is responsible for splitting the pipeline into the text steps, applied to the
+// rendered template, and the binary steps, applied to the encoded output
+func splitPostProcess(names []string) ([]string, []string, error) {
+	for i, name := range names {
+		x, found := postProcessors[name]
+		if !found {
+			return nil, nil, fmt.Errorf("unsupported post processor: %s", name)
+		}
+		if !x.binary {
+			continue
+		}
+		for _, next := range names[i+1:] {
+			if !postProcessors[next].binary {
+				return nil, nil, fmt.Errorf("post processor %s cannot follow %s, which produces binary output", next, name)
+			}
+		}
+		return names[:i], names[i:], nil
+	}
+
+	return names, nil, nil
+}
+
+// postProcess is responsible for running the content through each of the post processors in order
+func postProcess(content []byte, names []string) ([]byte, error) {
+	for _, name := range names {
+		x, found := postProcessors[name]
+		if !found {
+			return nil, fmt.Errorf("unsupported post processor: %s", name)
+		}
+		processed, err := x.process(content)
+		if err != nil {
+			return nil, fmt.Errorf("post processor %s failed, error: %s", name, err)
+		}
+		content = processed
+	}
+
+	return content, nil
+}
+
+// ensureNewline ends the content with a single line break, matching the line endings of the
+// content; empty content is left empty
+func ensureNewline(content []byte) ([]byte, error) {
+	if len(content) == 0 {
+		return content, nil
+	}
+	newline := []byte("\n")
+	if index := bytes.IndexByte(content, '\n'); index > 0 && content[index-1] == '\r' {
+		newline = []byte("\r\n")
+	}
+
+	return append(bytes.TrimRight(content, "\r\n"), newline...), nil
+}
+
+// minifyJSON removes the insignificant whitespace from the json content
+func minifyJSON(content []byte) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	if err := json.Compact(buffer, content); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// prettyJSON indents the json content by two spaces
+func prettyJSON(content []byte) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	if err := json.Indent(buffer, content, "", "  "); err != nil {
+		return nil, err
+	}
+	buffer.WriteString("\n")
+
+	return buffer.Bytes(), nil
+}
+
+// sortKeys re-encodes the json content with the keys of every object sorted, the output being compact
+func sortKeys(content []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// sortKeysYAML re-encodes each of the yaml documents with the keys of every mapping sorted; the
+// comments and any empty documents are dropped
+func sortKeysYAML(content []byte) ([]byte, error) {
+	var documents []string
+	for i, x := range validate.SplitYAMLDocuments(string(content)) {
+		var document interface{}
+		if err := yaml.Unmarshal([]byte(x), &document); err != nil {
+			return nil, fmt.Errorf("unable to decode yaml document %d, error: %s", i, err)
+		}
+		if document == nil {
+			continue
+		}
+		encoded, err := yaml.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode yaml document %d, error: %s", i, err)
+		}
+		documents = append(documents, string(encoded))
+	}
+
+	return []byte(strings.Join(documents, "---\n")), nil
+}
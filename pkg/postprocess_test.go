@@ -0,0 +1,145 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestPostProcess(t *testing.T) {
+	cases := []struct {
+		Content  string
+		Steps    []string
+		Expected string
+	}{
+		{Content: "  text \n", Steps: []string{"trim"}, Expected: "text"},
+		{Content: "{ \"b\": 1,\n \"a\": [1, 2] }", Steps: []string{"minify_json"}, Expected: `{"b":1,"a":[1,2]}`},
+		{Content: `{"b":1,"a":{"d":"<x>","c":1.50}}`, Steps: []string{"sort_keys"}, Expected: `{"a":{"c":1.50,"d":"<x>"},"b":1}`},
+		{Content: `{"b":1,"a":2}`, Steps: []string{"sort_keys", "pretty_json"}, Expected: "{\n  \"a\": 2,\n  \"b\": 1\n}\n"},
+		{Content: "line", Steps: []string{"ensure_newline"}, Expected: "line\n"},
+		{Content: "line\n\n\n", Steps: []string{"ensure_newline"}, Expected: "line\n"},
+		{Content: "a\r\nb", Steps: []string{"ensure_newline"}, Expected: "a\r\nb\r\n"},
+		{Content: "", Steps: []string{"ensure_newline"}, Expected: ""},
+		{Content: "a\nb\r\n\n", Steps: []string{"strip_newline"}, Expected: "a\nb"},
+		{Content: "# comment\nb: 1\na:\n  d: [2, 1]\n  c: x\n", Steps: []string{"sort_keys_yaml"}, Expected: "a:\n  c: x\n  d:\n  - 2\n  - 1\nb: 1\n"},
+		{Content: "---\nb: 1\na: 2\n---\n# empty\n---\nz: 1\nm: 2\n", Steps: []string{"sort_keys_yaml"}, Expected: "a: 2\nb: 1\n---\nm: 2\nz: 1\n"},
+		{Content: "unchanged", Expected: "unchanged"},
+	}
+	for i, x := range cases {
+		got, err := postProcess([]byte(x.Content), x.Steps)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if string(got) != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, got, x.Expected)
+		}
+	}
+	if _, err := postProcess([]byte("not json"), []string{"minify_json"}); err == nil {
+		t.Errorf("expected an error for invalid json")
+	}
+	if _, err := postProcess([]byte("a: [1"), []string{"sort_keys_yaml"}); err == nil {
+		t.Errorf("expected an error for invalid yaml")
+	}
+}
+
+func TestSplitPostProcess(t *testing.T) {
+	text, binary, err := splitPostProcess([]string{"trim", "sort_keys", "gzip"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(text, []string{"trim", "sort_keys"}) || !reflect.DeepEqual(binary, []string{"gzip"}) {
+		t.Errorf("unexpected split, text: %v, binary: %v", text, binary)
+	}
+	if _, _, err := splitPostProcess([]string{"gzip", "trim"}); err == nil {
+		t.Errorf("expected an error for a text step following a binary one")
+	}
+	if _, _, err := splitPostProcess([]string{"unknown"}); err == nil {
+		t.Errorf("expected an error for an unknown step")
+	}
+}
+
+func TestGoTemplateTrailingNewline(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template         = "{{ .name }}"
+						vars             = { name = "web" }
+						trailing_newline = "ensure"
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "web\n"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template         = " { \"a\": 1 } "
+						post_process     = ["trim", "pretty_json"]
+						trailing_newline = "strip"
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "{\n  \"a\": 1\n}"),
+			},
+		},
+	})
+}
+
+func TestGoTemplatePostProcess(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template     = " { \"b\": 1, \"a\": 2 } "
+						post_process = ["trim", "sort_keys", "gzip"]
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", `{"a":2,"b":1}`),
+					func(s *terraform.State) error {
+						encoded := s.RootModule().Resources["data.gotemplate_file.test"].Primary.Attributes["rendered_base64"]
+						decoded, err := base64.StdEncoding.DecodeString(encoded)
+						if err != nil {
+							return err
+						}
+						r, err := gzip.NewReader(bytes.NewReader(decoded))
+						if err != nil {
+							return err
+						}
+						content, err := ioutil.ReadAll(r)
+						if err != nil {
+							return err
+						}
+						if string(content) != `{"a":2,"b":1}` {
+							return fmt.Errorf("unexpected content: %q", content)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
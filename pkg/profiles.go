@@ -0,0 +1,93 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// profilesSchema returns the schema of the named sets of provider settings selected by profile
+func profilesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Named sets of default vars, snippets and remote connection settings, the one named by profile applying over the provider settings",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "The name of the profile, i.e. prod",
+				},
+				"vars": {
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Description: "Variables passed to every template, beneath those of the data source",
+				},
+				"snippets": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The default path to a directory containing snippets, replacing that of the provider",
+				},
+				"snippet_paths": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Directories of snippets loaded before the snippet_paths of the data source, which may redefine them",
+				},
+				"aws":    awsSchema(),
+				"consul": consulSchema(),
+				"vault":  vaultSchema(),
+			},
+		},
+	}
+}
+
+// applyProfile is responsible for applying the selected profile over the provider configuration
+func applyProfile(config *providerConfig, name string, profiles []interface{}) error {
+	var names []string
+	for _, x := range profiles {
+		profile := x.(map[string]interface{})
+		if profile["name"].(string) != name {
+			names = append(names, profile["name"].(string))
+			continue
+		}
+		config.defaults.profile = name
+		config.defaults.vars = profile["vars"].(map[string]interface{})
+		config.defaults.snippetPaths = toStrings(profile["snippet_paths"].([]interface{}))
+		if snippets := profile["snippets"].(string); snippets != "" {
+			config.defaults.snippets = snippets
+		}
+		// step: a connection block of the profile replaces that of the provider as a whole
+		if blocks := profile["aws"].([]interface{}); len(blocks) > 0 {
+			config.aws = awsConfig(blocks)
+		}
+		if blocks := profile["consul"].([]interface{}); len(blocks) > 0 {
+			config.consul = consulConfig(blocks)
+		}
+		if blocks := profile["vault"].([]interface{}); len(blocks) > 0 {
+			config.vault = vaultConfig(blocks)
+		}
+		return nil
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("unknown profile: %s, the profiles are: %s", name, strings.Join(names, ", "))
+}
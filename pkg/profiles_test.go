@@ -0,0 +1,124 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestApplyProfile(t *testing.T) {
+	profiles := []interface{}{
+		map[string]interface{}{
+			"name":          "dev",
+			"vars":          map[string]interface{}{"env": "dev"},
+			"snippets":      "",
+			"snippet_paths": []interface{}{},
+			"aws":           []interface{}{},
+			"consul":        []interface{}{},
+			"vault":         []interface{}{},
+		},
+		map[string]interface{}{
+			"name":          "prod",
+			"vars":          map[string]interface{}{"env": "prod"},
+			"snippets":      "/snippets/prod",
+			"snippet_paths": []interface{}{"/snippets/shared"},
+			"aws":           []interface{}{},
+			"consul":        []interface{}{map[string]interface{}{"address": "consul.prod:8500", "scheme": "", "datacenter": "", "token": "", "ca_file": "", "cert_file": "", "key_file": ""}},
+			"vault":         []interface{}{},
+		},
+	}
+	config := &providerConfig{defaults: renderDefaults{snippets: "/snippets"}}
+	config.consul.Address = "127.0.0.1:8500"
+	config.vault.address = "https://vault:8200"
+	if err := applyProfile(config, "prod", profiles); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if config.defaults.profile != "prod" || config.defaults.vars["env"] != "prod" || config.defaults.snippets != "/snippets/prod" {
+		t.Errorf("unexpected defaults: %+v", config.defaults)
+	}
+	if len(config.defaults.snippetPaths) != 1 || config.defaults.snippetPaths[0] != "/snippets/shared" {
+		t.Errorf("unexpected snippet paths: %v", config.defaults.snippetPaths)
+	}
+	if config.consul.Address != "consul.prod:8500" || config.vault.address != "https://vault:8200" {
+		t.Errorf("expected only the consul settings to be replaced, consul: %s, vault: %s", config.consul.Address, config.vault.address)
+	}
+
+	err := applyProfile(&providerConfig{}, "staging", profiles)
+	if err == nil || err.Error() != "unknown profile: staging, the profiles are: dev, prod" {
+		t.Errorf("expected an unknown profile error, got: %v", err)
+	}
+}
+
+func TestGoTemplateProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "banner.tmpl"), []byte(`{{ define "banner" }}[{{ .env }}]{{ end }}`), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	config := func(profile, vars string) string {
+		return fmt.Sprintf(`
+			provider "gotemplate" {
+				profile = "%s"
+				profiles {
+					name = "dev"
+					vars {
+						env = "dev"
+					}
+				}
+				profiles {
+					name          = "prod"
+					snippet_paths = ["%s"]
+					vars {
+						env  = "prod"
+						team = "platform"
+					}
+				}
+			}
+			data "gotemplate_file" "test" {
+				template = "{{ template \"banner\" . }} {{ .team }} {{ .gotemplate.profile }}"
+				%s
+			}`, profile, dir, vars)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("prod", ""),
+				Check:  resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "[prod] platform prod"),
+			},
+			{
+				Config: config("prod", `vars { env = "canary" }`),
+				Check:  resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "[canary] platform prod"),
+			},
+			{
+				Config:      config("staging", ""),
+				ExpectError: regexp.MustCompile("unknown profile: staging"),
+			},
+		},
+	})
+}
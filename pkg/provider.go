@@ -16,21 +16,245 @@ limitations under the License.
 package pkg
 
 import (
+	"time"
+
+	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
 )
 
+// providerConfig is the provider configuration shared by the data sources
+type providerConfig struct {
+	// library is the shared library of defines, nil when not configured
+	library *render.Library
+	// snippetCache holds the parsed snippet directories shared by the renders
+	snippetCache *render.SnippetCache
+	// budget bounds the memory held by the renders, nil when unlimited
+	budget *memoryBudget
+	// semaphore bounds the number of concurrent renders, nil when unbounded
+	semaphore renderSemaphore
+	// renderPool bounds the number of files rendered at once by the multi-output data sources,
+	// across all of them, nil when unbounded
+	renderPool renderSemaphore
+	// defaults are the render settings used when not set by the data sources
+	defaults renderDefaults
+	// consul is the configuration of the consul client used by the consul resources
+	consul api.Config
+	// aws are the settings of the aws clients used by the aws resources
+	aws awsSettings
+	// vault are the settings of the vault client used by the vault resources
+	vault vaultSettings
+	// templates is the registry of the named templates
+	templates *templateRegistry
+	// commands runs the external commands permitted to the resources
+	commands *commandRunner
+}
+
 // Provider returns the plugin definition
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	return withRedactedErrors(&schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"allowed_commands": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The executables the resources may run, as given in their commands, i.e. nginx or /usr/bin/systemctl; no command may be run unless listed",
+			},
+			"aws":    awsSchema(),
+			"consul": consulSchema(),
+			"disable_functions": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The names of template functions removed by default, i.e. to forbid functions in shared modules",
+			},
+			"left_delimiter": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The default left action delimiter of the templates",
+			},
+			"library": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The path to a directory of defines parsed once and made available to every template",
+			},
+			"max_concurrent_renders": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of files rendered at once across the multi-output data sources, i.e. gotemplate_dir, zero being unbounded; unlike render_concurrency a slot is held per file rather than per data source",
+			},
+			"memory_budget": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum bytes of rendered output held across the concurrent renders, zero being unlimited",
+			},
+			"memory_budget_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      budgetQueue,
+				ValidateFunc: validation.StringInSlice([]string{budgetQueue, budgetFail}, false),
+				Description:  "Whether a render which would exceed the memory budget waits for it (queue) or fails (fail)",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of the profile applied over the provider settings, i.e. to select the environment of a provider alias",
+			},
+			"profiles": profilesSchema(),
+			"redact_patterns": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Regexes whose matches, or capture groups when they have any, are redacted from the error messages, traces and logs",
+			},
+			"render_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of data sources rendering at once, zero being unbounded",
+			},
+			"right_delimiter": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The default right action delimiter of the templates",
+			},
+			"snippets": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The default path to a directory containing snippets",
+			},
+			"strict": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Fail renders on references to missing variables by default",
+			},
+			"vault": vaultSchema(),
+		},
+		ConfigureFunc: providerConfigure,
 		DataSourcesMap: map[string]*schema.Resource{
-			"gotemplate_file": goDataSourceFile(),
+			"gotemplate_assert":           goDataSourceAssert(),
+			"gotemplate_cloudinit_config": goDataSourceCloudInit(),
+			"gotemplate_concat":           goDataSourceConcat(),
+			"gotemplate_diff":             goDataSourceDiff(),
+			"gotemplate_dir":              goDataSourceDir(),
+			"gotemplate_file":             goDataSourceFile(),
+			"gotemplate_functions":        goDataSourceFunctions(),
+			"gotemplate_json_patch":       goDataSourceJSONPatch(),
+			"gotemplate_merge":            goDataSourceMerge(),
+			"gotemplate_snippet_index":    goDataSourceSnippetIndex(),
+			"gotemplate_template":         goDataSourceTemplate(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"gotemplate_file": schema.DataSourceResourceShim(
+			"gotemplate_consul_key": withStateMigration(goResourceConsulKey(), consulKeyStateUpgraders),
+			"gotemplate_file": withStateMigration(withDeferredRender(schema.DataSourceResourceShim(
 				"gotemplate_file",
-				goDataSourceFile(),
-			),
+				goResourceFile(),
+			)), fileStateUpgraders),
+			"gotemplate_file_block":    withStateMigration(goResourceFileBlock(), fileBlockStateUpgraders),
+			"gotemplate_local_file":    withStateMigration(goResourceLocalFile(), localFileStateUpgraders),
+			"gotemplate_s3_object":     withStateMigration(goResourceS3Object(), s3ObjectStateUpgraders),
+			"gotemplate_ssm_parameter": withStateMigration(goResourceSSMParameter(), ssmParameterStateUpgraders),
+			"gotemplate_vault_kv":      withStateMigration(goResourceVaultKV(), vaultKVStateUpgraders),
 		},
+	})
+}
+
+// providerConfigure is responsible for loading the provider configuration
+func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+	config := &providerConfig{
+		budget:       newMemoryBudget(int64(d.Get("memory_budget").(int)), d.Get("memory_budget_action").(string)),
+		semaphore:    newRenderSemaphore(d.Get("render_concurrency").(int)),
+		renderPool:   newRenderSemaphore(d.Get("max_concurrent_renders").(int)),
+		consul:       consulConfig(d.Get("consul").([]interface{})),
+		aws:          awsConfig(d.Get("aws").([]interface{})),
+		vault:        vaultConfig(d.Get("vault").([]interface{})),
+		templates:    newTemplateRegistry(),
+		commands:     newCommandRunner(toStrings(d.Get("allowed_commands").([]interface{}))),
+		snippetCache: render.NewSnippetCache(),
+		defaults: renderDefaults{
+			strict:       d.Get("strict").(bool),
+			leftDelim:    d.Get("left_delimiter").(string),
+			rightDelim:   d.Get("right_delimiter").(string),
+			snippets:     d.Get("snippets").(string),
+			disableFuncs: toStrings(d.Get("disable_functions").([]interface{})),
+		},
+	}
+	if err := checkFunctionNames(config.defaults.disableFuncs); err != nil {
+		return nil, err
 	}
+	if name := d.Get("profile").(string); name != "" {
+		if err := applyProfile(config, name, d.Get("profiles").([]interface{})); err != nil {
+			return nil, err
+		}
+	}
+	patterns, err := compileRedactPatterns(toStrings(d.Get("redact_patterns").([]interface{})))
+	if err != nil {
+		return nil, err
+	}
+	redactions.addPatterns(patterns)
+	if dir := d.Get("library").(string); dir != "" {
+		started := time.Now()
+		library, err := render.LoadLibrary(dir, render.Options{
+			LeftDelim:    config.defaults.leftDelim,
+			RightDelim:   config.defaults.rightDelim,
+			DisableFuncs: config.defaults.disableFuncs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		config.library = library
+		logEvent(logDebug, "library loaded", "dir", dir, "templates", library.Len(), "duration", time.Since(started))
+	}
+
+	return config, nil
+}
+
+// libraryOf returns the shared library from the provider configuration, if any
+func libraryOf(meta interface{}) *render.Library {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.library
+	}
+
+	return nil
+}
+
+// snippetCacheOf returns the snippet cache from the provider configuration, if any
+func snippetCacheOf(meta interface{}) *render.SnippetCache {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.snippetCache
+	}
+
+	return nil
+}
+
+// renderPoolOf returns the render pool from the provider configuration, if any
+func renderPoolOf(meta interface{}) renderSemaphore {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.renderPool
+	}
+
+	return nil
+}
+
+// budgetOf returns the memory budget from the provider configuration, if any
+func budgetOf(meta interface{}) *memoryBudget {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.budget
+	}
+
+	return nil
+}
+
+// semaphoreOf returns the render semaphore from the provider configuration, if any
+func semaphoreOf(meta interface{}) renderSemaphore {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.semaphore
+	}
+
+	return nil
 }
@@ -16,8 +16,13 @@ limitations under the License.
 package pkg
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
 
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 )
 
@@ -26,3 +31,145 @@ func TestProvider(t *testing.T) {
 		t.Fatalf("err: %s", err)
 	}
 }
+
+func TestProviderLibrary(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"banner.tmpl": `{{ define "banner" }}# managed by terraform{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "gotemplate" {
+						library = "%s"
+					}
+
+					data "gotemplate_file" "test" {
+						template = "{{ template \"banner\" }}"
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "# managed by terraform"),
+			},
+		},
+	})
+}
+
+func TestProviderDefaults(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"banner.tmpl": `[[ define "banner" ]]# [[ .name ]][[ end ]]`,
+	})
+	defer os.RemoveAll(dir)
+
+	provider := fmt.Sprintf(`
+		provider "gotemplate" {
+			strict            = true
+			left_delimiter    = "[["
+			right_delimiter   = "]]"
+			snippets          = "%s"
+			disable_functions = ["upper"]
+		}`, filepath.ToSlash(dir))
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + `
+					data "gotemplate_file" "test" {
+						template = "[[ template \"banner\" . ]] {{ lower .name }}"
+						vars {
+							name = "App"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "# App {{ lower .name }}"),
+			},
+			{
+				Config: provider + `
+					data "gotemplate_file" "test" {
+						template = "[[ .missing ]]"
+					}`,
+				ExpectError: regexp.MustCompile("map has no entry for key"),
+			},
+			{
+				Config: provider + `
+					data "gotemplate_file" "test" {
+						template = "[[ upper .name ]]"
+						vars {
+							name = "app"
+						}
+					}`,
+				ExpectError: regexp.MustCompile(`function "upper" not defined`),
+			},
+		},
+	})
+}
+
+func TestProviderDefaultsOverridden(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "gotemplate" {
+						strict            = true
+						left_delimiter    = "[["
+						right_delimiter   = "]]"
+						disable_functions = ["upper"]
+					}
+
+					data "gotemplate_file" "test" {
+						template        = "<< .name >><< .missing >>"
+						strict          = false
+						left_delimiter  = "<<"
+						right_delimiter = ">>"
+						vars {
+							name = "app"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "app<no value>"),
+			},
+			{
+				Config: `
+					provider "gotemplate" {
+						disable_functions = ["upper"]
+					}
+
+					data "gotemplate_file" "test" {
+						template          = "{{ upper .name }}"
+						disable_functions = ["lower"]
+						vars {
+							name = "app"
+						}
+					}`,
+				ExpectError: regexp.MustCompile(`function "upper" not defined`),
+			},
+			{
+				Config: `
+					provider "gotemplate" {
+						disable_functions = ["upper"]
+					}
+
+					data "gotemplate_file" "test" {
+						template          = "{{ lower .name }}"
+						disable_functions = ["lower"]
+						vars {
+							name = "APP"
+						}
+					}`,
+				ExpectError: regexp.MustCompile(`function "lower" not defined`),
+			},
+			{
+				Config: `
+					provider "gotemplate" {
+						disable_functions = ["nonexistent"]
+					}
+
+					data "gotemplate_file" "test" {
+						template = "x"
+					}`,
+				ExpectError: regexp.MustCompile("unknown template function: nonexistent"),
+			},
+		},
+	})
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	// redactedText replaces the redacted content
+	redactedText = "<redacted>"
+	// minSensitiveLength is the shortest sensitive value redacted, shorter strings being too
+	// common in the output to remove every occurrence of
+	minSensitiveLength = 4
+)
+
+// redactor removes secrets from the error messages, traces and logs; the patterns are shared by
+// the process as the logs are, so those of every provider instance are redacted from all output,
+// while the values marked sensitive are only held by the redactor scoped to their render
+type redactor struct {
+	sync.RWMutex
+	// patterns are the regexes whose matches, or capture groups when they have any, are redacted
+	patterns []*regexp.Regexp
+	// values are the sensitive values redacted wherever they appear
+	values map[string]bool
+}
+
+// redactions is the redactor of the patterns applied to all output
+var redactions = &redactor{values: make(map[string]bool)}
+
+// compileRedactPatterns is responsible for compiling the redaction patterns
+func compileRedactPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, x := range patterns {
+		re, err := regexp.Compile(x)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern: %s, error: %s", x, err)
+		}
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// addPatterns adds the patterns to those redacted, ignoring any already present
+func (r *redactor) addPatterns(patterns []*regexp.Regexp) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, x := range patterns {
+		found := false
+		for _, existing := range r.patterns {
+			if existing.String() == x.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.patterns = append(r.patterns, x)
+		}
+	}
+}
+
+// withValues returns a redactor of the patterns and the values, the values being redacted only
+// by it; a render uses one so its sensitive values do not outlive it
+func (r *redactor) withValues(values ...string) *redactor {
+	r.RLock()
+	defer r.RUnlock()
+
+	scoped := &redactor{patterns: append([]*regexp.Regexp(nil), r.patterns...), values: make(map[string]bool)}
+	scoped.addValues(values...)
+
+	return scoped
+}
+
+// addValues adds the values to those redacted, empty values being ignored
+func (r *redactor) addValues(values ...string) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, x := range values {
+		if x != "" {
+			r.values[x] = true
+		}
+	}
+}
+
+// redact returns the text with the sensitive values and the matches of the patterns replaced
+func (r *redactor) redact(text string) string {
+	r.RLock()
+	defer r.RUnlock()
+
+	if len(r.values) > 0 {
+		// step: replace the longest values first, so a value containing another is removed whole
+		values := make([]string, 0, len(r.values))
+		for x := range r.values {
+			values = append(values, x)
+		}
+		sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+		for _, x := range values {
+			text = strings.Replace(text, x, redactedText, -1)
+		}
+	}
+	for _, x := range r.patterns {
+		text = redactMatches(x, text)
+	}
+
+	return text
+}
+
+// redactMatches replaces the matches of the pattern, only the capture groups when it has any
+func redactMatches(re *regexp.Regexp, text string) string {
+	var out strings.Builder
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(text, -1) {
+		spans := match[:2]
+		if len(match) > 2 {
+			spans = match[2:]
+		}
+		// step: an unmatched group is -1 and a nested group starts before the end of its parent
+		for i := 0; i+1 < len(spans); i += 2 {
+			if spans[i] < last {
+				continue
+			}
+			out.WriteString(text[last:spans[i]])
+			out.WriteString(redactedText)
+			last = spans[i+1]
+		}
+	}
+	out.WriteString(text[last:])
+
+	return out.String()
+}
+
+// redactError returns the error with its message redacted
+func (r *redactor) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if message := r.redact(err.Error()); message != err.Error() {
+		return errors.New(message)
+	}
+
+	return err
+}
+
+// sensitiveValues returns the string values held by the named variables, including those nested
+// in lists and maps; bools, numbers and strings shorter than minSensitiveLength are skipped, as
+// redacting every occurrence of true or 1 would mangle the output
+func sensitiveValues(vars map[string]interface{}, names []string) []string {
+	var values []string
+	var walk func(interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			if len(v) >= minSensitiveLength {
+				values = append(values, v)
+			}
+		case []interface{}:
+			for _, x := range v {
+				walk(x)
+			}
+		case map[string]interface{}:
+			for _, x := range v {
+				walk(x)
+			}
+		}
+	}
+	for _, name := range names {
+		walk(vars[name])
+	}
+
+	return values
+}
+
+// withRedactedErrors is responsible for redacting the errors returned by the data sources and
+// resources of the provider, including those raised planning the diff and importing
+func withRedactedErrors(p *schema.Provider) *schema.Provider {
+	for _, resources := range []map[string]*schema.Resource{p.DataSourcesMap, p.ResourcesMap} {
+		for _, r := range resources {
+			r.Create = redactedFunc(r.Create)
+			r.Read = redactedFunc(r.Read)
+			r.Update = redactedFunc(r.Update)
+			r.Delete = redactedFunc(r.Delete)
+			if exists := r.Exists; exists != nil {
+				r.Exists = func(d *schema.ResourceData, meta interface{}) (bool, error) {
+					found, err := exists(d, meta)
+					return found, redactions.redactError(err)
+				}
+			}
+			if diff := r.CustomizeDiff; diff != nil {
+				r.CustomizeDiff = func(d *schema.ResourceDiff, meta interface{}) error {
+					return redactions.redactError(diff(d, meta))
+				}
+			}
+			if r.Importer != nil && r.Importer.State != nil {
+				state := r.Importer.State
+				r.Importer = &schema.ResourceImporter{
+					State: func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+						list, err := state(d, meta)
+						return list, redactions.redactError(err)
+					},
+				}
+			}
+		}
+	}
+
+	return p
+}
+
+// redactedFunc wraps the function to redact its error, a nil function being left nil
+func redactedFunc(fn func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	if fn == nil {
+		return nil
+	}
+
+	return func(d *schema.ResourceData, meta interface{}) error {
+		return redactions.redactError(fn(d, meta))
+	}
+}
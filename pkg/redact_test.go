@@ -0,0 +1,178 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestRedact(t *testing.T) {
+	patterns, err := compileRedactPatterns([]string{`password=(\S+)`, `AKIA[A-Z0-9]{4}`, `(user)=(\w+)?`})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r := &redactor{values: make(map[string]bool)}
+	r.addPatterns(patterns)
+	r.addPatterns(patterns[:1])
+	r.addValues("hunter2", "hunter2-extended", "")
+	if len(r.patterns) != 3 || len(r.values) != 2 {
+		t.Fatalf("unexpected redactor, patterns: %d, values: %d", len(r.patterns), len(r.values))
+	}
+	cases := map[string]string{
+		"nothing to see":                    "nothing to see",
+		"failed: hunter2-extended, hunter2": "failed: <redacted>, <redacted>",
+		"url?password=abc&x password=def":   "url?password=<redacted> password=<redacted>",
+		"key AKIAABCD used":                 "key <redacted> used",
+		"user=bob user=":                    "<redacted>=<redacted> <redacted>=",
+	}
+	for text, expected := range cases {
+		if got := r.redact(text); got != expected {
+			t.Errorf("text: %q, got: %q, want: %q", text, got, expected)
+		}
+	}
+	if err := r.redactError(errors.New("unchanged")); err.Error() != "unchanged" {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := r.redactError(errors.New("was hunter2")); err.Error() != "was <redacted>" {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if r.redactError(nil) != nil {
+		t.Error("expected a nil error to remain nil")
+	}
+	if _, err := compileRedactPatterns([]string{"("}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestRedactorWithValues(t *testing.T) {
+	r := &redactor{values: make(map[string]bool)}
+	patterns, _ := compileRedactPatterns([]string{`key-(\w+)`})
+	r.addPatterns(patterns)
+
+	scoped := r.withValues("hunter2")
+	if got := scoped.redact("hunter2 key-abc"); got != "<redacted> key-<redacted>" {
+		t.Errorf("unexpected scoped redaction: %q", got)
+	}
+	if got := r.redact("hunter2 key-abc"); got != "hunter2 key-<redacted>" {
+		t.Errorf("expected the values to be held by the scoped redactor only, got: %q", got)
+	}
+}
+
+func TestSensitiveValues(t *testing.T) {
+	vars := map[string]interface{}{
+		"password": "secret",
+		"keys":     []interface{}{"a", map[string]interface{}{"b": "apikey", "n": 10, "on": true}},
+		"public":   "visible",
+	}
+	values := sensitiveValues(vars, []string{"password", "keys", "missing"})
+	sort.Strings(values)
+	if expected := []string{"apikey", "secret"}; !reflect.DeepEqual(values, expected) {
+		t.Errorf("got: %v, want: %v", values, expected)
+	}
+}
+
+func TestWithRedactedErrors(t *testing.T) {
+	patterns, _ := compileRedactPatterns([]string{`token-(\w+)`})
+	original := redactions
+	defer func() { redactions = original }()
+	redactions = &redactor{values: make(map[string]bool)}
+	redactions.addPatterns(patterns)
+
+	failed := errors.New("render failed: token-abc")
+	p := withRedactedErrors(&schema.Provider{
+		ResourcesMap: map[string]*schema.Resource{
+			"test": {
+				CustomizeDiff: func(*schema.ResourceDiff, interface{}) error { return failed },
+				Importer: &schema.ResourceImporter{
+					State: func(*schema.ResourceData, interface{}) ([]*schema.ResourceData, error) { return nil, failed },
+				},
+			},
+		},
+	})
+	expected := "render failed: token-<redacted>"
+	if err := p.ResourcesMap["test"].CustomizeDiff(nil, nil); err == nil || err.Error() != expected {
+		t.Errorf("diff, got: %v, want: %s", err, expected)
+	}
+	if _, err := p.ResourcesMap["test"].Importer.State(nil, nil); err == nil || err.Error() != expected {
+		t.Errorf("import, got: %v, want: %s", err, expected)
+	}
+}
+
+func TestGoTemplateRedaction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template       = "#cloud-config\n{{ .password }}: true\n"
+						vars           = { password = "redact-me-please" }
+						sensitive_vars = ["password"]
+						validate       = "cloud-config"
+					}`,
+				ExpectError: regexp.MustCompile("unknown key: <redacted>, is it"),
+			},
+			{
+				Config: `
+					provider "gotemplate" {
+						redact_patterns = ["apikey-(\\w+)"]
+					}
+					data "gotemplate_file" "test" {
+						template = "#cloud-config\napikey-{{ .key }}: true\n"
+						vars     = { key = "abcdef" }
+						validate = "cloud-config"
+					}`,
+				ExpectError: regexp.MustCompile("unknown key: apikey-<redacted>, is it"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template       = "#cloud-config\n{{ .password }}: true\n"
+						vars           = { base = "s3cr3t", password = "{{ .base }}-derived" }
+						sensitive_vars = ["password"]
+						resolve_vars   = true
+						validate       = "cloud-config"
+					}`,
+				ExpectError: regexp.MustCompile("unknown key: <redacted>, is it"),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template    = "#cloud-config\n{{ .password }}: true\n"
+						secret_vars = { password = "redact-me-too" }
+						validate    = "cloud-config"
+					}`,
+				ExpectError: regexp.MustCompile("unknown key: <redacted>, is it"),
+			},
+			{
+				// the sensitive values of the earlier renders are not redacted from this one
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "#cloud-config\nredact-me-please: true\n"
+						validate = "cloud-config"
+					}`,
+				ExpectError: regexp.MustCompile("unknown key: redact-me-please, is it"),
+			},
+		},
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// registeredTemplate is a named template held by the registry
+type registeredTemplate struct {
+	// content is the template content
+	content string
+	// vars are the default variables, overridden by those of the referencing template
+	vars map[string]interface{}
+}
+
+// templateRegistry holds the named templates registered by the gotemplate_template data sources
+// of the provider instance, for other templates to reference by name
+type templateRegistry struct {
+	sync.Mutex
+	// entries is a map of the template name to the template
+	entries map[string]registeredTemplate
+}
+
+// newTemplateRegistry returns an empty registry
+func newTemplateRegistry() *templateRegistry {
+	return &templateRegistry{entries: make(map[string]registeredTemplate)}
+}
+
+// register adds the template under the name; registering the same template again is permitted,
+// as every read registers it, while registering a different template under the name is not
+func (r *templateRegistry) register(name string, template registeredTemplate) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if existing, found := r.entries[name]; found && !reflect.DeepEqual(existing, template) {
+		return fmt.Errorf("a different template is already registered as: %s", name)
+	}
+	r.entries[name] = template
+
+	return nil
+}
+
+// lookup returns the template registered under the name
+func (r *templateRegistry) lookup(name string) (registeredTemplate, error) {
+	if r == nil {
+		return registeredTemplate{}, fmt.Errorf("template %s is not registered, the provider holds no registry", name)
+	}
+	r.Lock()
+	defer r.Unlock()
+
+	template, found := r.entries[name]
+	if !found {
+		return registeredTemplate{}, fmt.Errorf("template %s is not registered, interpolate the name of the gotemplate_template so it is read first", name)
+	}
+
+	return template, nil
+}
+
+// registryOf returns the template registry from the provider configuration, if any
+func registryOf(meta interface{}) *templateRegistry {
+	if config, ok := meta.(*providerConfig); ok {
+		return config.templates
+	}
+
+	return nil
+}
+
+// refOf returns the name of the registered template referenced by the data, if any; only the data
+// sources hold a ref, as the apply of a resource renders again in a freshly configured provider
+// whose registry is empty, the gotemplate_template data sources having been read by the plan
+func refOf(d attributeGetter) string {
+	ref, _ := d.Get("ref").(string)
+
+	return ref
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateRegistry(t *testing.T) {
+	registry := newTemplateRegistry()
+	template := registeredTemplate{content: "{{ .name }}", vars: map[string]interface{}{"name": "web"}}
+	if err := registry.register("banner", template); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := registry.register("banner", template); err != nil {
+		t.Errorf("expected registering the same template again to succeed, got: %s", err)
+	}
+	if err := registry.register("banner", registeredTemplate{content: "other"}); err == nil || !strings.Contains(err.Error(), "already registered as: banner") {
+		t.Errorf("expected an error registering a different template, got: %v", err)
+	}
+	found, err := registry.lookup("banner")
+	if err != nil || found.content != "{{ .name }}" {
+		t.Errorf("unexpected lookup: %v, error: %v", found, err)
+	}
+	if _, err := registry.lookup("missing"); err == nil || !strings.Contains(err.Error(), "template missing is not registered") {
+		t.Errorf("expected an error for a missing template, got: %v", err)
+	}
+	var none *templateRegistry
+	if _, err := none.lookup("banner"); err == nil {
+		t.Error("expected an error without a registry")
+	}
+}
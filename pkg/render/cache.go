@@ -0,0 +1,140 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SnippetCache holds the parsed files of the snippet directories, shared by the renderers of a
+// provider instance so a directory used by many templates is parsed once; an entry is reused for
+// as long as the files of the directory, their sizes and modification times are unchanged
+type SnippetCache struct {
+	sync.Mutex
+	// entries is a map of the cache key to the parsed directory
+	entries map[snippetCacheKey]snippetCacheEntry
+	// hits and misses count the loads served from the cache and those parsed
+	hits, misses int
+}
+
+// snippetCacheKey identifies a directory parsed with the options which affect the parse
+type snippetCacheKey struct {
+	dir            string
+	left, right    string
+	funcs          string
+	trimBlocks     bool
+	lstripBlocks   bool
+	stripComments  bool
+	legacyNames    bool
+	followSymlinks bool
+	includeHidden  bool
+	skipOversized  bool
+	maxSize        int64
+}
+
+// snippetCacheEntry is a parsed directory
+type snippetCacheEntry struct {
+	// fingerprint describes the files of the directory when parsed
+	fingerprint string
+	// files are the parsed files
+	files []*snippetFile
+}
+
+// NewSnippetCache returns an empty snippet cache
+func NewSnippetCache() *SnippetCache {
+	return &SnippetCache{entries: make(map[snippetCacheKey]snippetCacheEntry)}
+}
+
+// Stats returns the number of loads served from the cache and the number parsed
+func (c *SnippetCache) Stats() (int, int) {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.hits, c.misses
+}
+
+// load returns the parsed files of the directory, parsing them when not cached or when the files
+// have changed since; the parse runs outside the lock, so concurrent misses may parse twice
+func (c *SnippetCache) load(dir string, key snippetCacheKey, walk WalkOptions, parse func() ([]*snippetFile, error)) ([]*snippetFile, error) {
+	fingerprint, err := fingerprintDir(dir, walk)
+	if err != nil {
+		return nil, err
+	}
+	c.Lock()
+	entry, found := c.entries[key]
+	if found && entry.fingerprint == fingerprint {
+		c.hits++
+		c.Unlock()
+		return entry.files, nil
+	}
+	c.misses++
+	c.Unlock()
+
+	files, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	c.Lock()
+	c.entries[key] = snippetCacheEntry{fingerprint: fingerprint, files: files}
+	c.Unlock()
+
+	return files, nil
+}
+
+// fingerprintDir describes the files of the directory by their path, size and modification time
+func fingerprintDir(dir string, walk WalkOptions) (string, error) {
+	var lines []string
+	err := WalkFiles(dir, walk, func(path, relative string) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%s:%d:%d", relative, info.Size(), info.ModTime().UnixNano()))
+		return nil
+	})
+
+	return strings.Join(lines, "\n"), err
+}
+
+// cacheKey returns the cache key of the directory for the options of the renderer
+func (r *Renderer) cacheKey(dir string) snippetCacheKey {
+	left, right := r.delims()
+	funcs := r.funcs()
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return snippetCacheKey{
+		dir:            dir,
+		left:           left,
+		right:          right,
+		funcs:          strings.Join(names, ","),
+		trimBlocks:     r.options.TrimBlocks,
+		lstripBlocks:   r.options.LstripBlocks,
+		stripComments:  r.options.StripComments,
+		legacyNames:    r.options.LegacySnippetNames,
+		followSymlinks: r.options.FollowSymlinks,
+		includeHidden:  r.options.IncludeHidden,
+		skipOversized:  r.options.SkipOversized,
+		maxSize:        r.options.MaxSnippetSize,
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnippetCache(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"greeting.tmpl": `{{ define "greeting" }}Hello {{ .name }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	cache := NewSnippetCache()
+	render := func(options Options) string {
+		options.Snippets, options.SnippetCache = dir, cache
+		rendered, err := New(options).Render(`{{ template "greeting" . }}`, map[string]interface{}{"name": "web"})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return rendered
+	}
+	for i := 0; i < 3; i++ {
+		if rendered := render(Options{}); rendered != "Hello web" {
+			t.Errorf("render %d, got: %q", i, rendered)
+		}
+	}
+	if hits, misses := cache.Stats(); hits != 2 || misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got: %d, %d", hits, misses)
+	}
+
+	// step: options affecting the parse are cached separately
+	render(Options{TrimBlocks: true})
+	if _, misses := cache.Stats(); misses != 2 {
+		t.Errorf("expected a miss for different options, got: %d", misses)
+	}
+
+	// step: the trace instruments a copy of the cached trees
+	render(Options{Trace: NewTrace()})
+	if rendered := render(Options{}); rendered != "Hello web" {
+		t.Errorf("unexpected render after a trace: %q", rendered)
+	}
+
+	// step: a changed file is parsed again
+	path := filepath.Join(dir, "greeting.tmpl")
+	if err := ioutil.WriteFile(path, []byte(`{{ define "greeting" }}Goodbye {{ .name }}{{ end }}`), 0644); err != nil {
+		t.Fatalf("unable to write file: %s", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("unable to change the modification time: %s", err)
+	}
+	if rendered := render(Options{}); rendered != "Goodbye web" {
+		t.Errorf("expected the changed snippet, got: %q", rendered)
+	}
+}
+
+func TestSnippetCacheWarnings(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{"large.tmpl": "0123456789"})
+	defer os.RemoveAll(dir)
+
+	cache := NewSnippetCache()
+	for i := 0; i < 2; i++ {
+		warnings := NewWarnings()
+		options := Options{Snippets: dir, SnippetCache: cache, MaxSnippetSize: 5, SkipOversized: true, Warnings: warnings}
+		if _, err := New(options).Render("", nil); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(warnings.Entries()) != 1 {
+			t.Errorf("render %d, expected the skipped snippet warning, got: %v", i, warnings.Entries())
+		}
+	}
+	if hits, _ := cache.Stats(); hits != 1 {
+		t.Errorf("expected the second render to hit the cache, got: %d", hits)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// stripComments is responsible for removing the comment actions from the content; a comment which
+// is alone on its line is removed along with the line, so documented templates do not leave blank
+// lines in the output
+func stripComments(content, left, right string) string {
+	var out strings.Builder
+	for {
+		start := strings.Index(content, left)
+		if start < 0 {
+			out.WriteString(content)
+			break
+		}
+		end := actionEnd(content, start, left, right)
+		if end < 0 {
+			// an unterminated action is left for the parser to report
+			out.WriteString(content)
+			break
+		}
+		text, action := content[:start], content[start:end]
+		content = content[end:]
+		if !isComment(action, left) {
+			out.WriteString(text)
+			out.WriteString(action)
+			continue
+		}
+		// step: drop the line when nothing but whitespace surrounds the comment
+		out.WriteString(text)
+		written := out.String()
+		line := strings.LastIndex(written, "\n") + 1
+		rest := len(content)
+		if index := strings.Index(content, "\n"); index >= 0 {
+			rest = index + 1
+		}
+		if strings.Trim(written[line:], " \t") == "" && strings.Trim(content[:rest], " \t\r\n") == "" {
+			out.Reset()
+			out.WriteString(written[:line])
+			content = content[rest:]
+		}
+	}
+
+	return out.String()
+}
+
+// isComment checks if the action is a comment
+func isComment(action, left string) bool {
+	inner := strings.TrimPrefix(action, left)
+	inner = strings.TrimLeft(strings.TrimPrefix(inner, "-"), " \t\r\n")
+
+	return strings.HasPrefix(inner, "/*")
+}
+
+// commentFilter is a writer removing the lines of output whose first non-blank characters are the
+// prefix, buffering the partial line until it is complete
+type commentFilter struct {
+	w       io.Writer
+	prefix  string
+	pending []byte
+}
+
+// Write is responsible for passing on the complete lines not marked as comments
+func (c *commentFilter) Write(p []byte) (int, error) {
+	c.pending = append(c.pending, p...)
+	for {
+		index := bytes.IndexByte(c.pending, '\n')
+		if index < 0 {
+			break
+		}
+		line := c.pending[:index+1]
+		c.pending = c.pending[index+1:]
+		if err := c.emit(line); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush is responsible for passing on the final unterminated line
+func (c *commentFilter) Flush() error {
+	line := c.pending
+	c.pending = nil
+
+	return c.emit(line)
+}
+
+// emit writes the line unless it is a comment
+func (c *commentFilter) emit(line []byte) error {
+	if len(line) == 0 || bytes.HasPrefix(bytes.TrimLeft(line, " \t"), []byte(c.prefix)) {
+		return nil
+	}
+	_, err := c.w.Write(line)
+
+	return err
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripComments(t *testing.T) {
+	cases := []struct {
+		Content  string
+		Expected string
+	}{
+		{Content: "{{/* header */}}\nvalue\n", Expected: "value\n"},
+		{Content: "  {{- /* indented */ -}}  \r\nvalue\n", Expected: "value\n"},
+		{Content: "a\n{{/* a comment\nover lines with }} */}}\nb", Expected: "a\nb"},
+		{Content: "key: {{ .name }} {{/* trailing */}}\n", Expected: "key: {{ .name }} \n"},
+		{Content: "{{/* the last line */}}", Expected: ""},
+		{Content: "{{ \"{{/* quoted */}}\" }}\n", Expected: "{{ \"{{/* quoted */}}\" }}\n"},
+	}
+	for i, x := range cases {
+		if got := stripComments(x.Content, "{{", "}}"); got != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, got, x.Expected)
+		}
+	}
+}
+
+func TestRenderStripComments(t *testing.T) {
+	content := "#cloud-config\n{{/* the users */}}\n## generated from users.tmpl\nusers:\n  ## each user\n  - {{ .name }}\n## end"
+	cases := []struct {
+		Options  Options
+		Expected string
+	}{
+		{Expected: "#cloud-config\n\n## generated from users.tmpl\nusers:\n  ## each user\n  - web\n## end"},
+		{Options: Options{StripComments: true}, Expected: "#cloud-config\n## generated from users.tmpl\nusers:\n  ## each user\n  - web\n## end"},
+		{Options: Options{StripComments: true, CommentPrefix: "##"}, Expected: "#cloud-config\nusers:\n  - web\n"},
+	}
+	for i, x := range cases {
+		rendered, err := New(x.Options).Render(content, map[string]interface{}{"name": "web"})
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered, x.Expected)
+		}
+	}
+}
+
+func TestCommentFilterWriteError(t *testing.T) {
+	w := &failingWriter{limit: 2}
+	filter := &commentFilter{w: w, prefix: "#"}
+	if _, err := filter.Write([]byte("# skipped\n")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := filter.Write([]byte("kept\n")); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected the writer error, got: %v", err)
+	}
+}
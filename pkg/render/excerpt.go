@@ -0,0 +1,84 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// excerptContext is the number of lines shown ahead of the failing line
+const excerptContext = 2
+
+// executionLocation matches the location of the failing action in an execution error, i.e.
+// template: base:3:12: executing "base" at <.a.b>: ...
+var executionLocation = regexp.MustCompile(`template: (.+?):(\d+):(\d+): executing`)
+
+// excerpt returns an excerpt of the template text around the action which failed the execution,
+// with a caret under the action, or an empty string when the location or text is unknown
+func (r *Renderer) excerpt(tmpl *template.Template, err error) string {
+	match := executionLocation.FindStringSubmatch(err.Error())
+	if match == nil {
+		return ""
+	}
+	r.Lock()
+	text := r.sources[tmpl][match[1]]
+	r.Unlock()
+	if text == "" {
+		return ""
+	}
+	line, _ := strconv.Atoi(match[2])
+	column, _ := strconv.Atoi(match[3])
+
+	return formatExcerpt(match[1], text, line, column)
+}
+
+// formatExcerpt formats the lines leading up to and including the line, followed by a caret under
+// the column; the column is the byte offset into the line
+func formatExcerpt(name, text string, line, column int) string {
+	lines := strings.Split(text, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first := line - excerptContext
+	if first < 1 {
+		first = 1
+	}
+	width := len(strconv.Itoa(line))
+
+	out := new(strings.Builder)
+	fmt.Fprintf(out, "\n\non %s line %d:\n", name, line)
+	for i := first; i <= line; i++ {
+		fmt.Fprintf(out, "  %*d | %s\n", width, i, strings.TrimRight(lines[i-1], "\r"))
+	}
+	// step: keep any tabs ahead of the column so the caret lines up with the action
+	current := lines[line-1]
+	if column > len(current) {
+		column = len(current)
+	}
+	padding := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, current[:column])
+	fmt.Fprintf(out, "  %*s | %s^", width, "", padding)
+
+	return out.String()
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormatExcerpt(t *testing.T) {
+	text := "one\ntwo\n\tkey: {{ .a.b }}\nfour"
+	expected := "\n\non base line 3:\n  1 | one\n  2 | two\n  3 | \tkey: {{ .a.b }}\n    | \t        ^"
+	if got := formatExcerpt("base", text, 3, 9); got != expected {
+		t.Errorf("got: %q, want: %q", got, expected)
+	}
+	if got := formatExcerpt("base", "{{ x }}", 1, 3); got != "\n\non base line 1:\n  1 | {{ x }}\n    |    ^" {
+		t.Errorf("unexpected excerpt: %q", got)
+	}
+	if got := formatExcerpt("base", text, 10, 1); got != "" {
+		t.Errorf("expected no excerpt for a line out of range, got: %q", got)
+	}
+}
+
+func TestExecuteExcerpt(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"nested/user.tmpl": "{{ define \"user\" }}\nname: {{ .name }}\nhome: {{ .home.dir }}\n{{ end }}",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir}).Render("users:\n{{ template \"user\" . }}", map[string]interface{}{"name": "web", "home": "/home"})
+	if err == nil {
+		t.Fatal("expected an execution error")
+	}
+	expected := "\n\non nested/user.tmpl line 3:\n  1 | {{ define \"user\" }}\n  2 | name: {{ .name }}\n  3 | home: {{ .home.dir }}\n    |               ^"
+	if !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	_, err = New(Options{Strict: true}).Render("a\n{{ .missing }}", map[string]interface{}{})
+	if err == nil || !strings.HasSuffix(err.Error(), "on base line 2:\n  1 | a\n  2 | {{ .missing }}\n    |    ^") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
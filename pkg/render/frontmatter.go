@@ -0,0 +1,108 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+// Engine is the name of the template engine, as may be declared in the frontmatter
+const Engine = "gotemplate"
+
+// frontmatterSettings are the settings permitted in the frontmatter
+var frontmatterSettings = []string{"defaults", "delimiters", "engine", "required"}
+
+// Frontmatter are the settings a template may declare in a leading yaml block delimited by ---
+type Frontmatter struct {
+	// Engine is the template engine the template is written for
+	Engine string `yaml:"engine"`
+	// Required are the dotted paths of the variables which must be supplied
+	Required []string `yaml:"required"`
+	// Delimiters are the left and right action delimiters
+	Delimiters []string `yaml:"delimiters"`
+	// Defaults are the values of the variables when not supplied
+	Defaults map[string]interface{} `yaml:"defaults"`
+}
+
+// ParseFrontmatter is responsible for splitting the frontmatter from the content, returning the
+// settings and the remaining template; content without frontmatter is returned unchanged
+func ParseFrontmatter(content string) (*Frontmatter, string, error) {
+	frontmatter := &Frontmatter{}
+	normalized := strings.Replace(content, "\r\n", "\n", -1)
+	if !strings.HasPrefix(normalized, "---\n") {
+		return frontmatter, content, nil
+	}
+	lines := strings.SplitAfter(normalized[4:], "\n")
+	for i, line := range lines {
+		if strings.TrimRight(line, "\n") != "---" {
+			continue
+		}
+		document := []byte(strings.Join(lines[:i], ""))
+		// step: reject unknown settings, which are most likely typos
+		settings := make(map[string]interface{})
+		if err := yaml.Unmarshal(document, &settings); err != nil {
+			return nil, "", fmt.Errorf("invalid frontmatter, error: %s", err)
+		}
+		for k := range settings {
+			if !containsString(frontmatterSettings, k) {
+				return nil, "", fmt.Errorf("invalid frontmatter, unknown setting: %s", k)
+			}
+		}
+		if err := yaml.Unmarshal(document, frontmatter); err != nil {
+			return nil, "", fmt.Errorf("invalid frontmatter, error: %s", err)
+		}
+		if defaults, ok := values.Normalize(frontmatter.Defaults).(map[string]interface{}); ok {
+			frontmatter.Defaults = defaults
+		}
+		if err := frontmatter.validate(); err != nil {
+			return nil, "", fmt.Errorf("invalid frontmatter, error: %s", err)
+		}
+		return frontmatter, strings.Join(lines[i+1:], ""), nil
+	}
+
+	return nil, "", fmt.Errorf("invalid frontmatter, the closing --- was not found")
+}
+
+// Apply is responsible for applying the settings to the options and vars, returning the vars
+// merged over the defaults; an error is returned if any of the required variables are missing
+func (f *Frontmatter) Apply(options *Options, vars map[string]interface{}) (map[string]interface{}, error) {
+	if len(f.Delimiters) == 2 {
+		options.LeftDelim, options.RightDelim = f.Delimiters[0], f.Delimiters[1]
+	}
+	merged := values.Merge(f.Defaults, vars)
+	if missing := Missing(f.Required, merged); len(missing) > 0 {
+		return nil, fmt.Errorf("the template requires the variables: %s", strings.Join(missing, ", "))
+	}
+
+	return merged, nil
+}
+
+// validate checks the settings are usable
+func (f *Frontmatter) validate() error {
+	if f.Engine != "" && f.Engine != Engine {
+		return fmt.Errorf("unsupported engine: %q, expected: %s", f.Engine, Engine)
+	}
+	if f.Delimiters != nil && (len(f.Delimiters) != 2 || f.Delimiters[0] == "" || f.Delimiters[1] == "") {
+		return fmt.Errorf("delimiters must be a list of the left and right delimiter")
+	}
+
+	return nil
+}
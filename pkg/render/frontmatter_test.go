@@ -0,0 +1,86 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	content := "---\nengine: gotemplate\nrequired: [name, image.tag]\ndelimiters: ['[[', ']]']\ndefaults:\n  image:\n    name: nginx\n---\nimage: [[ .image.name ]]:[[ .image.tag ]]\n"
+	frontmatter, body, err := ParseFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if body != "image: [[ .image.name ]]:[[ .image.tag ]]\n" {
+		t.Errorf("unexpected body: %q", body)
+	}
+	expected := &Frontmatter{
+		Engine:     "gotemplate",
+		Required:   []string{"name", "image.tag"},
+		Delimiters: []string{"[[", "]]"},
+		Defaults:   map[string]interface{}{"image": map[string]interface{}{"name": "nginx"}},
+	}
+	if !reflect.DeepEqual(frontmatter, expected) {
+		t.Errorf("got: %#v, want: %#v", frontmatter, expected)
+	}
+
+	options := Options{}
+	vars, err := frontmatter.Apply(&options, map[string]interface{}{
+		"name":  "web",
+		"image": map[string]interface{}{"tag": "1.0"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rendered, err := New(options).Render(body, vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "image: nginx:1.0\n" {
+		t.Errorf("unexpected render: %q", rendered)
+	}
+	if _, err := frontmatter.Apply(&options, map[string]interface{}{"name": "web"}); err == nil || !strings.Contains(err.Error(), "image.tag") {
+		t.Errorf("expected a missing variable error, got: %v", err)
+	}
+}
+
+func TestParseFrontmatterNone(t *testing.T) {
+	frontmatter, body, err := ParseFrontmatter("kind: ConfigMap\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if body != "kind: ConfigMap\n" || !reflect.DeepEqual(frontmatter, &Frontmatter{}) {
+		t.Errorf("expected the content to be unchanged, got: %q", body)
+	}
+}
+
+func TestParseFrontmatterErrors(t *testing.T) {
+	cases := map[string]string{
+		"---\nengine: jinja\n---\n":        "unsupported engine",
+		"---\nrequird: [a]\n---\n":         "unknown setting: requird",
+		"---\ndelimiters: ['<<']\n---\n":   "delimiters must be a list",
+		"---\nengine: gotemplate\n":        "closing --- was not found",
+		"---\nrequired: {a: b}\n---\nbody": "invalid frontmatter",
+	}
+	for content, expected := range cases {
+		if _, _, err := ParseFrontmatter(content); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("content: %q, expected error containing %q, got: %v", content, expected, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// descriptions is the registry of the one line description of each template function
+var descriptions = map[string]string{
+	"chunklist":         "splits the list into lists of at most the size, as terraform's chunklist",
+	"element":           "returns the element at the index of the list, wrapping around, as terraform's element",
+	"empty":             "checks if the string is empty",
+	"flatten":           "converts nested maps and lists into a map keyed by the dotted path of each leaf",
+	"format":            "formats the values according to the format, as terraform's format",
+	"formatlist":        "formats each element of the lists, returning a list, as terraform's formatlist",
+	"fromHcl":           "parses the hcl2 string into maps and lists, labelled blocks nested under their type and labels",
+	"fromIni":           "parses the ini content into a map of the top level keys and a map of each section",
+	"fromProperties":    "parses java properties into a map of strings",
+	"isFalse":           "checks if the string is 0, false or False",
+	"isTrue":            "checks if the string is 1, true or True",
+	"is_false":          "deprecated, always returns false, use isFalse",
+	"is_true":           "deprecated, use isTrue",
+	"join":              "joins the lists with the separator, as terraform's join(separator, lists...), or join(list, separator)",
+	"keys":              "returns the keys of the map",
+	"lookup":            "returns the value of the key in the map or the default, as terraform's lookup",
+	"lower":             "converts the string to lowercase",
+	"mergeWithStrategy": "deep merges the maps using the strategy: override, append-lists or fail-on-conflict",
+	"resourceQuantity":  "normalizes a kubernetes resource quantity, i.e. 1.5Gi or 500m",
+	"sanitizeDNS1123":   "converts the string into a valid dns-1123 label",
+	"split":             "splits the string on the delimiter",
+	"toIni":             "encodes the map as ini, nested maps becoming sections",
+	"toK8sEnvList":      "converts the map into a sorted list of kubernetes name and value env entries",
+	"toK8sLabels":       "converts the map into valid kubernetes labels",
+	"toPaths":           "returns the sorted dotted paths of the leaves of nested maps and lists",
+	"toProperties":      "encodes the map as java properties, nested maps becoming dotted keys",
+	"unflatten":         "converts a map keyed by dotted paths back into nested maps",
+	"upper":             "converts the string to uppercase",
+	"values":            "returns the values of the map",
+	"zipmap":            "builds a map from a list of keys and a list of values, as terraform's zipmap",
+}
+
+// Funcs returns the template functions we support
+func Funcs() template.FuncMap {
+	funcs := template.FuncMap{
+		"upper": func(s string) string {
+			return strings.ToUpper(s)
+		},
+		"lower": func(s string) string {
+			return strings.ToLower(s)
+		},
+		"split": func(s, delim string) []string {
+			return strings.Split(s, delim)
+		},
+		"empty": func(s string) bool {
+			return s == ""
+		},
+		"keys": func(m map[string]interface{}) []string {
+			var keys []string
+			for k := range m {
+				keys = append(keys, k)
+			}
+			return keys
+		},
+		"is_true": isTrue,
+		"is_false": func(s string) bool {
+			if s == "0" || s == "false" || s == "False" {
+				return false
+			}
+			return false
+		},
+		"isTrue": isTrue,
+		"isFalse": func(s string) bool {
+			return s == "0" || s == "false" || s == "False"
+		},
+		"values": func(m map[string]interface{}) []interface{} {
+			var values []interface{}
+			for _, v := range m {
+				values = append(values, v)
+			}
+			return values
+		},
+	}
+	for _, x := range []template.FuncMap{hclFuncs(), iniFuncs(), kubernetesFuncs(), structureFuncs(), terraformFuncs()} {
+		for name, fn := range x {
+			funcs[name] = fn
+		}
+	}
+
+	return funcs
+}
+
+// isTrue checks if the string is 1, true or True
+func isTrue(s string) bool {
+	return s == "1" || s == "true" || s == "True"
+}
+
+// Signatures returns a map of the function name to its signature
+func Signatures(funcs template.FuncMap) map[string]string {
+	signatures := make(map[string]string, len(funcs))
+	for name, fn := range funcs {
+		signatures[name] = reflect.TypeOf(fn).String()
+	}
+
+	return signatures
+}
+
+// Descriptions returns a map of the function name to its one line description, for each of the
+// functions which has one
+func Descriptions(funcs template.FuncMap) map[string]string {
+	described := make(map[string]string, len(funcs))
+	for name := range funcs {
+		if description, found := descriptions[name]; found {
+			described[name] = description
+		}
+	}
+
+	return described
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+// hclFuncs are helpers for feeding hcl fragments into the templates as data
+func hclFuncs() template.FuncMap {
+	return template.FuncMap{
+		"fromHcl": fromHcl,
+	}
+}
+
+// fromHcl parses the hcl2 content into nested maps and lists. Attributes become keys of the body;
+// a labelled block is nested under its type and each of its labels, i.e. resource "a" "b" {} is
+// found at .resource.a.b, while unlabelled blocks are a list under their type so may repeat. An
+// expression which cannot be evaluated as a literal, such as a reference to var.name, is kept as
+// its source text
+func fromHcl(content string) (map[string]interface{}, error) {
+	src := []byte(content)
+	file, diags := hclsyntax.ParseConfig(src, "fromHcl", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("unable to parse the hcl, error: %s", diags)
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unable to parse the hcl, unexpected body: %T", file.Body)
+	}
+
+	return hclBody(src, body)
+}
+
+// hclBody converts the attributes and blocks of the body
+func hclBody(src []byte, body *hclsyntax.Body) (map[string]interface{}, error) {
+	converted := make(map[string]interface{}, len(body.Attributes)+len(body.Blocks))
+	// labelled is the set of paths, joined by a nul, of the maps holding the labels of blocks
+	labelled := make(map[string]bool)
+	for name, x := range body.Attributes {
+		value, diags := x.Expr.Value(nil)
+		if diags.HasErrors() {
+			converted[name] = string(x.Expr.Range().SliceBytes(src))
+			continue
+		}
+		converted[name] = values.FromCty(value)
+	}
+
+	for _, x := range body.Blocks {
+		nested, err := hclBody(src, x.Body)
+		if err != nil {
+			return nil, err
+		}
+		if _, found := body.Attributes[x.Type]; found {
+			return nil, fmt.Errorf("%s is both an attribute and a block at %s", x.Type, x.TypeRange)
+		}
+		if len(x.Labels) == 0 {
+			list, _ := converted[x.Type].([]interface{})
+			if _, found := converted[x.Type]; found && list == nil {
+				return nil, fmt.Errorf("block %s at %s conflicts with another block", x.Type, x.TypeRange)
+			}
+			converted[x.Type] = append(list, nested)
+			continue
+		}
+
+		// step: walk down the labels, creating the maps as required
+		parent := converted
+		keys := append([]string{x.Type}, x.Labels...)
+		for i, key := range keys[:len(keys)-1] {
+			path := strings.Join(keys[:i+1], "\x00")
+			if _, found := parent[key]; !found {
+				parent[key] = make(map[string]interface{})
+				labelled[path] = true
+			}
+			child, ok := parent[key].(map[string]interface{})
+			if !ok || !labelled[path] {
+				return nil, fmt.Errorf("block %s at %s conflicts with another block", x.Type, x.TypeRange)
+			}
+			parent = child
+		}
+		last := keys[len(keys)-1]
+		if _, found := parent[last]; found {
+			return nil, fmt.Errorf("duplicate block %s %q at %s", x.Type, x.Labels, x.TypeRange)
+		}
+		parent[last] = nested
+	}
+
+	return converted, nil
+}
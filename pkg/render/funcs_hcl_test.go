@@ -0,0 +1,80 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromHcl(t *testing.T) {
+	content := `
+name     = "web"
+replicas = 3
+ratio    = 1.5
+enabled  = true
+zones    = ["a", "b"]
+tags     = { env = "prod" }
+subnet   = var.subnet_id
+
+resource "aws_instance" "web" {
+  ami = "ami-123"
+  ingress {
+    port = 80
+  }
+  ingress {
+    port = 443
+  }
+}
+`
+	cases := []struct {
+		Content  string
+		Expected string
+	}{
+		{Content: `{{ $h := fromHcl .hcl }}{{ $h.name }} {{ $h.replicas }} {{ $h.ratio }} {{ $h.enabled }}`, Expected: "web 3 1.5 true"},
+		{Content: `{{ $h := fromHcl .hcl }}{{ join "," $h.zones }} {{ $h.tags.env }} {{ $h.subnet }}`, Expected: "a,b prod var.subnet_id"},
+		{Content: `{{ $r := (fromHcl .hcl).resource.aws_instance.web }}{{ $r.ami }}{{ range $r.ingress }} {{ .port }}{{ end }}`, Expected: "ami-123 80 443"},
+	}
+	for i, x := range cases {
+		rendered, err := New(Options{}).Render(x.Content, map[string]interface{}{"hcl": content})
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered, x.Expected)
+		}
+	}
+}
+
+func TestFromHclErrors(t *testing.T) {
+	cases := []struct {
+		Content string
+		Error   string
+	}{
+		{Content: `name = `, Error: "unable to parse the hcl"},
+		{Content: "a \"b\" {}\na \"b\" {}", Error: "duplicate block a"},
+		{Content: "a \"b\" {}\na {}", Error: "conflicts with another block"},
+		{Content: "a {}\na \"b\" {}", Error: "conflicts with another block"},
+		{Content: "a \"b\" {}\na \"b\" \"c\" {}", Error: "conflicts with another block"},
+		{Content: "a = 1\na {}", Error: "both an attribute and a block"},
+	}
+	for i, x := range cases {
+		if _, err := fromHcl(x.Content); err == nil || !strings.Contains(err.Error(), x.Error) {
+			t.Errorf("case %d, expected error: %q, got: %v", i, x.Error, err)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// iniFuncs are helpers for the ini and java properties formats of legacy services
+func iniFuncs() template.FuncMap {
+	return template.FuncMap{
+		"fromIni":        fromIni,
+		"fromProperties": fromProperties,
+		"toIni":          toIni,
+		"toProperties":   toProperties,
+	}
+}
+
+// fromIni parses the ini content into a map of the keys before the first section and a map of
+// each section; lines starting with a semicolon or hash are comments, a section repeated later in
+// the content is merged and a double quoted value is unquoted
+func fromIni(content string) (map[string]interface{}, error) {
+	parsed := make(map[string]interface{})
+	section := parsed
+	for i, line := range strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section: %q", i+1, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, found := parsed[name]; !found {
+				parsed[name] = make(map[string]interface{})
+			}
+			existing, ok := parsed[name].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("line %d: section %s conflicts with a key of the same name", i+1, name)
+			}
+			section = existing
+			continue
+		}
+
+		index := strings.Index(line, "=")
+		if index <= 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+		key, value := strings.TrimSpace(line[:index]), strings.TrimSpace(line[index+1:])
+		if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value: %s", i+1, value)
+			}
+			value = unquoted
+		}
+		section[key] = value
+	}
+
+	return parsed, nil
+}
+
+// toIni encodes the map as ini, the values which are maps becoming sections after the top level
+// keys; keys are sorted so the output is stable and values needing it are double quoted
+func toIni(v interface{}) (string, error) {
+	m, ok := generic(reflect.ValueOf(v)).(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("toIni expects a map, got: %T", v)
+	}
+
+	var globals, sections []string
+	for k, x := range m {
+		if _, ok := x.(map[string]interface{}); ok {
+			sections = append(sections, k)
+		} else {
+			globals = append(globals, k)
+		}
+	}
+	sort.Strings(globals)
+	sort.Strings(sections)
+
+	encoded := new(strings.Builder)
+	if err := writeIniKeys(encoded, "", globals, m); err != nil {
+		return "", err
+	}
+	for i, name := range sections {
+		if i > 0 || len(globals) > 0 {
+			encoded.WriteString("\n")
+		}
+		fmt.Fprintf(encoded, "[%s]\n", name)
+
+		section := m[name].(map[string]interface{})
+		var keys []string
+		for k := range section {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if err := writeIniKeys(encoded, name+".", keys, section); err != nil {
+			return "", err
+		}
+	}
+
+	return encoded.String(), nil
+}
+
+// writeIniKeys writes the key = value lines of the keys of the map
+func writeIniKeys(encoded *strings.Builder, prefix string, keys []string, m map[string]interface{}) error {
+	for _, k := range keys {
+		value, err := scalarString(m[k])
+		if err != nil {
+			return fmt.Errorf("toIni, key: %s%s, %s", prefix, k, err)
+		}
+		if value != strings.TrimSpace(value) || strings.ContainsAny(value, "\"\n\r;#") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(encoded, "%s = %s\n", k, value)
+	}
+
+	return nil
+}
+
+// fromProperties parses java properties into a flat map of strings; keys are separated from
+// their values by an equals, colon or whitespace, lines starting with a hash or exclamation mark
+// are comments, a trailing backslash continues the line and the java escape sequences, including
+// \uXXXX, are expanded
+func fromProperties(content string) (map[string]interface{}, error) {
+	parsed := make(map[string]interface{})
+	lines := strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		number, line := i+1, strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		// step: join the continued lines, the leading whitespace of each being dropped
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, value := splitProperty(line)
+		k, err := unescapeProperty(key)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", number, err)
+		}
+		v, err := unescapeProperty(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", number, err)
+		}
+		parsed[k] = v
+	}
+
+	return parsed, nil
+}
+
+// continued checks if the line ends in an odd number of backslashes, so continues on the next
+func continued(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+
+	return count%2 == 1
+}
+
+// splitProperty splits the line at the first unescaped separator, being an equals, colon or
+// whitespace, the whitespace around the separator being dropped
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			value := strings.TrimLeft(line[i:], " \t\f")
+			if strings.HasPrefix(value, "=") || strings.HasPrefix(value, ":") {
+				value = strings.TrimLeft(value[1:], " \t\f")
+			}
+			return line[:i], value
+		}
+	}
+
+	return line, ""
+}
+
+// unescapeProperty expands the escape sequences of a key or value
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	unescaped := new(strings.Builder)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			unescaped.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			unescaped.WriteByte('\t')
+		case 'n':
+			unescaped.WriteByte('\n')
+		case 'r':
+			unescaped.WriteByte('\r')
+		case 'f':
+			unescaped.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape: %s", s[i-1:])
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape: %s", s[i-1:i+5])
+			}
+			unescaped.WriteRune(rune(code))
+			i += 4
+		default:
+			unescaped.WriteByte(s[i])
+		}
+	}
+
+	return unescaped.String(), nil
+}
+
+// toProperties encodes the map as java properties, nested maps being flattened into dotted keys;
+// keys are sorted so the output is stable, and the keys and values escaped as java would
+func toProperties(v interface{}) (string, error) {
+	m, ok := generic(reflect.ValueOf(v)).(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("toProperties expects a map, got: %T", v)
+	}
+	flattened := make(map[string]string)
+	if err := flattenProperties(flattened, "", m); err != nil {
+		return "", err
+	}
+	var keys []string
+	for k := range flattened {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	encoded := new(strings.Builder)
+	for _, k := range keys {
+		fmt.Fprintf(encoded, "%s=%s\n", escapeProperty(k, true), escapeProperty(flattened[k], false))
+	}
+
+	return encoded.String(), nil
+}
+
+// flattenProperties adds the values of the map to the flattened properties under the prefix
+func flattenProperties(flattened map[string]string, prefix string, m map[string]interface{}) error {
+	for k, x := range m {
+		if nested, ok := x.(map[string]interface{}); ok {
+			if err := flattenProperties(flattened, prefix+k+".", nested); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := scalarString(x)
+		if err != nil {
+			return fmt.Errorf("toProperties, key: %s%s, %s", prefix, k, err)
+		}
+		flattened[prefix+k] = value
+	}
+
+	return nil
+}
+
+// escapeProperty escapes the key or value; the separators and comment characters only need
+// escaping in a key, while leading whitespace is significant in both
+func escapeProperty(s string, key bool) string {
+	escaped := new(strings.Builder)
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			escaped.WriteString(`\\`)
+		case r == '\t':
+			escaped.WriteString(`\t`)
+		case r == '\n':
+			escaped.WriteString(`\n`)
+		case r == '\r':
+			escaped.WriteString(`\r`)
+		case r == '\f':
+			escaped.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			escaped.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r):
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < 0x20:
+			fmt.Fprintf(escaped, `\u%04x`, r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+
+	return escaped.String()
+}
+
+// scalarString formats a value which is neither a map nor a list, nil being empty
+func scalarString(v interface{}) (string, error) {
+	switch v.(type) {
+	case nil:
+		return "", nil
+	case map[string]interface{}, []interface{}:
+		return "", fmt.Errorf("expected a scalar value, got: %T", v)
+	}
+
+	return fmt.Sprintf("%v", v), nil
+}
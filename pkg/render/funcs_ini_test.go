@@ -0,0 +1,166 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromIni(t *testing.T) {
+	content := `; global settings
+name = web
+
+[database]
+host = db.local
+port = 5432
+# a comment
+password = "p;ss"
+
+[cache]
+ttl=60
+
+[database]
+pool = 10
+`
+	parsed, err := fromIni(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"name":     "web",
+		"database": map[string]interface{}{"host": "db.local", "port": "5432", "password": "p;ss", "pool": "10"},
+		"cache":    map[string]interface{}{"ttl": "60"},
+	}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("got: %v, want: %v", parsed, expected)
+	}
+}
+
+func TestFromIniErrors(t *testing.T) {
+	cases := map[string]string{
+		"[open":                    "line 1: unterminated section",
+		"a = 1\nnovalue":           "line 2: expected key = value",
+		"a = \"b\\x\"":             "line 1: invalid quoted value",
+		"database = 1\n[database]": "line 2: section database conflicts with a key",
+	}
+	for content, expected := range cases {
+		if _, err := fromIni(content); err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("content: %q, got: %v, want: %s", content, err, expected)
+		}
+	}
+}
+
+func TestToIni(t *testing.T) {
+	encoded, err := toIni(map[string]interface{}{
+		"name":     "web",
+		"database": map[string]interface{}{"port": 5432, "host": "db.local", "password": "p;ss"},
+		"cache":    map[string]string{"ttl": "60"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "name = web\n\n[cache]\nttl = 60\n\n[database]\nhost = db.local\npassword = \"p;ss\"\nport = 5432\n"
+	if encoded != expected {
+		t.Errorf("got: %q, want: %q", encoded, expected)
+	}
+	if parsed, err := fromIni(encoded); err != nil || parsed["database"].(map[string]interface{})["password"] != "p;ss" {
+		t.Errorf("expected the encoded ini to round trip, got: %v, error: %v", parsed, err)
+	}
+
+	if _, err := toIni(map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{}}}); err == nil || !strings.Contains(err.Error(), "key: a.b, expected a scalar") {
+		t.Errorf("expected an error on a nested section, got: %v", err)
+	}
+	if _, err := toIni("a"); err == nil {
+		t.Error("expected an error encoding a string")
+	}
+}
+
+func TestFromProperties(t *testing.T) {
+	content := `# application settings
+! another comment
+app.name = web
+app.port:8080
+greeting hello world
+path=c:\\temp\\app
+multi = first, \
+        second
+escaped\ key = \u00e9t\u00e9
+tabs=a\tb
+empty
+`
+	parsed, err := fromProperties(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"app.name":    "web",
+		"app.port":    "8080",
+		"greeting":    "hello world",
+		"path":        `c:\temp\app`,
+		"multi":       "first, second",
+		"escaped key": "été",
+		"tabs":        "a\tb",
+		"empty":       "",
+	}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("got: %v, want: %v", parsed, expected)
+	}
+
+	if _, err := fromProperties("a = 1\nb = \\u00zz"); err == nil || !strings.HasPrefix(err.Error(), "line 2: invalid unicode escape") {
+		t.Errorf("expected an invalid escape error, got: %v", err)
+	}
+}
+
+func TestToProperties(t *testing.T) {
+	vars := map[string]interface{}{
+		"app":     map[string]interface{}{"name": "web", "port": 8080},
+		"a key=1": " leading",
+		"lines":   "one\ntwo",
+		"path":    `c:\temp`,
+	}
+	encoded, err := toProperties(vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "a\\ key\\=1=\\ leading\napp.name=web\napp.port=8080\nlines=one\\ntwo\npath=c:\\\\temp\n"
+	if encoded != expected {
+		t.Errorf("got: %q, want: %q", encoded, expected)
+	}
+	parsed, err := fromProperties(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if parsed["a key=1"] != " leading" || parsed["lines"] != "one\ntwo" || parsed["path"] != `c:\temp` || parsed["app.port"] != "8080" {
+		t.Errorf("expected the encoded properties to round trip, got: %v", parsed)
+	}
+
+	if _, err := toProperties(map[string]interface{}{"a": []interface{}{1}}); err == nil || !strings.Contains(err.Error(), "key: a, expected a scalar") {
+		t.Errorf("expected an error on a list, got: %v", err)
+	}
+}
+
+func TestIniFuncs(t *testing.T) {
+	vars := map[string]interface{}{"ini": "[db]\nhost = a\n", "properties": "db.host=b\n"}
+	rendered, err := New(Options{}).Render(`{{ (fromIni .ini).db.host }} {{ index (fromProperties .properties) "db.host" }} {{ toProperties (fromIni .ini) }}`, vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "a b db.host=a\n" {
+		t.Errorf("got: %q, want: %q", rendered, "a b db.host=a\n")
+	}
+}
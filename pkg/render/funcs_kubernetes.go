@@ -0,0 +1,181 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var (
+	// dns1123Invalid matches characters not permitted in a dns-1123 label
+	dns1123Invalid = regexp.MustCompile(`[^a-z0-9-]+`)
+	// labelInvalid matches characters not permitted in a label name or value
+	labelInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	// quantityFormat matches a kubernetes resource quantity
+	quantityFormat = regexp.MustCompile(`^([+-]?[0-9]*\.?[0-9]+)([eE][+-]?[0-9]+|m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+)
+
+// binarySuffixes are the binary quantity suffixes, largest first
+var binarySuffixes = []string{"Ei", "Pi", "Ti", "Gi", "Mi", "Ki"}
+
+// decimalSuffixes are the decimal quantity suffixes, largest first
+var decimalSuffixes = []string{"E", "P", "T", "G", "M", "k"}
+
+// quantityMultipliers is the multiplier of each quantity suffix
+var quantityMultipliers = map[string]*big.Rat{
+	"m":  big.NewRat(1, 1000),
+	"":   big.NewRat(1, 1),
+	"k":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(3), nil)),
+	"M":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(6), nil)),
+	"G":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(9), nil)),
+	"T":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(12), nil)),
+	"P":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(15), nil)),
+	"E":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)),
+	"Ki": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 10)),
+	"Mi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 20)),
+	"Gi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 30)),
+	"Ti": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 40)),
+	"Pi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 50)),
+	"Ei": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 60)),
+}
+
+// kubernetesFuncs are helpers for templates producing kubernetes manifests
+func kubernetesFuncs() template.FuncMap {
+	return template.FuncMap{
+		"sanitizeDNS1123":  sanitizeDNS1123,
+		"toK8sLabels":      toK8sLabels,
+		"resourceQuantity": resourceQuantity,
+		"toK8sEnvList":     toK8sEnvList,
+	}
+}
+
+// sanitizeDNS1123 converts the string into a valid dns-1123 label, i.e. lowercase alphanumerics
+// and dashes, starting and ending with an alphanumeric and no longer than 63 characters
+func sanitizeDNS1123(s string) string {
+	label := dns1123Invalid.ReplaceAllString(strings.ToLower(s), "-")
+	label = strings.Trim(label, "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+
+	return label
+}
+
+// sanitizeLabel converts the string into a valid label name or value
+func sanitizeLabel(s string) string {
+	label := labelInvalid.ReplaceAllString(s, "-")
+	label = strings.Trim(label, "-_.")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-_.")
+	}
+
+	return label
+}
+
+// toK8sLabels converts the map into valid kubernetes labels; keys may carry a dns subdomain prefix
+func toK8sLabels(m map[string]interface{}) map[string]string {
+	labels := make(map[string]string, len(m))
+	for k, v := range m {
+		key := sanitizeLabel(k)
+		if index := strings.LastIndex(k, "/"); index > 0 {
+			var parts []string
+			for _, x := range strings.Split(k[:index], ".") {
+				if part := sanitizeDNS1123(x); part != "" {
+					parts = append(parts, part)
+				}
+			}
+			key = sanitizeLabel(k[index+1:])
+			if prefix := strings.Join(parts, "."); prefix != "" && len(prefix) <= 253 {
+				key = prefix + "/" + key
+			}
+		}
+		if key == "" {
+			continue
+		}
+		labels[key] = sanitizeLabel(fmt.Sprintf("%v", v))
+	}
+
+	return labels
+}
+
+// resourceQuantity parses a kubernetes resource quantity, returning it in canonical form, i.e.
+// "1024Mi" becomes "1Gi", "0.5" becomes "500m" and 2048 becomes "2048"
+func resourceQuantity(v interface{}) (string, error) {
+	value := strings.TrimSpace(fmt.Sprintf("%v", v))
+	matches := quantityFormat.FindStringSubmatch(value)
+	if matches == nil {
+		return "", fmt.Errorf("invalid resource quantity: %q", value)
+	}
+	number, ok := new(big.Rat).SetString(matches[1])
+	if !ok {
+		return "", fmt.Errorf("invalid resource quantity: %q", value)
+	}
+	suffix := matches[2]
+	if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		exponent, ok := new(big.Rat).SetString("1" + suffix)
+		if !ok {
+			return "", fmt.Errorf("invalid resource quantity: %q", value)
+		}
+		number.Mul(number, exponent)
+		suffix = ""
+	}
+	number.Mul(number, quantityMultipliers[suffix])
+
+	// step: keep to the binary or decimal family of the original suffix
+	family := decimalSuffixes
+	if strings.HasSuffix(suffix, "i") {
+		family = binarySuffixes
+	}
+	if number.IsInt() {
+		if number.Sign() == 0 {
+			return "0", nil
+		}
+		for _, x := range family {
+			scaled := new(big.Rat).Quo(number, quantityMultipliers[x])
+			if scaled.IsInt() {
+				return scaled.Num().String() + x, nil
+			}
+		}
+		return number.Num().String(), nil
+	}
+	milli := new(big.Rat).Quo(number, quantityMultipliers["m"])
+	if !milli.IsInt() {
+		return "", fmt.Errorf("resource quantity %q has a precision finer than 1m", value)
+	}
+
+	return milli.Num().String() + "m", nil
+}
+
+// toK8sEnvList converts the map into a list of name/value pairs sorted by name
+func toK8sEnvList(m map[string]interface{}) []map[string]string {
+	var names []string
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var list []map[string]string
+	for _, name := range names {
+		list = append(list, map[string]string{"name": name, "value": fmt.Sprintf("%v", m[name])})
+	}
+
+	return list
+}
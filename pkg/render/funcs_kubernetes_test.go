@@ -0,0 +1,110 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeDNS1123(t *testing.T) {
+	cases := map[string]string{
+		"My_Service.Name":  "my-service-name",
+		"--leading":        "leading",
+		"already-valid-01": "already-valid-01",
+		"a b  c":           "a-b-c",
+	}
+	for input, expected := range cases {
+		if got := sanitizeDNS1123(input); got != expected {
+			t.Errorf("input: %q, got: %q, want: %q", input, got, expected)
+		}
+	}
+	long := sanitizeDNS1123(strings.Repeat("ab", 50))
+	if len(long) != 63 {
+		t.Errorf("expected the label to be truncated, got %d characters", len(long))
+	}
+}
+
+func TestToK8sLabels(t *testing.T) {
+	labels := toK8sLabels(map[string]interface{}{
+		"app":                    "my app!",
+		"Example.COM/team":       "platform",
+		"app.kubernetes.io/name": "web",
+		"!!!":                    "dropped",
+	})
+	expected := map[string]string{
+		"app":                    "my-app",
+		"example.com/team":       "platform",
+		"app.kubernetes.io/name": "web",
+	}
+	if len(labels) != len(expected) {
+		t.Fatalf("got: %v, want: %v", labels, expected)
+	}
+	for k, v := range expected {
+		if labels[k] != v {
+			t.Errorf("label %s, got: %q, want: %q", k, labels[k], v)
+		}
+	}
+}
+
+func TestResourceQuantity(t *testing.T) {
+	cases := []struct {
+		Value    interface{}
+		Expected string
+		Error    bool
+	}{
+		{Value: "1024Mi", Expected: "1Gi"},
+		{Value: "1536Mi", Expected: "1536Mi"},
+		{Value: "0.5", Expected: "500m"},
+		{Value: "1.5Gi", Expected: "1536Mi"},
+		{Value: "2000", Expected: "2k"},
+		{Value: 2048, Expected: "2048"},
+		{Value: "1e3", Expected: "1k"},
+		{Value: "0", Expected: "0"},
+		{Value: "0.0001", Error: true},
+		{Value: "ten", Error: true},
+	}
+	for i, x := range cases {
+		got, err := resourceQuantity(x.Value)
+		if x.Error {
+			if err == nil {
+				t.Errorf("case %d, expected an error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if got != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, got, x.Expected)
+		}
+	}
+}
+
+func TestToK8sEnvList(t *testing.T) {
+	content := `{{ range toK8sEnvList . }}- name: {{ .name }}
+  value: "{{ .value }}"
+{{ end }}`
+	rendered, err := New(Options{}).Render(content, map[string]interface{}{"B": 2, "A": "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "- name: A\n  value: \"1\"\n- name: B\n  value: \"2\"\n"
+	if rendered != expected {
+		t.Errorf("got: %q, want: %q", rendered, expected)
+	}
+}
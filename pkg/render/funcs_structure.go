@@ -0,0 +1,161 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+// structureFuncs are helpers for traversing nested maps and lists
+func structureFuncs() template.FuncMap {
+	return template.FuncMap{
+		"flatten":           flatten,
+		"mergeWithStrategy": mergeWithStrategy,
+		"toPaths":           toPaths,
+		"unflatten":         unflatten,
+	}
+}
+
+// flatten converts the nested maps and lists into a single map keyed by the dotted path of each
+// leaf, i.e. {"a": {"b": [1]}} becomes {"a.b.0": 1}; dots within keys are escaped with a backslash
+func flatten(v interface{}) map[string]interface{} {
+	flattened := make(map[string]interface{})
+	flattenInto(flattened, "", reflect.ValueOf(v))
+
+	return flattened
+}
+
+// flattenInto adds the leaves under the value to the map
+func flattenInto(flattened map[string]interface{}, prefix string, v reflect.Value) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	join := func(key string) string {
+		key = strings.Replace(key, ".", `\.`, -1)
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch {
+	case v.Kind() == reflect.Map && v.Len() > 0:
+		for _, k := range v.MapKeys() {
+			flattenInto(flattened, join(fmt.Sprintf("%v", k.Interface())), v.MapIndex(k))
+		}
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() > 0:
+		for i := 0; i < v.Len(); i++ {
+			flattenInto(flattened, join(strconv.Itoa(i)), v.Index(i))
+		}
+	case prefix != "":
+		if v.IsValid() {
+			flattened[prefix] = v.Interface()
+		} else {
+			flattened[prefix] = nil
+		}
+	}
+}
+
+// toPaths returns the sorted dotted paths of all the leaves in the nested maps and lists
+func toPaths(v interface{}) []string {
+	var paths []string
+	for k := range flatten(v) {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// unflatten converts a map keyed by dotted paths back into nested maps, the reverse of flatten;
+// list indexes become map keys, as there is no way of telling them apart from other keys
+func unflatten(v interface{}) (map[string]interface{}, error) {
+	m := reflect.ValueOf(v)
+	if m.Kind() != reflect.Map {
+		return nil, fmt.Errorf("unflatten expects a map, got: %T", v)
+	}
+	var keys []string
+	entries := make(map[string]interface{}, m.Len())
+	for _, k := range m.MapKeys() {
+		key := fmt.Sprintf("%v", k.Interface())
+		keys = append(keys, key)
+		entries[key] = m.MapIndex(k).Interface()
+	}
+	sort.Strings(keys)
+
+	nested := make(map[string]interface{})
+	for _, key := range keys {
+		if err := values.Set(nested, key, entries[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return nested, nil
+}
+
+// mergeWithStrategy deep merges the maps in order, later maps winning, with the strategy deciding
+// how values other than maps are combined: override, append-lists or fail-on-conflict
+func mergeWithStrategy(strategy string, maps ...interface{}) (map[string]interface{}, error) {
+	var layers []map[string]interface{}
+	for i, x := range maps {
+		layer, ok := generic(reflect.ValueOf(x)).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mergeWithStrategy expects maps, argument %d is a %T", i+2, x)
+		}
+		layers = append(layers, layer)
+	}
+
+	return values.MergeWithStrategy(strategy, layers...)
+}
+
+// generic converts any maps and lists under the value into string keyed maps and interface
+// lists, the types the values package operates on
+func generic(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			m[fmt.Sprintf("%v", k.Interface())] = generic(v.MapIndex(k))
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = generic(v.Index(i))
+		}
+		return list
+	case reflect.Invalid:
+		return nil
+	}
+
+	return v.Interface()
+}
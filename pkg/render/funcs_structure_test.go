@@ -0,0 +1,104 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	nested := map[string]interface{}{
+		"image":   map[string]interface{}{"name": "nginx", "tag": "1.0"},
+		"ports":   []interface{}{80, 443},
+		"labels":  map[string]string{"example.com/team": "platform"},
+		"empty":   map[string]interface{}{},
+		"enabled": true,
+	}
+	expected := map[string]interface{}{
+		"image.name":               "nginx",
+		"image.tag":                "1.0",
+		"ports.0":                  80,
+		"ports.1":                  443,
+		`labels.example\.com/team`: "platform",
+		"empty":                    map[string]interface{}{},
+		"enabled":                  true,
+	}
+	if got := flatten(nested); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, want: %v", got, expected)
+	}
+}
+
+func TestToPaths(t *testing.T) {
+	paths := toPaths(map[string]interface{}{"b": map[string]interface{}{"c": 1}, "a": "x"})
+	if expected := []string{"a", "b.c"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("got: %v, want: %v", paths, expected)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	nested := map[string]interface{}{
+		"image":  map[string]interface{}{"name": "nginx"},
+		"labels": map[string]interface{}{"example.com/team": "platform"},
+	}
+	got, err := unflatten(flatten(nested))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(got, nested) {
+		t.Errorf("got: %v, want: %v", got, nested)
+	}
+	if _, err := unflatten("not a map"); err == nil {
+		t.Errorf("expected an error for a non map")
+	}
+}
+
+func TestFlattenTemplate(t *testing.T) {
+	content := `{{ $flat := flatten . }}{{ range toPaths . }}{{ . }}={{ index $flat . }}
+{{ end }}`
+	vars := map[string]interface{}{"db": map[string]interface{}{"host": "localhost", "port": 5432}}
+	rendered, err := New(Options{}).Render(content, vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "db.host=localhost\ndb.port=5432\n"; rendered != expected {
+		t.Errorf("got: %q, want: %q", rendered, expected)
+	}
+}
+
+func TestMergeWithStrategy(t *testing.T) {
+	content := `{{ $m := mergeWithStrategy "append-lists" .base .extra }}{{ range $m.names }}{{ . }},{{ end }} {{ $m.region }}`
+	vars := map[string]interface{}{
+		"base":  map[string]interface{}{"names": []string{"a"}, "region": "eu-west-1"},
+		"extra": map[string]interface{}{"names": []interface{}{"b"}, "region": "eu-west-2"},
+	}
+	rendered, err := New(Options{}).Render(content, vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "a,b, eu-west-2"; rendered != expected {
+		t.Errorf("got: %q, want: %q", rendered, expected)
+	}
+
+	_, err = New(Options{}).Render(`{{ mergeWithStrategy "fail-on-conflict" .base .extra }}`, vars)
+	if err == nil || !strings.Contains(err.Error(), "conflicting values for key") {
+		t.Errorf("expected a conflict error, got: %v", err)
+	}
+	if _, err := mergeWithStrategy("override", "not a map"); err == nil {
+		t.Errorf("expected an error for a non map")
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"text/template"
+)
+
+// terraformFuncs mirror the terraform interpolation functions of the same name, so logic may move
+// between the configuration and the templates unchanged
+func terraformFuncs() template.FuncMap {
+	return template.FuncMap{
+		"chunklist":  chunklist,
+		"element":    element,
+		"format":     fmt.Sprintf,
+		"formatlist": formatlist,
+		"join":       join,
+		"lookup":     lookup,
+		"zipmap":     zipmap,
+	}
+}
+
+// join joins the lists with the separator, as terraform does with join(separator, lists...); the
+// original argument order of join(list, separator) remains supported
+func join(first interface{}, rest ...interface{}) (string, error) {
+	if list, ok := toList(first); ok && len(rest) == 1 {
+		if separator, ok := rest[0].(string); ok {
+			return joinList(list, separator), nil
+		}
+	}
+	separator, ok := first.(string)
+	if !ok || len(rest) == 0 {
+		return "", fmt.Errorf("join expects a separator followed by one or more lists")
+	}
+	var joined []interface{}
+	for i, x := range rest {
+		list, ok := toList(x)
+		if !ok {
+			return "", fmt.Errorf("join argument %d is not a list", i+2)
+		}
+		joined = append(joined, list...)
+	}
+
+	return joinList(joined, separator), nil
+}
+
+// joinList joins the elements of the list with the separator
+func joinList(list []interface{}, separator string) string {
+	var joined string
+	for i, x := range list {
+		if i > 0 {
+			joined += separator
+		}
+		joined += fmt.Sprint(x)
+	}
+
+	return joined
+}
+
+// formatlist formats each element of the lists, which must be of the same length, returning the
+// list of the formatted strings; arguments which are not lists are repeated for every element
+func formatlist(format string, args ...interface{}) ([]string, error) {
+	length := -1
+	lists := make([][]interface{}, len(args))
+	for i, x := range args {
+		list, ok := toList(x)
+		if !ok {
+			continue
+		}
+		if length >= 0 && len(list) != length {
+			return nil, fmt.Errorf("formatlist: mismatched list lengths: %d != %d", length, len(list))
+		}
+		length, lists[i] = len(list), list
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("formatlist requires at least one list argument")
+	}
+
+	formatted := make([]string, length)
+	for i := range formatted {
+		values := make([]interface{}, len(args))
+		for j, x := range args {
+			if lists[j] != nil {
+				values[j] = lists[j][i]
+			} else {
+				values[j] = x
+			}
+		}
+		formatted[i] = fmt.Sprintf(format, values...)
+	}
+
+	return formatted, nil
+}
+
+// lookup returns the value of the key in the map, or the default when given and the key is missing
+func lookup(m interface{}, key string, defaults ...interface{}) (interface{}, error) {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("lookup expects a map, got: %T", m)
+	}
+	if len(defaults) > 1 {
+		return nil, fmt.Errorf("lookup expects at most one default, got: %d", len(defaults))
+	}
+	if value := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())); value.IsValid() {
+		return value.Interface(), nil
+	}
+	if len(defaults) == 0 {
+		return nil, fmt.Errorf("lookup failed to find '%s'", key)
+	}
+
+	return defaults[0], nil
+}
+
+// element returns the element at the index of the list, wrapping around once past the end
+func element(list interface{}, index interface{}) (interface{}, error) {
+	items, ok := toList(list)
+	if !ok {
+		return nil, fmt.Errorf("element expects a list, got: %T", list)
+	}
+	i, err := toInt(index)
+	if err != nil {
+		return nil, fmt.Errorf("element index is invalid, error: %s", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("element() may not be used with an empty list")
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("element() requires a non-negative index, got: %d", i)
+	}
+
+	return items[i%len(items)], nil
+}
+
+// chunklist splits the list into lists of at most the size, a size of zero being a single chunk
+func chunklist(list interface{}, size interface{}) ([][]interface{}, error) {
+	items, ok := toList(list)
+	if !ok {
+		return nil, fmt.Errorf("chunklist expects a list, got: %T", list)
+	}
+	n, err := toInt(size)
+	if err != nil {
+		return nil, fmt.Errorf("chunklist size is invalid, error: %s", err)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("the size argument must be positive")
+	}
+	if n == 0 {
+		return [][]interface{}{items}, nil
+	}
+
+	chunks := [][]interface{}{}
+	for i := 0; i < len(items); i += n {
+		end := i + n
+		if end > len(items) {
+			end = len(items)
+		}
+		chunks = append(chunks, items[i:end])
+	}
+
+	return chunks, nil
+}
+
+// zipmap builds a map from the list of keys and the list of values, which must be of the same length
+func zipmap(keys, values interface{}) (map[string]interface{}, error) {
+	k, ok := toList(keys)
+	if !ok {
+		return nil, fmt.Errorf("zipmap expects a list of keys, got: %T", keys)
+	}
+	v, ok := toList(values)
+	if !ok {
+		return nil, fmt.Errorf("zipmap expects a list of values, got: %T", values)
+	}
+	if len(k) != len(v) {
+		return nil, fmt.Errorf("count of keys (%d) does not match count of values (%d)", len(k), len(v))
+	}
+	zipped := make(map[string]interface{}, len(k))
+	for i := range k {
+		zipped[fmt.Sprint(k[i])] = v[i]
+	}
+
+	return zipped, nil
+}
+
+// toList converts a slice or array of any element type into a list
+func toList(value interface{}) ([]interface{}, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+
+	return list, true
+}
+
+// toInt converts an integer, integral float or numeric string into an int, terraform holding
+// numbers in strings
+func toInt(value interface{}) (int, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f == float64(int(f)) {
+			return int(f), nil
+		}
+	case reflect.String:
+		return strconv.Atoi(v.String())
+	}
+
+	return 0, fmt.Errorf("expected an integer, got: %v", value)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerraformFuncs(t *testing.T) {
+	vars := map[string]interface{}{
+		"names": []interface{}{"a", "b", "c"},
+		"ports": []int{80, 443},
+		"tags":  map[string]interface{}{"env": "prod"},
+		"more":  []string{"d"},
+	}
+	cases := []struct {
+		Content  string
+		Expected string
+	}{
+		{Content: `{{ join "," .names }}`, Expected: "a,b,c"},
+		{Content: `{{ join "-" .names .more }}`, Expected: "a-b-c-d"},
+		{Content: `{{ join .names "+" }}`, Expected: "a+b+c"},
+		{Content: `{{ join (split "a,b,c" ",") "-" }}`, Expected: "a-b-c"},
+		{Content: `{{ format "%s-%03d" "web" 7 }}`, Expected: "web-007"},
+		{Content: `{{ join "," (formatlist "%s:%s" .names (split "1,2,3" ",")) }}`, Expected: "a:1,b:2,c:3"},
+		{Content: `{{ join "," (formatlist "%s=%d" "port" .ports) }}`, Expected: "port=80,port=443"},
+		{Content: `{{ lookup .tags "env" }} {{ lookup .tags "tier" "web" }}`, Expected: "prod web"},
+		{Content: `{{ element .names 1 }} {{ element .names 4 }} {{ element .names "5" }}`, Expected: "b b c"},
+		{Content: `{{ chunklist .names 2 }} {{ chunklist .names 0 }} {{ len (chunklist .more 5) }}`, Expected: "[[a b] [c]] [[a b c]] 1"},
+		{Content: `{{ $m := zipmap .names (split "1,2,3" ",") }}{{ $m.a }}{{ $m.c }}`, Expected: "13"},
+	}
+	for i, x := range cases {
+		rendered, err := New(Options{}).Render(x.Content, vars)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered, x.Expected)
+		}
+	}
+}
+
+func TestTerraformFuncsErrors(t *testing.T) {
+	vars := map[string]interface{}{
+		"names": []interface{}{"a", "b"},
+		"tags":  map[string]string{"env": "prod"},
+	}
+	cases := map[string]string{
+		`{{ join "," }}`:                                 "join expects a separator followed by one or more lists",
+		`{{ join "," "a" }}`:                             "join argument 2 is not a list",
+		`{{ formatlist "%s" "a" }}`:                      "formatlist requires at least one list argument",
+		`{{ formatlist "%s%s" .names (split "a" ",") }}`: "mismatched list lengths: 2 != 1",
+		`{{ lookup .tags "tier" }}`:                      "lookup failed to find 'tier'",
+		`{{ lookup .names "a" }}`:                        "lookup expects a map",
+		`{{ element (split "" ",") 0 }}`:                 "", // a list holding the empty string
+		`{{ element .names -1 }}`:                        "requires a non-negative index",
+		`{{ element .names "x" }}`:                       "element index is invalid",
+		`{{ chunklist .names -1 }}`:                      "the size argument must be positive",
+		`{{ zipmap .names (split "a" ",") }}`:            "count of keys (2) does not match count of values (1)",
+	}
+	for content, expected := range cases {
+		_, err := New(Options{}).Render(content, vars)
+		if expected == "" {
+			if err != nil {
+				t.Errorf("content: %s, unexpected error: %s", content, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("content: %s, expected error: %s, got: %v", content, expected, err)
+		}
+	}
+	if _, err := element([]interface{}{}, 0); err == nil || !strings.Contains(err.Error(), "may not be used with an empty list") {
+		t.Errorf("expected an error for an empty list, got: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncs(t *testing.T) {
+	cases := []struct {
+		Content  string
+		Vars     map[string]interface{}
+		Expected string
+	}{
+		{Content: `{{ upper "hello" }}`, Expected: "HELLO"},
+		{Content: `{{ lower "HELLO" }}`, Expected: "hello"},
+		{Content: `{{ join (split "a,b,c" ",") "-" }}`, Expected: "a-b-c"},
+		{Content: `{{ if empty "" }}empty{{ end }}`, Expected: "empty"},
+		{Content: `{{ if is_true "True" }}true{{ end }}`, Expected: "true"},
+		{Content: `{{ if is_true "no" }}true{{ else }}false{{ end }}`, Expected: "false"},
+		{Content: `{{ if isTrue "1" }}true{{ end }}`, Expected: "true"},
+		{Content: `{{ if isFalse "False" }}false{{ end }}`, Expected: "false"},
+		{Content: `{{ if isFalse "true" }}false{{ else }}true{{ end }}`, Expected: "true"},
+		{
+			Content:  `{{ range keys . }}{{ . }}{{ end }}`,
+			Vars:     map[string]interface{}{"a": "1"},
+			Expected: "a",
+		},
+		{
+			Content:  `{{ range values . }}{{ . }}{{ end }}`,
+			Vars:     map[string]interface{}{"a": "1"},
+			Expected: "1",
+		},
+	}
+	for i, x := range cases {
+		rendered, err := New(Options{}).Render(x.Content, x.Vars)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered, x.Expected)
+		}
+	}
+}
+
+func TestSignatures(t *testing.T) {
+	signatures := Signatures(Funcs())
+	if len(signatures) != len(Funcs()) {
+		t.Errorf("expected a signature for every function")
+	}
+	if signatures["split"] != "func(string, string) []string" {
+		t.Errorf("unexpected signature: %s", signatures["split"])
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	described := Descriptions(Funcs())
+	for name := range Funcs() {
+		if described[name] == "" {
+			t.Errorf("function %s has no description", name)
+		}
+	}
+	if _, found := Descriptions(template.FuncMap{"custom": strings.ToUpper})["custom"]; found {
+		t.Errorf("expected no description for an unregistered function")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var (
+	// defineAction matches the opening of a define or block action, capturing the template name
+	defineAction = regexp.MustCompile(`\{\{-?\s*(?:define|block)\s+("(?:[^"\\]|\\.)*"|\x60[^\x60]*\x60)`)
+	// commentOpen matches the opening of a comment action at the end of the text
+	commentOpen = regexp.MustCompile(`\{\{-?\s*$`)
+	// commentClose matches the closing of a comment action at the end of the text
+	commentClose = regexp.MustCompile(`\*/\s*-?\}\}$`)
+)
+
+// IndexEntry describes a template defined in a snippets directory
+type IndexEntry struct {
+	// Name is the name of the define or block
+	Name string
+	// File is the path of the file relative to the snippets directory
+	File string
+	// Doc is the comment immediately preceding the define, if any
+	Doc string
+}
+
+// Index is responsible for scanning the snippets directory for defines; each file must parse,
+// the entries being sorted by name and then file
+func Index(dir string, options Options) ([]IndexEntry, error) {
+	walk := WalkOptions{
+		FollowSymlinks: options.FollowSymlinks,
+		IncludeHidden:  options.IncludeHidden,
+	}
+	funcs := New(options).funcs()
+
+	var entries []IndexEntry
+	var errs SnippetErrors
+	err := WalkFiles(dir, walk, func(path, relative string) error {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err := template.New(relative).Funcs(funcs).Parse(string(content)); err != nil {
+			errs = append(errs, snippetError(relative, relative, err))
+			return nil
+		}
+		entries = append(entries, indexDefines(relative, string(content))...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].File < entries[j].File
+	})
+
+	return entries, nil
+}
+
+// indexDefines returns the defines in the content along with their doc comments
+func indexDefines(file, content string) []IndexEntry {
+	var entries []IndexEntry
+	for _, x := range defineAction.FindAllStringSubmatchIndex(content, -1) {
+		name, err := strconv.Unquote(content[x[2]:x[3]])
+		if err != nil {
+			continue
+		}
+		entries = append(entries, IndexEntry{Name: name, File: file, Doc: leadingComment(content[:x[0]])})
+	}
+
+	return entries
+}
+
+// leadingComment returns the content of the comment action ending the text, ignoring any
+// trailing whitespace, or an empty string if the text does not end with a comment
+func leadingComment(text string) string {
+	text = strings.TrimRight(text, " \t\r\n")
+	end := commentClose.FindStringIndex(text)
+	if end == nil {
+		return ""
+	}
+	start := strings.LastIndex(text[:end[0]], "/*")
+	if start < 0 || !commentOpen.MatchString(text[:start]) {
+		return ""
+	}
+
+	return strings.TrimSpace(text[start+2 : end[0]])
+}
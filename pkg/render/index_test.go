@@ -0,0 +1,73 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"banner.tmpl": `{{/* banner renders the managed by header */}}
+{{ define "banner" }}# managed{{ end }}
+{{ define "undocumented" }}{{ end }}`,
+		"net/retry.tmpl": `{{- /*
+  retry renders the retry policy
+*/ -}}
+{{ block "retry" . }}retry: 3{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	entries, err := Index(dir, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []IndexEntry{
+		{Name: "banner", File: "banner.tmpl", Doc: "banner renders the managed by header"},
+		{Name: "retry", File: "net/retry.tmpl", Doc: "retry renders the retry policy"},
+		{Name: "undocumented", File: "banner.tmpl"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("got: %v, want: %v", entries, expected)
+	}
+}
+
+func TestIndexErrors(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"bad.tmpl": `{{ define "bad" }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	if _, err := Index(dir, Options{}); err == nil {
+		t.Errorf("expected an error for an invalid snippet")
+	}
+}
+
+func TestLeadingComment(t *testing.T) {
+	cases := map[string]string{
+		"{{/* a */}}{{ define \"x\" }}{{ end }}{{/* b */}}\n": "b",
+		"{{/* a */}}{{ define \"x\" }}{{ end }}\n":            "",
+		"no comment":      "",
+		"{{- /* c */ -}}": "c",
+	}
+	for text, expected := range cases {
+		if got := leadingComment(text); got != expected {
+			t.Errorf("text: %q, got: %q, want: %q", text, got, expected)
+		}
+	}
+}
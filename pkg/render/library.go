@@ -0,0 +1,82 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"text/template"
+	"text/template/parse"
+)
+
+// Library is a directory of defines parsed once and shared by many renderers; the defines are
+// of the lowest precedence, so the base template and any snippets may redefine them
+type Library struct {
+	// dir is the path of the library directory
+	dir string
+	// trees is a map of template name to its parsed template
+	trees map[string]*parse.Tree
+	// sources is a map of template name to where it was defined
+	sources map[string]snippetSource
+	// texts is a map of the template name of each file to its text
+	texts map[string]string
+}
+
+// LoadLibrary is responsible for parsing the library directory; the walk, size and naming
+// options are honoured while the snippet paths are ignored
+func LoadLibrary(dir string, options Options) (*Library, error) {
+	options.Library = nil
+	renderer := New(options)
+
+	tmpl := template.New(BaseTemplate).Funcs(renderer.funcs())
+	sources := make(map[string]snippetSource)
+	texts := make(map[string]string)
+	if err := renderer.loadSnippets(tmpl, dir, sources, texts); err != nil {
+		return nil, fmt.Errorf("failed to parse library at: %s, error: %s", dir, err)
+	}
+	trees := make(map[string]*parse.Tree)
+	for _, x := range tmpl.Templates() {
+		if x.Tree == nil || x.Name() == BaseTemplate {
+			continue
+		}
+		trees[x.Name()] = x.Tree
+	}
+
+	return &Library{dir: dir, trees: trees, sources: sources, texts: texts}, nil
+}
+
+// Len returns the number of templates in the library
+func (l *Library) Len() int {
+	return len(l.trees)
+}
+
+// addTo is responsible for adding the library templates not already defined to the template;
+// the trees are copied as instrumenting a trace modifies them
+func (l *Library) addTo(tmpl *template.Template, sources map[string]snippetSource, overrides *Overrides) error {
+	for name, tree := range l.trees {
+		if source, found := sources[name]; found {
+			if overrides != nil {
+				overrides.record(name, source)
+			}
+			continue
+		}
+		if _, err := tmpl.AddParseTree(name, tree.Copy()); err != nil {
+			return err
+		}
+		sources[name] = l.sources[name]
+	}
+
+	return nil
+}
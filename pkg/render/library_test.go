@@ -0,0 +1,74 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLibrary(t *testing.T) {
+	library := testSnippetsDir(t, map[string]string{
+		"banner.tmpl": `{{ define "banner" }}# managed by {{ .owner }}{{ end }}`,
+		"retry.tmpl":  `{{ define "retry" }}retry: 3{{ end }}`,
+	})
+	defer os.RemoveAll(library)
+	snippets := testSnippetsDir(t, map[string]string{
+		"retry.tmpl": `{{ define "retry" }}retry: 5{{ end }}`,
+	})
+	defer os.RemoveAll(snippets)
+
+	lib, err := LoadLibrary(library, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lib.Len() != 4 {
+		t.Errorf("expected 4 templates in the library, got: %d", lib.Len())
+	}
+
+	content := `{{ template "banner" . }} {{ template "retry" }}`
+	vars := map[string]interface{}{"owner": "platform"}
+	cases := []struct {
+		Options  Options
+		Expected string
+	}{
+		{Options: Options{Library: lib}, Expected: "# managed by platform retry: 3"},
+		{Options: Options{Library: lib, Snippets: snippets}, Expected: "# managed by platform retry: 5"},
+		{Options: Options{Library: lib, Trace: NewTrace()}, Expected: "# managed by platform retry: 3"},
+	}
+	for i, x := range cases {
+		rendered, err := New(x.Options).Render(content, vars)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered, x.Expected)
+		}
+	}
+
+	// step: the base template takes precedence over the library
+	rendered, err := New(Options{Library: lib}).Render(`{{ define "retry" }}base{{ end }}{{ template "retry" }}`, nil)
+	if err != nil || rendered != "base" {
+		t.Errorf("expected the base define to win, got: %q, error: %v", rendered, err)
+	}
+}
+
+func TestLibraryErrors(t *testing.T) {
+	if _, err := LoadLibrary("/does/not/exist", Options{}); err == nil {
+		t.Errorf("expected an error for a missing library")
+	}
+}
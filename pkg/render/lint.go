@@ -0,0 +1,254 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+// The kinds of problem reported by Lint
+const (
+	// LintSyntax is a file which fails to parse
+	LintSyntax = "syntax"
+	// LintDuplicateDefine is a define already defined by another file
+	LintDuplicateDefine = "duplicate_define"
+	// LintUnusedDefine is a define which no file in the directory references
+	LintUnusedDefine = "unused_define"
+	// LintUndeclaredVariable is a reference to a variable which has not been declared
+	LintUndeclaredVariable = "undeclared_variable"
+)
+
+// LintKinds are the kinds of problem reported by Lint
+var LintKinds = []string{LintDuplicateDefine, LintSyntax, LintUndeclaredVariable, LintUnusedDefine}
+
+// errorLocation matches the template name and line number at the start of a parse error
+var errorLocation = regexp.MustCompile(`^template: .*?:(\d+):\s*(.*)$`)
+
+// LintOptions control the checks made by Lint
+type LintOptions struct {
+	// Vars are the variables available to the templates; when set, or a file declares its
+	// variables in frontmatter, references to any others are reported
+	Vars map[string]interface{}
+	// Frontmatter parses any leading frontmatter of the files, as the provider does when enabled
+	Frontmatter bool
+}
+
+// LintProblem is a problem found in a template
+type LintProblem struct {
+	// Kind is the kind of problem, i.e. syntax or unused_define
+	Kind string `json:"kind"`
+	// File is the path of the file relative to the directory
+	File string `json:"file"`
+	// Line is the line of the problem in the file, zero when not known
+	Line int `json:"line,omitempty"`
+	// Name is the define or variable the problem relates to
+	Name string `json:"name,omitempty"`
+	// Message describes the problem
+	Message string `json:"message"`
+}
+
+// String returns the problem as file:line: message
+func (p LintProblem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s (%s)", p.File, p.Line, p.Message, p.Kind)
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", p.File, p.Message, p.Kind)
+}
+
+// lintDefine is a define found while linting
+type lintDefine struct {
+	file  string
+	line  int
+	block bool
+}
+
+// Lint is responsible for checking every file in the directory parses, that the defines are
+// unique and referenced, and optionally that the variables referenced have been declared. The
+// problems are sorted by file and line; an error is only returned if the files cannot be read
+func Lint(dir string, options Options, lint LintOptions) ([]LintProblem, error) {
+	walk := WalkOptions{
+		FollowSymlinks: options.FollowSymlinks,
+		IncludeHidden:  options.IncludeHidden,
+	}
+	funcs := New(options).funcs()
+
+	var problems []LintProblem
+	var defines []string
+	defined := make(map[string]lintDefine)
+	referenced := make(map[string]bool)
+
+	err := WalkFiles(dir, walk, func(path, relative string) error {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		text := string(content)
+		frontmatter := &Frontmatter{}
+		if lint.Frontmatter {
+			if frontmatter, text, err = ParseFrontmatter(text); err != nil {
+				problems = append(problems, LintProblem{Kind: LintSyntax, File: relative, Line: 1, Message: err.Error()})
+				return nil
+			}
+		}
+		left, right := New(options).delims()
+		if len(frontmatter.Delimiters) == 2 {
+			left, right = frontmatter.Delimiters[0], frontmatter.Delimiters[1]
+		}
+		parsed, err := template.New(relative).Delims(left, right).Funcs(funcs).Parse(text)
+		if err != nil {
+			problems = append(problems, syntaxProblem(relative, err))
+			return nil
+		}
+
+		// step: record the defines and the templates they reference
+		blocks := blockNames(text, left)
+		for _, x := range parsed.Templates() {
+			if x.Tree == nil || x.Tree.Root == nil {
+				continue
+			}
+			walkTemplates(x.Tree.Root, referenced)
+			if x.Tree.Name == x.Tree.ParseName {
+				continue
+			}
+			line := nodeLine(x.Tree, x.Tree.Root)
+			if first, found := defined[x.Name()]; found && !first.block {
+				problems = append(problems, LintProblem{
+					Kind:    LintDuplicateDefine,
+					File:    relative,
+					Line:    line,
+					Name:    x.Name(),
+					Message: fmt.Sprintf("template %q is defined in both %s and %s", x.Name(), first.file, relative),
+				})
+				continue
+			} else if !found {
+				defines = append(defines, x.Name())
+			}
+			defined[x.Name()] = lintDefine{file: relative, line: line, block: blocks[x.Name()]}
+		}
+
+		// step: check the variables against those declared
+		if lint.Vars == nil && frontmatter.Required == nil && frontmatter.Defaults == nil {
+			return nil
+		}
+		declared := values.Merge(frontmatter.Defaults, lint.Vars)
+		for _, name := range Missing(Variables(parsed), declared) {
+			if isRequired(name, frontmatter.Required) {
+				continue
+			}
+			problems = append(problems, LintProblem{
+				Kind:    LintUndeclaredVariable,
+				File:    relative,
+				Name:    name,
+				Message: fmt.Sprintf("variable %s has not been declared", name),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range defines {
+		if referenced[name] {
+			continue
+		}
+		define := defined[name]
+		problems = append(problems, LintProblem{
+			Kind:    LintUnusedDefine,
+			File:    define.file,
+			Line:    define.line,
+			Name:    name,
+			Message: fmt.Sprintf("template %q is not referenced by any file", name),
+		})
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		return problems[i].Line < problems[j].Line
+	})
+
+	return problems, nil
+}
+
+// syntaxProblem converts the parse error to a problem, extracting the line where possible
+func syntaxProblem(relative string, err error) LintProblem {
+	problem := LintProblem{Kind: LintSyntax, File: relative, Message: err.Error()}
+	if matches := errorLocation.FindStringSubmatch(err.Error()); matches != nil {
+		problem.Line, _ = strconv.Atoi(matches[1])
+		problem.Message = matches[2]
+	}
+
+	return problem
+}
+
+// nodeLine returns the line of the node within the parsed file
+func nodeLine(tree *parse.Tree, node parse.Node) int {
+	location, _ := tree.ErrorContext(node)
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+
+	return line
+}
+
+// isRequired checks if the variable, or a parent of it, is one of the required variables
+func isRequired(name string, required []string) bool {
+	for _, x := range required {
+		if name == x || strings.HasPrefix(name, x+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// walkTemplates collects the names of the templates referenced under the node
+func walkTemplates(node parse.Node, found map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, x := range n.Nodes {
+			walkTemplates(x, found)
+		}
+	case *parse.IfNode:
+		walkTemplates(n.List, found)
+		walkTemplates(n.ElseList, found)
+	case *parse.RangeNode:
+		walkTemplates(n.List, found)
+		walkTemplates(n.ElseList, found)
+	case *parse.WithNode:
+		walkTemplates(n.List, found)
+		walkTemplates(n.ElseList, found)
+	case *parse.TemplateNode:
+		found[n.Name] = true
+	}
+}
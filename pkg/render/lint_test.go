@@ -0,0 +1,94 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"bad.tmpl": "line one\n{{ if }}\n",
+		"base.tmpl": `{{ template "used" . }}
+{{ block "overridable" . }}default{{ end }}`,
+		"defines.tmpl": `{{ define "used" }}{{ .name }}{{ end }}
+{{ define "unused" }}{{ end }}
+{{ define "overridable" }}changed{{ end }}`,
+		"dupe.tmpl": "\n{{ define \"used\" }}{{ end }}",
+	})
+	defer os.RemoveAll(dir)
+
+	problems, err := Lint(dir, Options{}, LintOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []LintProblem{
+		{Kind: LintSyntax, File: "bad.tmpl", Line: 2, Message: "missing value for if"},
+		{Kind: LintUnusedDefine, File: "defines.tmpl", Line: 2, Name: "unused", Message: `template "unused" is not referenced by any file`},
+		{Kind: LintDuplicateDefine, File: "dupe.tmpl", Line: 2, Name: "used", Message: `template "used" is defined in both defines.tmpl and dupe.tmpl`},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("got: %v, want: %v", problems, expected)
+	}
+}
+
+func TestLintVariables(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"config.tmpl": "{{ .name }} {{ .image.tag }} {{ .missing }}",
+		"declared.tmpl": `---
+required: [port]
+defaults:
+  host: localhost
+---
+{{ .host }}:{{ .port }} {{ .other }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	problems, err := Lint(dir, Options{}, LintOptions{
+		Vars:        map[string]interface{}{"name": "app", "image": map[string]interface{}{"tag": "v1"}},
+		Frontmatter: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var names []string
+	for _, x := range problems {
+		if x.Kind != LintUndeclaredVariable {
+			t.Errorf("unexpected problem: %s", x)
+		}
+		names = append(names, x.File+":"+x.Name)
+	}
+	if expected := []string{"config.tmpl:missing", "declared.tmpl:other"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("got: %v, want: %v", names, expected)
+	}
+}
+
+func TestLintVariablesNotDeclared(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"config.tmpl": "{{ .name }}",
+	})
+	defer os.RemoveAll(dir)
+
+	problems, err := Lint(dir, Options{}, LintOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected no problems without declared variables, got: %v", problems)
+	}
+}
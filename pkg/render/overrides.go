@@ -0,0 +1,73 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// Overrides records the defines replaced by a later parsed file. The inheritance model is: a
+// template created with block in the base template, library or a snippet is a default intended to
+// be replaced and may be redefined by any later file; a define from an earlier snippets directory
+// may be redefined by a later one; and with AllowOverrides any template may be redefined
+type Overrides struct {
+	sync.Mutex
+	// entries is a map of template name to the path of the file which last defined it
+	entries map[string]string
+}
+
+// NewOverrides returns an empty record of overrides
+func NewOverrides() *Overrides {
+	return &Overrides{entries: make(map[string]string)}
+}
+
+// Entries returns a map of the overridden template name to the path of the overriding file
+func (o *Overrides) Entries() map[string]string {
+	o.Lock()
+	defer o.Unlock()
+
+	entries := make(map[string]string, len(o.entries))
+	for k, v := range o.entries {
+		entries[k] = v
+	}
+
+	return entries
+}
+
+// record notes the template was overridden by the file
+func (o *Overrides) record(name string, source snippetSource) {
+	o.Lock()
+	defer o.Unlock()
+	o.entries[name] = filepath.Join(source.dir, filepath.FromSlash(source.file))
+}
+
+// blockNames returns the names of the templates created by block actions in the content
+func blockNames(content, left string) map[string]bool {
+	// step: match the opening of a block action, capturing the quoted or raw template name
+	blockAction := regexp.MustCompile(regexp.QuoteMeta(left) + `-?\s*block\s+("(?:[^"\\]|\\.)*"|\x60[^\x60]*\x60)`)
+
+	names := make(map[string]bool)
+	for _, x := range blockAction.FindAllStringSubmatch(content, -1) {
+		if name, err := strconv.Unquote(x[1]); err == nil {
+			names[name] = true
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOverridesBlocks(t *testing.T) {
+	layout := `<header>{{ block "title" . }}default{{ end }}</header>{{ block "body" . }}{{ end }}`
+	dir := testSnippetsDir(t, map[string]string{
+		"prod/title.tmpl": `{{ define "title" }}production{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	overrides := NewOverrides()
+	rendered, err := New(Options{Snippets: dir, Overrides: overrides}).Render(layout, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "<header>production</header>" {
+		t.Errorf("got: %q, want: %q", rendered, "<header>production</header>")
+	}
+	expected := map[string]string{"title": filepath.Join(dir, "prod", "title.tmpl")}
+	if got := overrides.Entries(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, want: %v", got, expected)
+	}
+}
+
+func TestOverridesSnippetPaths(t *testing.T) {
+	base := testSnippetsDir(t, map[string]string{
+		"a.tmpl": `{{ define "a" }}base{{ end }}{{ block "b" . }}base{{ end }}`,
+	})
+	defer os.RemoveAll(base)
+	env := testSnippetsDir(t, map[string]string{
+		"env.tmpl": `{{ define "a" }}env{{ end }}`,
+	})
+	defer os.RemoveAll(env)
+
+	overrides := NewOverrides()
+	rendered, err := New(Options{Snippets: base, SnippetPaths: []string{env}, Overrides: overrides}).Render(`{{ template "a" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "env" {
+		t.Errorf("got: %q, want: %q", rendered, "env")
+	}
+	if got := overrides.Entries()["a"]; got != filepath.Join(env, "env.tmpl") {
+		t.Errorf("unexpected override: %q", got)
+	}
+}
+
+func TestOverridesDefineNotBlock(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"title.tmpl": `{{ define "title" }}snippet{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir}).Render(`{{ define "title" }}base{{ end }}`, nil)
+	if err == nil || !strings.Contains(err.Error(), `template "title" is defined in both base and title.tmpl`) {
+		t.Errorf("expected a duplicate define error, got: %v", err)
+	}
+}
+
+func TestBlockNames(t *testing.T) {
+	content := "{{ block \"a\" . }}{{ end }}{{- block `b` . }}{{ end }}{{ define \"c\" }}{{ end }}"
+	expected := map[string]bool{"a": true, "b": true}
+	if got := blockNames(content, "{{"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, want: %v", got, expected)
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package render provides the template engine used by the provider and the command line tool
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// BaseTemplate is the name given to the main template
+const BaseTemplate = "base"
+
+// Options are the options for the renderer
+type Options struct {
+	// Snippets is the path to a directory containing snippets
+	Snippets string
+	// SnippetPaths is an ordered list of snippet directories loaded after Snippets; defines in
+	// later directories intentionally override those of the same name in earlier ones
+	SnippetPaths []string
+	// Library is a shared library of defines, loaded before and overridable by the snippets
+	Library *Library
+	// SnippetCache, when set, holds the parsed snippet directories for reuse by other renders
+	SnippetCache *SnippetCache
+	// Funcs are additional template functions, overriding the defaults on conflict
+	Funcs template.FuncMap
+	// DisableFuncs are the names of functions removed, a template calling one fails to parse
+	DisableFuncs []string
+	// LeftDelim and RightDelim are the action delimiters of the templates, defaulting to {{ and }}
+	LeftDelim  string
+	RightDelim string
+	// Strict causes the render to fail on references to missing variables
+	Strict bool
+	// FollowSymlinks follows symbolic links when walking the snippet directories
+	FollowSymlinks bool
+	// IncludeHidden loads snippet files and directories whose name begins with a dot
+	IncludeHidden bool
+	// MaxSnippetSize is the maximum size in bytes of a snippet file, zero being unlimited
+	MaxSnippetSize int64
+	// SkipOversized skips snippets exceeding the maximum size rather than failing
+	SkipOversized bool
+	// AllowOverrides permits snippets to redefine templates defined elsewhere
+	AllowOverrides bool
+	// LegacySnippetNames registers snippet files under their basename rather than their path
+	// relative to the snippets directory, files of the same name in subdirectories then collide
+	LegacySnippetNames bool
+	// TrimBlocks removes the first newline after a block tag, i.e. if, range or end
+	TrimBlocks bool
+	// LstripBlocks strips the spaces and tabs from the start of a line up to a block tag
+	LstripBlocks bool
+	// StripComments removes the comment actions from the templates, along with the lines they
+	// occupy alone
+	StripComments bool
+	// CommentPrefix, when set, removes the lines of output whose first non-blank characters are
+	// the prefix
+	CommentPrefix string
+	// Overrides, when set, records the templates redefined by a later parsed file
+	Overrides *Overrides
+	// Trace, when set, records the execution of the templates
+	Trace *Trace
+	// Warnings, when set, records the conditions which do not fail the render, such as skipped
+	// snippets or calls to deprecated functions
+	Warnings *Warnings
+}
+
+// Renderer is responsible for parsing and executing templates
+type Renderer struct {
+	sync.Mutex
+	options Options
+	// sources is a map of the parsed template to the text of each file it was parsed from, keyed
+	// by the parse name, used for the excerpts of the execution errors
+	sources map[*template.Template]map[string]string
+}
+
+// New returns a renderer for the options
+func New(options Options) *Renderer {
+	return &Renderer{options: options, sources: make(map[*template.Template]map[string]string)}
+}
+
+// Render is responsible for parsing the content, along with any snippets, and executing it
+func (r *Renderer) Render(content string, vars map[string]interface{}) (string, error) {
+	tmpl, err := r.Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	return r.Execute(tmpl, vars)
+}
+
+// Parse is responsible for parsing the content as the base template and loading any snippets
+func (r *Renderer) Parse(content string) (*template.Template, error) {
+	// step: load the main template
+	left, right := r.delims()
+	if r.options.StripComments {
+		content = stripComments(content, left, right)
+	}
+	content = trimWhitespace(content, left, right, r.options.TrimBlocks, r.options.LstripBlocks)
+	tmpl, err := template.New(BaseTemplate).Delims(left, right).Funcs(r.funcs()).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if r.options.Strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	// step: load any snippits if required, in order of precedence
+	texts := map[string]string{BaseTemplate: content}
+	sources := make(map[string]snippetSource)
+	blocks := blockNames(content, left)
+	for _, x := range tmpl.Templates() {
+		sources[x.Name()] = snippetSource{file: BaseTemplate, block: blocks[x.Name()]}
+	}
+	if r.options.Library != nil {
+		if err := r.options.Library.addTo(tmpl, sources, r.options.Overrides); err != nil {
+			return nil, err
+		}
+		for name, text := range r.options.Library.texts {
+			texts[name] = text
+		}
+	}
+	for _, dir := range r.snippetPaths() {
+		if err := r.loadSnippets(tmpl, dir, sources, texts); err != nil {
+			return nil, fmt.Errorf("failed to parse snippets at: %s, error: %s", dir, err)
+		}
+	}
+	r.Lock()
+	r.sources[tmpl] = texts
+	r.Unlock()
+	r.options.Warnings.checkDeprecated(tmpl)
+	if r.options.Trace != nil {
+		if err := r.options.Trace.instrument(tmpl); err != nil {
+			return nil, err
+		}
+	}
+
+	return tmpl, nil
+}
+
+// Execute is responsible for executing the base template
+func (r *Renderer) Execute(tmpl *template.Template, vars map[string]interface{}) (string, error) {
+	rendered := new(bytes.Buffer)
+	if err := r.ExecuteTo(rendered, tmpl, vars); err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
+// ExecuteTo is responsible for executing the base template, streaming the output to the writer;
+// an error returned by the writer aborts the execution
+func (r *Renderer) ExecuteTo(w io.Writer, tmpl *template.Template, vars map[string]interface{}) error {
+	if r.options.CommentPrefix == "" {
+		return r.executeTo(w, tmpl, vars)
+	}
+	filter := &commentFilter{w: w, prefix: r.options.CommentPrefix}
+	if err := r.executeTo(filter, tmpl, vars); err != nil {
+		return err
+	}
+
+	return filter.Flush()
+}
+
+// executeTo executes the base template to the writer
+func (r *Renderer) executeTo(w io.Writer, tmpl *template.Template, vars map[string]interface{}) error {
+	if err := tmpl.ExecuteTemplate(w, BaseTemplate, vars); err != nil {
+		return fmt.Errorf("unable to generate content, snippets: %d, error: %s%s", len(tmpl.Templates()), err, r.excerpt(tmpl, err))
+	}
+
+	return nil
+}
+
+// ExecutePasses is responsible for executing the base template and then parsing and executing its
+// output again, for templates which generate template syntax, up to the given number of passes in
+// all; the passes stop early once one leaves the content unchanged
+func (r *Renderer) ExecutePasses(w io.Writer, tmpl *template.Template, vars map[string]interface{}, passes int) error {
+	if passes <= 1 {
+		return r.ExecuteTo(w, tmpl, vars)
+	}
+	rendered, err := r.Execute(tmpl, vars)
+	if err != nil {
+		return err
+	}
+
+	// step: the later passes are not recorded, having already been for the first
+	options := r.options
+	options.Overrides, options.Trace, options.Warnings = nil, nil, nil
+	later := New(options)
+	for pass := 2; ; pass++ {
+		if pass > passes {
+			if left, _ := r.delims(); strings.Contains(rendered, left) {
+				r.options.Warnings.add("the content still holds template syntax after %d render passes", passes)
+			}
+			break
+		}
+		next, err := later.Render(rendered, vars)
+		if err != nil {
+			return fmt.Errorf("render pass %d failed, error: %s", pass, err)
+		}
+		if next == rendered {
+			break
+		}
+		rendered = next
+	}
+	_, err = io.WriteString(w, rendered)
+
+	return err
+}
+
+// ExecuteDefines is responsible for executing each of the defines, returning a map of the define
+// name to its output; the templates of the snippet files themselves are not included
+func (r *Renderer) ExecuteDefines(tmpl *template.Template, vars map[string]interface{}) (map[string]string, error) {
+	outputs := make(map[string]string)
+	for _, name := range Defines(tmpl) {
+		rendered := new(bytes.Buffer)
+		if err := tmpl.ExecuteTemplate(rendered, name, vars); err != nil {
+			return nil, fmt.Errorf("unable to generate define: %s, error: %s%s", name, err, r.excerpt(tmpl, err))
+		}
+		outputs[name] = rendered.String()
+	}
+
+	return outputs, nil
+}
+
+// Defines returns a sorted list of the templates created by a define or block action, in the
+// base template, library or snippets
+func Defines(tmpl *template.Template) []string {
+	var names []string
+	for _, x := range tmpl.Templates() {
+		// a define is parsed as part of another template, so carries its name as the parse name
+		if x.Tree == nil || x.Tree.Name == x.Tree.ParseName {
+			continue
+		}
+		names = append(names, x.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// snippetPaths returns the snippet directories in the order they are loaded
+func (r *Renderer) snippetPaths() []string {
+	var paths []string
+	if r.options.Snippets != "" {
+		paths = append(paths, r.options.Snippets)
+	}
+
+	return append(paths, r.options.SnippetPaths...)
+}
+
+// Snippets returns a sorted list of the templates which were loaded from the snippets
+func Snippets(tmpl *template.Template) []string {
+	var names []string
+	for _, x := range tmpl.Templates() {
+		if x.Tree == nil || x.Tree.ParseName == BaseTemplate {
+			continue
+		}
+		names = append(names, x.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// delims returns the action delimiters
+func (r *Renderer) delims() (string, string) {
+	left, right := r.options.LeftDelim, r.options.RightDelim
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+
+	return left, right
+}
+
+// funcs returns the default functions merged with any from the options
+func (r *Renderer) funcs() template.FuncMap {
+	funcs := Funcs()
+	for name, fn := range r.options.Funcs {
+		funcs[name] = fn
+	}
+	for _, name := range r.options.DisableFuncs {
+		delete(funcs, name)
+	}
+
+	return funcs
+}
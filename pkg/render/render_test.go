@@ -0,0 +1,251 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		Content  string
+		Vars     map[string]interface{}
+		Expected string
+	}{
+		{
+			Content:  "This is a template!",
+			Expected: "This is a template!",
+		},
+		{
+			Content:  "Hello {{ .name }}",
+			Vars:     map[string]interface{}{"name": "rohith"},
+			Expected: "Hello rohith",
+		},
+		{
+			Content:  "Hello {{ .missing }}",
+			Expected: "Hello <no value>",
+		},
+	}
+	for i, x := range cases {
+		rendered, err := New(Options{}).Render(x.Content, x.Vars)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered, x.Expected)
+		}
+	}
+}
+
+func TestRenderStrict(t *testing.T) {
+	_, err := New(Options{Strict: true}).Render("Hello {{ .missing }}", map[string]interface{}{})
+	if err == nil {
+		t.Error("expected an error on a missing variable in strict mode")
+	}
+}
+
+func TestRenderFuncs(t *testing.T) {
+	r := New(Options{
+		Funcs: template.FuncMap{
+			"upper":  func(s string) string { return "overridden" },
+			"repeat": func(s string) string { return s + s },
+		},
+	})
+	rendered, err := r.Render(`{{ upper "a" }} {{ repeat "b" }} {{ lower "C" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "overridden bb c" {
+		t.Errorf("got: %q, want: %q", rendered, "overridden bb c")
+	}
+}
+
+func TestRenderDisableFuncs(t *testing.T) {
+	renderer := New(Options{DisableFuncs: []string{"upper"}})
+	if _, err := renderer.Render(`{{ upper "a" }}`, nil); err == nil || !strings.Contains(err.Error(), `function "upper" not defined`) {
+		t.Errorf("expected an error for a disabled function, got: %v", err)
+	}
+	if rendered, err := renderer.Render(`{{ lower "A" }}`, nil); err != nil || rendered != "a" {
+		t.Errorf("unexpected render: %q, error: %v", rendered, err)
+	}
+}
+
+func TestRenderSnippets(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"greeting.tmpl": `{{ define "greeting" }}Hello {{ .name }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	rendered, err := New(Options{Snippets: dir}).Render(`{{ template "greeting" . }}`, map[string]interface{}{"name": "rohith"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "Hello rohith" {
+		t.Errorf("got: %q, want: %q", rendered, "Hello rohith")
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"b.tmpl": `{{ define "greeting" }}Hello{{ end }}`,
+		"a.tmpl": `a`,
+	})
+	defer os.RemoveAll(dir)
+
+	tmpl, err := New(Options{Snippets: dir}).Parse(`{{ define "local" }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	names := Snippets(tmpl)
+	if strings.Join(names, ",") != "a.tmpl,b.tmpl,greeting" {
+		t.Errorf("unexpected snippets: %v", names)
+	}
+}
+
+// failingWriter fails once more than limit bytes have been written
+type failingWriter struct {
+	limit   int
+	written int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.written += len(p); f.written > f.limit {
+		return 0, errors.New("too large")
+	}
+	return len(p), nil
+}
+
+func TestExecuteTo(t *testing.T) {
+	renderer := New(Options{})
+	tmpl, err := renderer.Parse(`{{ range .items }}{{ . }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w := &failingWriter{limit: 2}
+	err = renderer.ExecuteTo(w, tmpl, map[string]interface{}{"items": []string{"a", "b", "c", "d"}})
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected the writer error, got: %v", err)
+	}
+	if w.written != 3 {
+		t.Errorf("expected the execution to stop at the failed write, written: %d", w.written)
+	}
+}
+
+func TestExecutePasses(t *testing.T) {
+	cases := []struct {
+		Content  string
+		Passes   int
+		Expected string
+		Warning  bool
+	}{
+		{Content: `{{ "{{ .name }}" }}`, Passes: 1, Expected: "{{ .name }}"},
+		{Content: `{{ "{{ .name }}" }}`, Passes: 2, Expected: "web"},
+		{Content: `{{ "{{ .name }}" }}`, Passes: 5, Expected: "web"},
+		{Content: `{{ "{{ \"{{ .name }}\" }}" }}`, Passes: 2, Expected: "{{ .name }}", Warning: true},
+		{Content: `{{ "{{ \"{{ .name }}\" }}" }}`, Passes: 3, Expected: "web"},
+	}
+	for i, x := range cases {
+		warnings := NewWarnings()
+		renderer := New(Options{Warnings: warnings})
+		tmpl, err := renderer.Parse(x.Content)
+		if err != nil {
+			t.Fatalf("case %d, unexpected error: %s", i, err)
+		}
+		rendered := new(strings.Builder)
+		if err := renderer.ExecutePasses(rendered, tmpl, map[string]interface{}{"name": "web"}, x.Passes); err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered.String() != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered.String(), x.Expected)
+		}
+		if warned := len(warnings.Entries()) > 0; warned != x.Warning {
+			t.Errorf("case %d, unexpected warnings: %v", i, warnings.Entries())
+		}
+	}
+
+	renderer := New(Options{})
+	tmpl, _ := renderer.Parse(`{{ "{{ .name " }}`)
+	if err := renderer.ExecutePasses(new(strings.Builder), tmpl, nil, 2); err == nil || !strings.Contains(err.Error(), "render pass 2 failed") {
+		t.Errorf("expected the second pass to fail, got: %v", err)
+	}
+}
+
+func TestExecuteDefines(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"files.tmpl": `{{ define "config.yaml" }}name: {{ .name }}{{ end }}{{ define "motd" }}welcome {{ .name }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	renderer := New(Options{Snippets: dir})
+	tmpl, err := renderer.Parse(`{{ block "header" . }}# {{ .name }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if names := Defines(tmpl); strings.Join(names, ",") != "config.yaml,header,motd" {
+		t.Errorf("unexpected defines: %v", names)
+	}
+	outputs, err := renderer.ExecuteDefines(tmpl, map[string]interface{}{"name": "web"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]string{"config.yaml": "name: web", "header": "# web", "motd": "welcome web"}
+	for name, content := range expected {
+		if outputs[name] != content {
+			t.Errorf("define %s, got: %q, want: %q", name, outputs[name], content)
+		}
+	}
+	if len(outputs) != len(expected) {
+		t.Errorf("unexpected outputs: %v", outputs)
+	}
+}
+
+func TestRenderBadSnippets(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{"bad.tmpl": `{{ define "bad" }}`})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir}).Render("", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to parse snippets") {
+		t.Errorf("expected a snippets parse error, got: %v", err)
+	}
+}
+
+func TestRenderBadTemplate(t *testing.T) {
+	if _, err := New(Options{}).Render("{{ .name ", nil); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestRenderDelims(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"name.tmpl": `<% define "name" %><% .name %><% end %>`,
+	})
+	defer os.RemoveAll(dir)
+
+	options := Options{Snippets: dir, LeftDelim: "<%", RightDelim: "%>", TrimBlocks: true}
+	rendered, err := New(options).Render("{{ literal }} <% if .name %>\n<% template \"name\" . %><% end %>", map[string]interface{}{"name": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "{{ literal }} x" {
+		t.Errorf("got: %q, want: %q", rendered, "{{ literal }} x")
+	}
+}
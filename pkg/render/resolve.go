@@ -0,0 +1,260 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// computedFunc is the function capturing the value of a computed variable
+const computedFunc = "_computed"
+
+// ResolveVars returns a copy of the vars with each string value, including those nested within maps
+// and lists, executed as a template against the other vars, i.e. api_url: https://api.{{ .domain }}.
+// The variables a value references are resolved before it, a cycle between them being an error
+func (r *Renderer) ResolveVars(vars map[string]interface{}) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		resolved[k] = v
+	}
+	left, _ := r.delims()
+
+	order := newDependencyOrder(func(name string) ([]string, error) {
+		var references []string
+		err := walkStrings(vars[name], func(s string) (string, error) {
+			if !strings.Contains(s, left) {
+				return s, nil
+			}
+			tmpl, err := r.parseValue(name, s)
+			if err != nil {
+				return "", err
+			}
+			references = append(references, topLevel(Variables(tmpl))...)
+			return s, nil
+		})
+		return references, err
+	}, func(name string) error {
+		value, err := walkValue(vars[name], func(s string) (string, error) {
+			if !strings.Contains(s, left) {
+				return s, nil
+			}
+			return r.executeValue(name, s, resolved)
+		})
+		if err != nil {
+			return err
+		}
+		resolved[name] = value
+		return nil
+	})
+	for _, name := range sortedKeys(vars) {
+		if err := order.resolve(name, vars); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+// ComputeVars returns a copy of the vars with each computed variable set to the value of its
+// expression, a pipeline such as: printf "%s.%s" .name .domain, evaluated once against the vars.
+// The value keeps its type, so an expression may yield a list or map, and a computed variable may
+// reference another, which is evaluated first
+func (r *Renderer) ComputeVars(computed map[string]string, vars map[string]interface{}) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(vars)+len(computed))
+	for k, v := range vars {
+		resolved[k] = v
+	}
+	candidates := make(map[string]interface{}, len(computed))
+	for k, v := range computed {
+		candidates[k] = v
+	}
+	left, right := r.delims()
+
+	// step: the expression is wrapped in a call capturing its value rather than its output, the
+	// capturing function being rebound when the template is executed
+	capture := func(v interface{}) string { return "" }
+	templates := make(map[string]*template.Template)
+	order := newDependencyOrder(func(name string) ([]string, error) {
+		funcs := r.funcs()
+		funcs[computedFunc] = capture
+		tmpl, err := template.New(name).Delims(left, right).Funcs(funcs).Parse(left + " " + computedFunc + " (" + computed[name] + ") " + right)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse computed variable: %s, error: %s", name, err)
+		}
+		if r.options.Strict {
+			tmpl = tmpl.Option("missingkey=error")
+		}
+		templates[name] = tmpl
+		return topLevel(Variables(tmpl)), nil
+	}, func(name string) error {
+		var value interface{}
+		tmpl := templates[name].Funcs(template.FuncMap{computedFunc: func(v interface{}) string {
+			value = v
+			return ""
+		}})
+		if err := tmpl.Execute(ioutil.Discard, resolved); err != nil {
+			return fmt.Errorf("unable to evaluate computed variable: %s, error: %s", name, err)
+		}
+		resolved[name] = value
+		return nil
+	})
+	for _, name := range sortedKeys(candidates) {
+		if err := order.resolve(name, candidates); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+// parseValue parses the template held by the variable with the functions and delimiters of the renderer
+func (r *Renderer) parseValue(name, content string) (*template.Template, error) {
+	left, right := r.delims()
+	tmpl, err := template.New(name).Delims(left, right).Funcs(r.funcs()).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse variable: %s, error: %s", name, err)
+	}
+	if r.options.Strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
+	return tmpl, nil
+}
+
+// executeValue parses and executes the template held by the variable
+func (r *Renderer) executeValue(name, content string, vars map[string]interface{}) (string, error) {
+	tmpl, err := r.parseValue(name, content)
+	if err != nil {
+		return "", err
+	}
+	rendered := new(bytes.Buffer)
+	if err := tmpl.Execute(rendered, vars); err != nil {
+		return "", fmt.Errorf("unable to resolve variable: %s, error: %s", name, err)
+	}
+
+	return rendered.String(), nil
+}
+
+// dependencyOrder evaluates named values once each, after the values they reference
+type dependencyOrder struct {
+	// references returns the names referenced by the value
+	references func(name string) ([]string, error)
+	// evaluate is called once the referenced values have been evaluated
+	evaluate func(name string) error
+	// done are the names already evaluated
+	done map[string]bool
+	// visiting is the chain of names being evaluated, used to detect a cycle
+	visiting []string
+}
+
+// newDependencyOrder returns an order evaluating the values
+func newDependencyOrder(references func(string) ([]string, error), evaluate func(string) error) *dependencyOrder {
+	return &dependencyOrder{references: references, evaluate: evaluate, done: make(map[string]bool)}
+}
+
+// resolve evaluates the value after any it references which are in the candidates
+func (d *dependencyOrder) resolve(name string, candidates map[string]interface{}) error {
+	if d.done[name] {
+		return nil
+	}
+	for i, x := range d.visiting {
+		if x == name {
+			return fmt.Errorf("variables reference each other in a cycle: %s", strings.Join(append(d.visiting[i:], name), " -> "))
+		}
+	}
+	d.visiting = append(d.visiting, name)
+	defer func() { d.visiting = d.visiting[:len(d.visiting)-1] }()
+
+	references, err := d.references(name)
+	if err != nil {
+		return err
+	}
+	for _, x := range references {
+		if _, found := candidates[x]; found {
+			if err := d.resolve(x, candidates); err != nil {
+				return err
+			}
+		}
+	}
+	if err := d.evaluate(name); err != nil {
+		return err
+	}
+	d.done[name] = true
+
+	return nil
+}
+
+// walkValue returns a copy of the value with each string replaced by the function
+func walkValue(value interface{}, fn func(string) (string, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			resolved, err := walkValue(x, fn)
+			if err != nil {
+				return nil, err
+			}
+			copied[k] = resolved
+		}
+		return copied, nil
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, x := range v {
+			resolved, err := walkValue(x, fn)
+			if err != nil {
+				return nil, err
+			}
+			copied[i] = resolved
+		}
+		return copied, nil
+	}
+
+	return value, nil
+}
+
+// walkStrings calls the function with each string within the value
+func walkStrings(value interface{}, fn func(string) (string, error)) error {
+	_, err := walkValue(value, fn)
+	return err
+}
+
+// topLevel returns the first element of each dotted path
+func topLevel(paths []string) []string {
+	var names []string
+	for _, x := range paths {
+		names = append(names, strings.SplitN(x, ".", 2)[0])
+	}
+
+	return names
+}
+
+// sortedKeys returns the keys of the map in order
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveVars(t *testing.T) {
+	vars := map[string]interface{}{
+		"api_url":     "https://api.{{ .domain }}",
+		"domain":      "{{ .env }}.{{ .base_domain }}",
+		"base_domain": "example.com",
+		"env":         "prod",
+		"count":       3,
+		"endpoints": map[string]interface{}{
+			"web":   "https://{{ .domain }}",
+			"paths": []interface{}{"{{ .api_url }}/v1", "/health"},
+		},
+	}
+	resolved, err := New(Options{}).ResolveVars(vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"api_url":     "https://api.prod.example.com",
+		"domain":      "prod.example.com",
+		"base_domain": "example.com",
+		"env":         "prod",
+		"count":       3,
+		"endpoints": map[string]interface{}{
+			"web":   "https://prod.example.com",
+			"paths": []interface{}{"https://api.prod.example.com/v1", "/health"},
+		},
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("got: %v, want: %v", resolved, expected)
+	}
+	if vars["domain"] != "{{ .env }}.{{ .base_domain }}" {
+		t.Errorf("expected the vars to be left untouched")
+	}
+}
+
+func TestResolveVarsDelims(t *testing.T) {
+	resolved, err := New(Options{LeftDelim: "<%", RightDelim: "%>"}).ResolveVars(map[string]interface{}{
+		"a": "<% upper .b %> {{ literal }}",
+		"b": "x",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resolved["a"] != "X {{ literal }}" {
+		t.Errorf("got: %q", resolved["a"])
+	}
+}
+
+func TestResolveVarsErrors(t *testing.T) {
+	cases := []struct {
+		Vars    map[string]interface{}
+		Strict  bool
+		Message string
+	}{
+		{
+			Vars:    map[string]interface{}{"a": "{{ .b }}", "b": "{{ .c }}", "c": "{{ .a }}"},
+			Message: "variables reference each other in a cycle: a -> b -> c -> a",
+		},
+		{
+			Vars:    map[string]interface{}{"a": "{{ .a }}"},
+			Message: "variables reference each other in a cycle: a -> a",
+		},
+		{
+			Vars:    map[string]interface{}{"a": "{{ .b "},
+			Message: "unable to parse variable: a",
+		},
+		{
+			Vars:    map[string]interface{}{"a": "{{ .missing }}"},
+			Strict:  true,
+			Message: "unable to resolve variable: a",
+		},
+	}
+	for i, x := range cases {
+		_, err := New(Options{Strict: x.Strict}).ResolveVars(x.Vars)
+		if err == nil || !strings.Contains(err.Error(), x.Message) {
+			t.Errorf("case %d, expected error: %q, got: %v", i, x.Message, err)
+		}
+	}
+}
+
+func TestComputeVars(t *testing.T) {
+	computed := map[string]string{
+		"fqdn":   `printf "%s.%s" .name .domain`,
+		"url":    `printf "https://%s" .fqdn`,
+		"domain": `.base | lower`,
+		"ports":  `split "80,443" ","`,
+	}
+	vars := map[string]interface{}{"name": "web", "base": "EXAMPLE.COM"}
+	resolved, err := New(Options{}).ComputeVars(computed, vars)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"name":   "web",
+		"base":   "EXAMPLE.COM",
+		"domain": "example.com",
+		"fqdn":   "web.example.com",
+		"url":    "https://web.example.com",
+		"ports":  []string{"80", "443"},
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("got: %#v, want: %#v", resolved, expected)
+	}
+	if _, found := vars["fqdn"]; found {
+		t.Errorf("expected the vars to be left untouched")
+	}
+}
+
+func TestComputeVarsErrors(t *testing.T) {
+	cases := []struct {
+		Computed map[string]string
+		Message  string
+	}{
+		{Computed: map[string]string{"a": ".b", "b": ".a"}, Message: "variables reference each other in a cycle: a -> b -> a"},
+		{Computed: map[string]string{"a": "upper"}, Message: "unable to evaluate computed variable: a"},
+		{Computed: map[string]string{"a": "(("}, Message: "unable to parse computed variable: a"},
+	}
+	for i, x := range cases {
+		_, err := New(Options{}).ComputeVars(x.Computed, nil)
+		if err == nil || !strings.Contains(err.Error(), x.Message) {
+			t.Errorf("case %d, expected error: %q, got: %v", i, x.Message, err)
+		}
+	}
+}
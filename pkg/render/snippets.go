@@ -0,0 +1,224 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// snippetSource records where a template was defined
+type snippetSource struct {
+	// dir is the snippets directory, empty for the base template
+	dir string
+	// file is the path of the file relative to the snippets directory
+	file string
+	// block indicates the template was created by a block action, so may be redefined
+	block bool
+}
+
+// loadSnippets is responsible for adding the files in the directory to the template; sources
+// is a map of template name to where it was defined, used to detect duplicate defines, and texts
+// a map of the template name of each file to its text. A define is only permitted to override a
+// block or one from an earlier snippets directory, unless overrides are allowed
+func (r *Renderer) loadSnippets(tmpl *template.Template, dir string, sources map[string]snippetSource, texts map[string]string) error {
+	files, err := r.snippetFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	// step: collect the errors from every file so they can be fixed in one pass
+	var errs SnippetErrors
+	for _, x := range files {
+		if err := r.addSnippet(tmpl, dir, x, sources, texts); err != nil {
+			errs = append(errs, snippetError(x.relative, x.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// snippetFiles returns the parsed files of the directory, from the snippet cache when configured
+func (r *Renderer) snippetFiles(dir string) ([]*snippetFile, error) {
+	if r.options.SnippetCache != nil {
+		return r.options.SnippetCache.load(dir, r.cacheKey(dir), r.walkOptions(), func() ([]*snippetFile, error) {
+			return r.parseSnippets(dir)
+		})
+	}
+
+	return r.parseSnippets(dir)
+}
+
+// walkOptions returns the options used to walk the snippet directories
+func (r *Renderer) walkOptions() WalkOptions {
+	return WalkOptions{
+		FollowSymlinks: r.options.FollowSymlinks,
+		IncludeHidden:  r.options.IncludeHidden,
+	}
+}
+
+// parseSnippets is responsible for parsing each of the files in the directory
+func (r *Renderer) parseSnippets(dir string) ([]*snippetFile, error) {
+	var files []*snippetFile
+	err := WalkFiles(dir, r.walkOptions(), func(path, relative string) error {
+		name := relative
+		if r.options.LegacySnippetNames {
+			name = filepath.Base(path)
+		}
+		files = append(files, r.parseSnippet(path, relative, name))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// SnippetErrors is a collection of errors encountered loading the snippets
+type SnippetErrors []error
+
+// Error returns all the errors, one per line
+func (e SnippetErrors) Error() string {
+	var lines []string
+	for _, x := range e {
+		lines = append(lines, x.Error())
+	}
+
+	return fmt.Sprintf("%d error(s) occurred:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// snippetError formats the error as file:line: message, using the path relative to the snippets
+func snippetError(relative, name string, err error) error {
+	// template errors take the form "template: <name>:<line>: <message>"
+	prefix := "template: " + name + ":"
+	if message := err.Error(); strings.HasPrefix(message, prefix) {
+		return fmt.Errorf("%s:%s", relative, strings.TrimPrefix(message, prefix))
+	}
+
+	return fmt.Errorf("%s: %s", relative, err)
+}
+
+// snippetFile is a parsed snippet file
+type snippetFile struct {
+	// name is the template name of the file
+	name string
+	// relative is the path of the file relative to the snippets directory
+	relative string
+	// text is the content of the file as parsed
+	text string
+	// trees are the templates parsed from the file, ordered by name
+	trees []*parse.Tree
+	// blocks are the names of the templates created by block actions
+	blocks map[string]bool
+	// skipped is the warning when the file was skipped for its size
+	skipped string
+	// err is the error reading or parsing the file
+	err error
+}
+
+// parseSnippet is responsible for reading and parsing a snippet file, the file itself being
+// registered under the given name
+func (r *Renderer) parseSnippet(path, relative, name string) *snippetFile {
+	file := &snippetFile{name: name, relative: relative}
+
+	// step: guard against slurping in anything unreasonably large
+	if r.options.MaxSnippetSize > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			file.err = err
+			return file
+		}
+		if info.Size() > r.options.MaxSnippetSize {
+			if r.options.SkipOversized {
+				file.skipped = fmt.Sprintf("snippet %s skipped, %d bytes exceeds the limit of %d bytes", relative, info.Size(), r.options.MaxSnippetSize)
+				return file
+			}
+			file.err = fmt.Errorf("snippet %s is %d bytes, exceeding the limit of %d bytes", relative, info.Size(), r.options.MaxSnippetSize)
+			return file
+		}
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		file.err = err
+		return file
+	}
+
+	// step: parse the file on its own so we know exactly what it defines
+	left, right := r.delims()
+	text := string(content)
+	if r.options.StripComments {
+		text = stripComments(text, left, right)
+	}
+	file.text = trimWhitespace(text, left, right, r.options.TrimBlocks, r.options.LstripBlocks)
+	parsed, err := template.New(name).Delims(left, right).Funcs(r.funcs()).Parse(file.text)
+	if err != nil {
+		file.err = err
+		return file
+	}
+	file.blocks = blockNames(file.text, left)
+	for _, x := range parsed.Templates() {
+		if x.Tree != nil {
+			file.trees = append(file.trees, x.Tree)
+		}
+	}
+	sort.Slice(file.trees, func(i, j int) bool { return file.trees[i].Name < file.trees[j].Name })
+
+	return file
+}
+
+// addSnippet is responsible for adding the templates of the parsed file to the template; the
+// trees are copied as they may be shared through the cache and instrumenting a trace modifies them
+func (r *Renderer) addSnippet(tmpl *template.Template, dir string, file *snippetFile, sources map[string]snippetSource, texts map[string]string) error {
+	if file.err != nil {
+		return file.err
+	}
+	if file.skipped != "" {
+		r.options.Warnings.add("%s", file.skipped)
+		return nil
+	}
+	// step: a file name loaded from two directories cannot be told apart in an error
+	if _, found := texts[file.name]; found {
+		texts[file.name] = ""
+	} else {
+		texts[file.name] = file.text
+	}
+	for _, x := range file.trees {
+		source, found := sources[x.Name]
+		if found && !source.block && (source.dir == "" || source.dir == dir) && !r.options.AllowOverrides {
+			return fmt.Errorf("template %q is defined in both %s and %s", x.Name, source.file, file.relative)
+		}
+		sources[x.Name] = snippetSource{dir: dir, file: file.relative, block: file.blocks[x.Name]}
+		if found && r.options.Overrides != nil {
+			r.options.Overrides.record(x.Name, sources[x.Name])
+		}
+
+		if _, err := tmpl.AddParseTree(x.Name, x.Copy()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testSnippetsDir creates a temporary directory containing the snippets
+func testSnippetsDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "snippets")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory: %s", err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unable to create directory: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("unable to write file: %s", err)
+		}
+	}
+
+	return dir
+}
+
+func TestSnippetsDuplicateDefines(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"a.tmpl": `{{ define "greeting" }}a{{ end }}`,
+		"b.tmpl": `{{ define "greeting" }}b{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir}).Render(`{{ template "greeting" }}`, nil)
+	if err == nil {
+		t.Fatal("expected an error on duplicate defines")
+	}
+	if !strings.Contains(err.Error(), "a.tmpl") || !strings.Contains(err.Error(), "b.tmpl") {
+		t.Errorf("expected both files in the error, got: %s", err)
+	}
+
+	rendered, err := New(Options{Snippets: dir, AllowOverrides: true}).Render(`{{ template "greeting" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "b" {
+		t.Errorf("expected the later snippet to override, got: %q", rendered)
+	}
+}
+
+func TestSnippetsDuplicateBaseDefine(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"a.tmpl": `{{ define "greeting" }}a{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir}).Render(`{{ define "greeting" }}base{{ end }}`, nil)
+	if err == nil || !strings.Contains(err.Error(), BaseTemplate) {
+		t.Errorf("expected a duplicate error against the base template, got: %v", err)
+	}
+}
+
+func TestSnippetPathsOverride(t *testing.T) {
+	base := testSnippetsDir(t, map[string]string{
+		"banner.tmpl": `{{ define "banner" }}base{{ end }}{{ define "footer" }}footer{{ end }}`,
+	})
+	defer os.RemoveAll(base)
+	overlay := testSnippetsDir(t, map[string]string{
+		"banner.tmpl": `{{ define "banner" }}prod{{ end }}`,
+	})
+	defer os.RemoveAll(overlay)
+
+	rendered, err := New(Options{Snippets: base, SnippetPaths: []string{overlay}}).Render(
+		`{{ template "banner" }} {{ template "footer" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "prod footer" {
+		t.Errorf("expected the overlay to override the base, got: %q", rendered)
+	}
+
+	rendered, err = New(Options{SnippetPaths: []string{overlay, base}}).Render(`{{ template "banner" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "base" {
+		t.Errorf("expected the last directory to take precedence, got: %q", rendered)
+	}
+}
+
+func TestSnippetsMaxSize(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"small.tmpl": `{{ define "small" }}small{{ end }}`,
+		"core":       strings.Repeat("\x00", 1024),
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir, MaxSnippetSize: 512}).Render(`{{ template "small" }}`, nil)
+	if err == nil || !strings.Contains(err.Error(), "exceeding the limit") {
+		t.Errorf("expected a size error, got: %v", err)
+	}
+
+	rendered, err := New(Options{Snippets: dir, MaxSnippetSize: 512, SkipOversized: true}).Render(`{{ template "small" }}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "small" {
+		t.Errorf("got: %q, want: %q", rendered, "small")
+	}
+}
+
+func TestSnippetsAllErrors(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"a.tmpl":     "{{ define \"a\" }}",
+		"good.tmpl":  `{{ define "good" }}good{{ end }}`,
+		"sub/b.tmpl": "line\n{{ .name ",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir}).Render(`{{ template "good" }}`, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{"2 error(s) occurred", "\na.tmpl:1: ", "\nsub/b.tmpl:2: "} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in the error: %s", expected, err)
+		}
+	}
+}
+
+func TestSnippetsRelativeNames(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"a/init.tmpl": "a:{{ .name }}",
+		"b/init.tmpl": "b:{{ .name }}",
+	})
+	defer os.RemoveAll(dir)
+
+	content := `{{ template "a/init.tmpl" . }} {{ template "b/init.tmpl" . }}`
+	rendered, err := New(Options{Snippets: dir}).Render(content, map[string]interface{}{"name": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "a:x b:x" {
+		t.Errorf("got: %q, want: %q", rendered, "a:x b:x")
+	}
+}
+
+func TestSnippetsLegacyNames(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"a/init.tmpl":  "a",
+		"b/init.tmpl":  "b",
+		"c/other.tmpl": "c",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := New(Options{Snippets: dir, LegacySnippetNames: true}).Render(`{{ template "other.tmpl" }}`, nil)
+	if err == nil {
+		t.Fatal("expected the basenames to collide")
+	}
+	if !strings.Contains(err.Error(), `template "init.tmpl" is defined in both a/init.tmpl and b/init.tmpl`) {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// traceFunc is the name of the function injected into the templates when tracing
+const traceFunc = "__gotemplate_trace"
+
+// Trace records the execution of the templates and defines
+type Trace struct {
+	sync.Mutex
+	// entries is a map of template name to the entry
+	entries map[string]*TraceEntry
+}
+
+// TraceEntry is the execution record for a single template
+type TraceEntry struct {
+	// Name is the name of the template or define
+	Name string
+	// Source is the name of the file the template was parsed from
+	Source string
+	// Calls is the number of times the template was executed
+	Calls int
+	// Keys are the top level keys of the data the template was executed with
+	Keys []string
+}
+
+// NewTrace returns an empty trace
+func NewTrace() *Trace {
+	return &Trace{entries: make(map[string]*TraceEntry)}
+}
+
+// Entries returns the trace entries sorted by name
+func (t *Trace) Entries() []TraceEntry {
+	t.Lock()
+	defer t.Unlock()
+
+	var list []TraceEntry
+	for _, x := range t.entries {
+		list = append(list, *x)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// String returns a textual summary of the trace
+func (t *Trace) String() string {
+	out := new(bytes.Buffer)
+	for _, x := range t.Entries() {
+		fmt.Fprintf(out, "%s (%s): calls: %d, keys: [%s]\n", x.Name, x.Source, x.Calls, strings.Join(x.Keys, ", "))
+	}
+
+	return out.String()
+}
+
+// instrument injects a call to the trace function at the start of every template
+func (t *Trace) instrument(tmpl *template.Template) error {
+	tmpl.Funcs(template.FuncMap{traceFunc: t.record})
+
+	for _, x := range tmpl.Templates() {
+		if x.Tree == nil || x.Tree.Root == nil {
+			continue
+		}
+		call, err := template.New("trace").Funcs(template.FuncMap{traceFunc: t.record}).
+			Parse(fmt.Sprintf("{{ %s %q . }}", traceFunc, x.Name()))
+		if err != nil {
+			return err
+		}
+		x.Tree.Root.Nodes = append(call.Tree.Root.Nodes, x.Tree.Root.Nodes...)
+
+		t.Lock()
+		t.entries[x.Name()] = &TraceEntry{Name: x.Name(), Source: x.Tree.ParseName}
+		t.Unlock()
+	}
+
+	return nil
+}
+
+// record is called on the execution of a template
+func (t *Trace) record(name string, data interface{}) string {
+	t.Lock()
+	defer t.Unlock()
+
+	entry, found := t.entries[name]
+	if !found {
+		entry = &TraceEntry{Name: name}
+		t.entries[name] = entry
+	}
+	entry.Calls++
+	if m, ok := data.(map[string]interface{}); ok {
+		for k := range m {
+			if !containsString(entry.Keys, k) {
+				entry.Keys = append(entry.Keys, k)
+			}
+		}
+		sort.Strings(entry.Keys)
+	}
+
+	return ""
+}
+
+// containsString checks if the list contains the value
+func containsString(list []string, value string) bool {
+	for _, x := range list {
+		if x == value {
+			return true
+		}
+	}
+
+	return false
+}
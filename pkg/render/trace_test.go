@@ -0,0 +1,55 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"lib.tmpl": `{{ define "item" }}{{ . }}{{ end }}{{ define "unused" }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	trace := NewTrace()
+	rendered, err := New(Options{Snippets: dir, Trace: trace}).Render(
+		`{{ range split .items "," }}{{ template "item" . }}{{ end }}`, map[string]interface{}{"items": "a,b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if rendered != "ab" {
+		t.Errorf("tracing should not change the output, got: %q", rendered)
+	}
+
+	calls := make(map[string]TraceEntry)
+	for _, x := range trace.Entries() {
+		calls[x.Name] = x
+	}
+	if calls["base"].Calls != 1 || len(calls["base"].Keys) != 1 || calls["base"].Keys[0] != "items" {
+		t.Errorf("unexpected base entry: %+v", calls["base"])
+	}
+	if calls["item"].Calls != 2 || calls["item"].Source != "lib.tmpl" {
+		t.Errorf("unexpected item entry: %+v", calls["item"])
+	}
+	if calls["unused"].Calls != 0 {
+		t.Errorf("unexpected unused entry: %+v", calls["unused"])
+	}
+	if trace.String() == "" {
+		t.Error("expected a trace summary")
+	}
+}
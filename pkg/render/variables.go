@@ -0,0 +1,117 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Variables returns a sorted list of the dotted paths of the variables referenced by the templates,
+// i.e. .image.tag gives image.tag. The analysis is static, so references made through a rebound
+// dot, such as those within a range or with, are only reported when made via $
+func Variables(tmpl *template.Template) []string {
+	found := make(map[string]bool)
+	for _, x := range tmpl.Templates() {
+		if x.Tree == nil || x.Tree.Root == nil {
+			continue
+		}
+		walkVariables(x.Tree.Root, true, found)
+	}
+	var names []string
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Missing returns the variables which cannot be found in the vars; a path which reaches a value
+// other than a map before its end is considered present, being a field of that value
+func Missing(names []string, vars map[string]interface{}) []string {
+	var missing []string
+	for _, name := range names {
+		var current interface{} = vars
+		for _, x := range strings.Split(name, ".") {
+			m, isMap := current.(map[string]interface{})
+			if !isMap {
+				break
+			}
+			value, found := m[x]
+			if !found {
+				missing = append(missing, name)
+				break
+			}
+			current = value
+		}
+	}
+
+	return missing
+}
+
+// walkVariables collects the variables referenced under the node; root indicates the dot still
+// refers to the variables passed to the template
+func walkVariables(node parse.Node, root bool, found map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, x := range n.Nodes {
+			walkVariables(x, root, found)
+		}
+	case *parse.ActionNode:
+		walkVariables(n.Pipe, root, found)
+	case *parse.IfNode:
+		walkVariables(n.Pipe, root, found)
+		walkVariables(n.List, root, found)
+		walkVariables(n.ElseList, root, found)
+	case *parse.RangeNode:
+		walkVariables(n.Pipe, root, found)
+		walkVariables(n.List, false, found)
+		walkVariables(n.ElseList, root, found)
+	case *parse.WithNode:
+		walkVariables(n.Pipe, root, found)
+		walkVariables(n.List, false, found)
+		walkVariables(n.ElseList, root, found)
+	case *parse.TemplateNode:
+		walkVariables(n.Pipe, root, found)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, x := range n.Cmds {
+			walkVariables(x, root, found)
+		}
+	case *parse.CommandNode:
+		for _, x := range n.Args {
+			walkVariables(x, root, found)
+		}
+	case *parse.ChainNode:
+		walkVariables(n.Node, root, found)
+	case *parse.FieldNode:
+		if root {
+			found[strings.Join(n.Ident, ".")] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			found[strings.Join(n.Ident[1:], ".")] = true
+		}
+	}
+}
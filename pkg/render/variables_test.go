@@ -0,0 +1,53 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestVariables(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"labels.tmpl": `{{ define "labels" }}app: {{ .app.name }}{{ end }}`,
+	})
+	defer os.RemoveAll(dir)
+
+	content := `{{ .name }} {{ if .enabled }}{{ upper .region }}{{ end }}
+{{ range .items }}{{ .ignored }}{{ $.prefix }}{{ else }}{{ .empty }}{{ end }}
+{{ with .image }}{{ .tag }}{{ end }}{{ template "labels" . }}`
+	tmpl, err := New(Options{Snippets: dir}).Parse(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{"app.name", "empty", "enabled", "image", "items", "name", "prefix", "region"}
+	if got := Variables(tmpl); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, want: %v", got, expected)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	vars := map[string]interface{}{
+		"name":  "web",
+		"image": map[string]interface{}{"tag": "1.0"},
+	}
+	names := []string{"image.name", "image.tag", "name", "name.length", "region"}
+	expected := []string{"image.name", "region"}
+	if got := Missing(names, vars); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, want: %v", got, expected)
+	}
+}
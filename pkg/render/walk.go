@@ -0,0 +1,93 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WalkOptions control which files are walked
+type WalkOptions struct {
+	// FollowSymlinks follows symbolic links rather than skipping them
+	FollowSymlinks bool
+	// IncludeHidden includes files and directories whose name begins with a dot
+	IncludeHidden bool
+}
+
+// WalkFunc is called for each file found by WalkFiles, relative is the path of the file in
+// relation to the root using forward slashes
+type WalkFunc func(path, relative string) error
+
+// WalkFiles calls the function for every regular file under the root in lexical order. Symbolic
+// links are skipped unless followed, in which case a link back to a directory being walked is
+// reported as a cycle
+func WalkFiles(root string, options WalkOptions, fn WalkFunc) error {
+	return walkFiles(root, "", options, make(map[string]bool), fn)
+}
+
+// walkFiles walks a directory; ancestors holds the real paths of the directories being walked
+func walkFiles(dir, relative string, options WalkOptions, ancestors map[string]bool, fn WalkFunc) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if ancestors[real] {
+		return fmt.Errorf("symlink cycle detected at: %s", dir)
+	}
+	ancestors[real] = true
+	defer delete(ancestors, real)
+
+	list, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, x := range list {
+		if !options.IncludeHidden && strings.HasPrefix(x.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, x.Name())
+		name := x.Name()
+		if relative != "" {
+			name = relative + "/" + x.Name()
+		}
+
+		info := x
+		if x.Mode()&os.ModeSymlink != 0 {
+			if !options.FollowSymlinks {
+				continue
+			}
+			if info, err = os.Stat(path); err != nil {
+				return err
+			}
+		}
+		switch {
+		case info.IsDir():
+			if err := walkFiles(path, name, options, ancestors, fn); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			if err := fn(path, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testWalkFiles(t *testing.T, root string, options WalkOptions) ([]string, error) {
+	var files []string
+	err := WalkFiles(root, options, func(path, relative string) error {
+		files = append(files, relative)
+		return nil
+	})
+
+	return files, err
+}
+
+func TestWalkFiles(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"b.tmpl":       "b",
+		"a/one.tmpl":   "one",
+		"a/b/two.tmpl": "two",
+	})
+	defer os.RemoveAll(dir)
+
+	files, err := testWalkFiles(t, dir, WalkOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(files, ",") != "a/b/two.tmpl,a/one.tmpl,b.tmpl" {
+		t.Errorf("unexpected files: %v", files)
+	}
+}
+
+func TestWalkFilesHidden(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"a.tmpl":          "a",
+		".DS_Store":       "binary",
+		".git/config":     "config",
+		"b/.a.tmpl.swp":   "swap",
+		"b/.gitkeep":      "",
+		"b/included.tmpl": "b",
+	})
+	defer os.RemoveAll(dir)
+
+	files, err := testWalkFiles(t, dir, WalkOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(files, ",") != "a.tmpl,b/included.tmpl" {
+		t.Errorf("expected hidden files to be skipped, got: %v", files)
+	}
+
+	files, err = testWalkFiles(t, dir, WalkOptions{IncludeHidden: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(files) != 6 {
+		t.Errorf("expected hidden files to be included, got: %v", files)
+	}
+}
+
+func TestWalkFilesSymlinks(t *testing.T) {
+	shared := testSnippetsDir(t, map[string]string{"shared.tmpl": "shared"})
+	defer os.RemoveAll(shared)
+	dir := testSnippetsDir(t, map[string]string{"local.tmpl": "local"})
+	defer os.RemoveAll(dir)
+	if err := os.Symlink(shared, filepath.Join(dir, "lib")); err != nil {
+		t.Skipf("unable to create symlinks: %s", err)
+	}
+
+	files, err := testWalkFiles(t, dir, WalkOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(files, ",") != "local.tmpl" {
+		t.Errorf("expected symlinks to be skipped, got: %v", files)
+	}
+
+	files, err = testWalkFiles(t, dir, WalkOptions{FollowSymlinks: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(files, ",") != "lib/shared.tmpl,local.tmpl" {
+		t.Errorf("expected symlinks to be followed, got: %v", files)
+	}
+}
+
+func TestWalkFilesSymlinkCycle(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{"a/local.tmpl": "local"})
+	defer os.RemoveAll(dir)
+	if err := os.Symlink(dir, filepath.Join(dir, "a", "loop")); err != nil {
+		t.Skipf("unable to create symlinks: %s", err)
+	}
+
+	if _, err := testWalkFiles(t, dir, WalkOptions{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := testWalkFiles(t, dir, WalkOptions{FollowSymlinks: true}); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected a cycle error, got: %v", err)
+	}
+}
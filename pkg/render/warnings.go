@@ -0,0 +1,126 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// deprecatedFuncs is a map of the deprecated template functions to the advice given on their use
+var deprecatedFuncs = map[string]string{
+	"is_false": "it always returns false, use isFalse",
+	"is_true":  "use isTrue",
+}
+
+// Warnings records the conditions found while rendering which do not fail the render
+type Warnings struct {
+	sync.Mutex
+	entries []string
+}
+
+// NewWarnings returns an empty record of warnings
+func NewWarnings() *Warnings {
+	return &Warnings{}
+}
+
+// Entries returns the warnings in the order they were recorded
+func (w *Warnings) Entries() []string {
+	w.Lock()
+	defer w.Unlock()
+
+	return append([]string(nil), w.entries...)
+}
+
+// add records a warning, doing nothing when there is no record
+func (w *Warnings) add(format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	w.Lock()
+	defer w.Unlock()
+	w.entries = append(w.entries, fmt.Sprintf(format, args...))
+}
+
+// checkDeprecated records a warning for each deprecated function called by the templates
+func (w *Warnings) checkDeprecated(tmpl *template.Template) {
+	if w == nil || len(deprecatedFuncs) == 0 {
+		return
+	}
+	found := make(map[string]bool)
+	for _, x := range tmpl.Templates() {
+		if x.Tree != nil && x.Tree.Root != nil {
+			walkIdentifiers(x.Tree.Root, found)
+		}
+	}
+	var names []string
+	for name := range found {
+		if _, deprecated := deprecatedFuncs[name]; deprecated {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w.add("the function %s is deprecated, %s", name, deprecatedFuncs[name])
+	}
+}
+
+// walkIdentifiers collects the names of the functions called under the node
+func walkIdentifiers(node parse.Node, found map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, x := range n.Nodes {
+			walkIdentifiers(x, found)
+		}
+	case *parse.ActionNode:
+		walkIdentifiers(n.Pipe, found)
+	case *parse.IfNode:
+		walkIdentifiers(n.Pipe, found)
+		walkIdentifiers(n.List, found)
+		walkIdentifiers(n.ElseList, found)
+	case *parse.RangeNode:
+		walkIdentifiers(n.Pipe, found)
+		walkIdentifiers(n.List, found)
+		walkIdentifiers(n.ElseList, found)
+	case *parse.WithNode:
+		walkIdentifiers(n.Pipe, found)
+		walkIdentifiers(n.List, found)
+		walkIdentifiers(n.ElseList, found)
+	case *parse.TemplateNode:
+		walkIdentifiers(n.Pipe, found)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, x := range n.Cmds {
+			walkIdentifiers(x, found)
+		}
+	case *parse.CommandNode:
+		for _, x := range n.Args {
+			walkIdentifiers(x, found)
+		}
+	case *parse.ChainNode:
+		walkIdentifiers(n.Node, found)
+	case *parse.IdentifierNode:
+		found[n.Ident] = true
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestWarningsDeprecated(t *testing.T) {
+	deprecatedFuncs["lower"] = "use a case insensitive comparison"
+	defer delete(deprecatedFuncs, "lower")
+
+	warnings := NewWarnings()
+	_, err := New(Options{Warnings: warnings}).Parse(`{{ if .x }}{{ lower .name }}{{ end }}{{ upper "a" }}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{"the function lower is deprecated, use a case insensitive comparison"}
+	if !reflect.DeepEqual(warnings.Entries(), expected) {
+		t.Errorf("got: %v, want: %v", warnings.Entries(), expected)
+	}
+}
+
+func TestWarningsDeprecatedFuncs(t *testing.T) {
+	warnings := NewWarnings()
+	_, err := New(Options{Warnings: warnings}).Parse(`{{ if is_true .a }}{{ end }}{{ if is_false .b }}{{ end }}{{ if isTrue .c }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		"the function is_false is deprecated, it always returns false, use isFalse",
+		"the function is_true is deprecated, use isTrue",
+	}
+	if !reflect.DeepEqual(warnings.Entries(), expected) {
+		t.Errorf("got: %v, want: %v", warnings.Entries(), expected)
+	}
+}
+
+func TestWarningsOversizedSnippet(t *testing.T) {
+	dir := testSnippetsDir(t, map[string]string{
+		"large.tmpl": "0123456789",
+	})
+	defer os.RemoveAll(dir)
+
+	warnings := NewWarnings()
+	_, err := New(Options{Snippets: dir, MaxSnippetSize: 5, SkipOversized: true, Warnings: warnings}).Parse("x")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{"snippet large.tmpl skipped, 10 bytes exceeds the limit of 5 bytes"}
+	if !reflect.DeepEqual(warnings.Entries(), expected) {
+		t.Errorf("got: %v, want: %v", warnings.Entries(), expected)
+	}
+}
+
+func TestWarningsNil(t *testing.T) {
+	var warnings *Warnings
+	warnings.add("ignored")
+	if _, err := New(Options{}).Parse(`{{ upper "a" }}`); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
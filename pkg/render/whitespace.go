@@ -0,0 +1,112 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"strings"
+)
+
+// blockKeywords are the actions considered block tags by the whitespace control
+var blockKeywords = []string{"block", "break", "continue", "define", "else", "end", "if", "range", "with"}
+
+// trimWhitespace is responsible for applying the jinja style whitespace control to the content;
+// trimBlocks removes the first newline after a block tag and lstripBlocks strips the spaces and
+// tabs from the start of a line up to a block tag. Block tags are the control structures and
+// comments, actions producing output are left untouched
+func trimWhitespace(content, left, right string, trimBlocks, lstripBlocks bool) string {
+	if !trimBlocks && !lstripBlocks {
+		return content
+	}
+	var out strings.Builder
+	for {
+		start := strings.Index(content, left)
+		if start < 0 {
+			out.WriteString(content)
+			break
+		}
+		end := actionEnd(content, start, left, right)
+		if end < 0 {
+			// an unterminated action is left for the parser to report
+			out.WriteString(content)
+			break
+		}
+		text, action := content[:start], content[start:end]
+		content = content[end:]
+
+		block := isBlockAction(action, left)
+		if block && lstripBlocks {
+			line := strings.LastIndex(text, "\n") + 1
+			if strings.Trim(text[line:], " \t") == "" {
+				text = text[:line]
+			}
+		}
+		out.WriteString(text)
+		out.WriteString(action)
+		if block && trimBlocks {
+			if strings.HasPrefix(content, "\r\n") {
+				content = content[2:]
+			} else if strings.HasPrefix(content, "\n") {
+				content = content[1:]
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// actionEnd returns the index just past the closing delimiter of the action starting at the
+// index, skipping over any quoted strings and comments, or -1 if the action is not terminated
+func actionEnd(content string, start int, left, right string) int {
+	i := start + len(left)
+	if rest := strings.TrimLeft(strings.TrimPrefix(content[i:], "-"), " \t\r\n"); strings.HasPrefix(rest, "/*") {
+		index := strings.Index(content[i:], "*/")
+		if index < 0 {
+			return -1
+		}
+		i += index + 2
+	}
+	for ; i < len(content); i++ {
+		switch c := content[i]; c {
+		case '"', '\'', '`':
+			for i++; i < len(content) && content[i] != c; i++ {
+				if content[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+		default:
+			if strings.HasPrefix(content[i:], right) {
+				return i + len(right)
+			}
+		}
+	}
+
+	return -1
+}
+
+// isBlockAction checks if the action is a control structure or a comment
+func isBlockAction(action, left string) bool {
+	if isComment(action, left) {
+		return true
+	}
+	inner := strings.TrimPrefix(action, left)
+	inner = strings.TrimLeft(strings.TrimPrefix(inner, "-"), " \t\r\n")
+	word := inner
+	if index := strings.IndexFunc(inner, func(r rune) bool { return r < 'a' || r > 'z' }); index >= 0 {
+		word = inner[:index]
+	}
+
+	return containsString(blockKeywords, word)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"testing"
+)
+
+func TestTrimWhitespace(t *testing.T) {
+	content := "items:\n  {{ range .items }}\n  - {{ . }}\n  {{ end }}\ndone \"{{ \"}}\" }}\"\n"
+	cases := []struct {
+		TrimBlocks   bool
+		LstripBlocks bool
+		Expected     string
+	}{
+		{Expected: "items:\n  \n  - a\n  \n  - b\n  \ndone \"}}\"\n"},
+		{TrimBlocks: true, Expected: "items:\n    - a\n    - b\n  done \"}}\"\n"},
+		{LstripBlocks: true, Expected: "items:\n\n  - a\n\n  - b\n\ndone \"}}\"\n"},
+		{TrimBlocks: true, LstripBlocks: true, Expected: "items:\n  - a\n  - b\ndone \"}}\"\n"},
+	}
+	vars := map[string]interface{}{"items": []string{"a", "b"}}
+	for i, x := range cases {
+		rendered, err := New(Options{TrimBlocks: x.TrimBlocks, LstripBlocks: x.LstripBlocks}).Render(content, vars)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if rendered != x.Expected {
+			t.Errorf("case %d, got: %q, want: %q", i, rendered, x.Expected)
+		}
+	}
+}
+
+func TestTrimWhitespaceComments(t *testing.T) {
+	content := "  {{/* a comment with }} inside */}}\nvalue\n"
+	if got := trimWhitespace(content, "{{", "}}", true, true); got != "{{/* a comment with }} inside */}}value\n" {
+		t.Errorf("unexpected content: %q", got)
+	}
+}
+
+func TestIsBlockAction(t *testing.T) {
+	cases := map[string]bool{
+		"{{ if .x }}":          true,
+		"{{- end -}}":          true,
+		"{{else}}":             true,
+		"{{ /* c */ }}":        true,
+		"{{ .end }}":           false,
+		"{{ ending }}":         false,
+		"{{ template \"x\" }}": false,
+	}
+	for action, expected := range cases {
+		if got := isBlockAction(action, "{{"); got != expected {
+			t.Errorf("action: %s, got: %t, want: %t", action, got, expected)
+		}
+	}
+}
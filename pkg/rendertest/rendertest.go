@@ -0,0 +1,123 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rendertest provides helpers for module authors to test their templates in go, using
+// the same engine and function set as the provider
+package rendertest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform/terraform"
+
+	"github.com/gambol99/terraform-gotemplate/pkg"
+	"github.com/gambol99/terraform-gotemplate/pkg/diff"
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+// UpdateEnv is the environment variable which, when set to 1, causes AssertGolden to rewrite the
+// golden files rather than compare against them
+const UpdateEnv = "GOTEMPLATE_UPDATE_GOLDEN"
+
+// TB is the subset of testing.TB used by the helpers
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Providers returns the provider keyed by name, for use with resource.Test and resource.UnitTest
+func Providers() map[string]terraform.ResourceProvider {
+	return map[string]terraform.ResourceProvider{
+		"gotemplate": pkg.Provider(),
+	}
+}
+
+// Render renders the template with the vars, failing the test on error
+func Render(t TB, content string, vars map[string]interface{}, options render.Options) string {
+	t.Helper()
+	rendered, err := render.New(options).Render(content, vars)
+	if err != nil {
+		t.Fatalf("unable to render the template, error: %s", err)
+	}
+
+	return rendered
+}
+
+// RenderFile renders the template file with the vars, failing the test on error
+func RenderFile(t TB, path string, vars map[string]interface{}, options render.Options) string {
+	t.Helper()
+
+	return Render(t, LoadFixture(t, path), vars, options)
+}
+
+// AssertRender renders the template and compares it to the expected content, reporting a
+// unified diff of any differences
+func AssertRender(t TB, content string, vars map[string]interface{}, options render.Options, expected string) {
+	t.Helper()
+	Compare(t, Render(t, content, vars, options), expected)
+}
+
+// Compare reports a unified diff if the rendered content differs from the expected
+func Compare(t TB, rendered, expected string) {
+	t.Helper()
+	if changes := diff.Unified("expected", "rendered", expected, rendered); changes != "" {
+		t.Errorf("rendered template does not match the expected content:\n%s", changes)
+	}
+}
+
+// AssertGolden compares the rendered content to the golden file; the file is written instead
+// when the UpdateEnv environment variable is set to 1
+func AssertGolden(t TB, rendered, path string) {
+	t.Helper()
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unable to create the golden directory, error: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(rendered), 0644); err != nil {
+			t.Fatalf("unable to write the golden file: %s, error: %s", path, err)
+		}
+		return
+	}
+	expected := LoadFixture(t, path)
+	if changes := diff.Unified(path, "rendered", expected, rendered); changes != "" {
+		t.Errorf("rendered template does not match the golden file, set %s=1 to update:\n%s", UpdateEnv, changes)
+	}
+}
+
+// LoadFixture returns the content of the file, failing the test on error
+func LoadFixture(t TB, path string) string {
+	t.Helper()
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read the fixture: %s, error: %s", path, err)
+	}
+
+	return string(content)
+}
+
+// LoadVars decodes the yaml or json file of variables, failing the test on error
+func LoadVars(t TB, path string) map[string]interface{} {
+	t.Helper()
+	vars, err := values.Parse(LoadFixture(t, path))
+	if err != nil {
+		t.Fatalf("unable to decode the vars: %s, error: %s", path, err)
+	}
+
+	return vars
+}
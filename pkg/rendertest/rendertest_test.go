@@ -0,0 +1,111 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rendertest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+// fakeTB records the failures rather than failing the test
+type fakeTB struct {
+	failures []string
+	fatal    bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+	f.fatal = true
+}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertRender(t *testing.T) {
+	AssertRender(t, "{{ upper .name }}", map[string]interface{}{"name": "app"}, render.Options{}, "APP")
+
+	fake := &fakeTB{}
+	AssertRender(fake, "{{ .name }}", map[string]interface{}{"name": "app"}, render.Options{}, "other")
+	if len(fake.failures) != 1 || !strings.Contains(fake.failures[0], "-other") || !strings.Contains(fake.failures[0], "+app") {
+		t.Errorf("expected a diff of the failure, got: %v", fake.failures)
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	fake := &fakeTB{}
+	Render(fake, "{{ .name", nil, render.Options{})
+	if !fake.fatal {
+		t.Errorf("expected the test to fail on an invalid template")
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rendertest")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "template.tmpl"), []byte("{{ .image.tag }}"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "vars.yaml"), []byte("image:\n  tag: v1\n"), 0644)
+
+	rendered := RenderFile(t, filepath.Join(dir, "template.tmpl"), LoadVars(t, filepath.Join(dir, "vars.yaml")), render.Options{})
+	Compare(t, rendered, "v1")
+
+	golden := filepath.Join(dir, "golden", "template.golden")
+	os.Setenv(UpdateEnv, "1")
+	AssertGolden(t, rendered, golden)
+	os.Unsetenv(UpdateEnv)
+	AssertGolden(t, rendered, golden)
+
+	fake := &fakeTB{}
+	AssertGolden(fake, "v2", golden)
+	if len(fake.failures) != 1 {
+		t.Errorf("expected a mismatch against the golden file, got: %v", fake.failures)
+	}
+	LoadFixture(fake, filepath.Join(dir, "missing"))
+	if !fake.fatal {
+		t.Errorf("expected the test to fail on a missing fixture")
+	}
+}
+
+func TestProviders(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: Providers(),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ upper .name }}"
+						vars {
+							name = "app"
+						}
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "APP"),
+			},
+		},
+	})
+}
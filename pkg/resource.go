@@ -0,0 +1,170 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/diff"
+)
+
+// maxContentDiffLines is the number of lines of the content diff shown in the plan
+const maxContentDiffLines = 100
+
+// deferredContentDiff is the content diff planned when the render is deferred to the apply, which
+// replaces it with the diff of the content written. Terraform drops an attribute marked computed
+// while its value is empty, so an unknown diff could not be planned after a create
+const deferredContentDiff = "(rendered by the apply, the inputs are not known until then)"
+
+// renderResource is responsible for rendering the template of a resource publishing the content,
+// returning the redactor scoped to the render for any output derived from the content
+func renderResource(d templateData, meta interface{}) (string, *redactor, error) {
+	semaphore := semaphoreOf(meta)
+	semaphore.acquire()
+	defer semaphore.release()
+
+	rendered := new(bytes.Buffer)
+	scoped, err := renderGoTemplateTo(d, meta, rendered)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return rendered.String(), scoped, nil
+}
+
+// renderedDiff returns the custom diff planning an update when the content was modified outside of
+// terraform or any of the files read by the render have changed; the render is passed through
+// written, the transformation applied by the write, before being compared
+func renderedDiff(attributes map[string]*schema.Schema, written func(attributeGetter, string) string) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, meta interface{}) error {
+		if d.Id() == "" {
+			return nil
+		}
+		current := d.Get("rendered").(string)
+		drifted := hash(current) != d.Get("content_sha256").(string)
+		if planned, err := updatePlanned(d, meta, attributes, drifted, "rendered", "content_sha256", "input_sha256"); err != nil || !planned {
+			return err
+		}
+
+		// step: render the new content for the diff; an input unknown until apply plans the note of
+		// the deferred render, while an error rendering leaves the diff computed and the error to be
+		// raised by the update
+		if !inputsKnown(d, attributes) {
+			return d.SetNew("content_diff", deferredContentDiff)
+		}
+		rendered, scoped, err := renderResource(planData{d}, meta)
+		if err != nil {
+			return d.SetNewComputed("content_diff")
+		}
+
+		return d.SetNew("content_diff", contentDiff(scoped, d.Id(), current, written(d, rendered)))
+	}
+}
+
+// updatePlanned checks if an update is planned, when the content has drifted, any input is not known
+// until the apply or the inputs of the render have changed, marking the computed attributes as
+// unknown until the apply; an error hashing the inputs plans the update, leaving the error to be
+// raised by it
+func updatePlanned(d *schema.ResourceDiff, meta interface{}, attributes map[string]*schema.Schema, drifted bool, computed ...string) (bool, error) {
+	switch {
+	case drifted:
+		logEvent(logWarn, "content has been modified outside of terraform", "id", d.Id())
+	case !inputsKnown(d, attributes):
+		logEvent(logDebug, "inputs not known until apply, deferring the render", "id", d.Id())
+	default:
+		inputs, err := inputHash(d, meta, attributes)
+		if err == nil && inputs == d.Get("input_sha256").(string) {
+			return false, nil
+		}
+	}
+	for _, x := range computed {
+		if err := d.SetNewComputed(x); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// withDeferredRender is responsible for deferring the render of the gotemplate_file resource to the
+// apply when any input is not known until then, marking the computed outputs unknown instead of
+// failing the plan as the read of the data source must
+func withDeferredRender(r *schema.Resource) *schema.Resource {
+	r.CustomizeDiff = func(d *schema.ResourceDiff, meta interface{}) error {
+		if d.Id() == "" || inputsKnown(d, r.Schema) {
+			return nil
+		}
+		for name, x := range r.Schema {
+			if x.Computed && !x.Optional {
+				if err := d.SetNewComputed(name); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return r
+}
+
+// writtenAsIs is the transformation of a write publishing the rendered template unchanged
+func writtenAsIs(_ attributeGetter, rendered string) string {
+	return rendered
+}
+
+// inputsKnown checks none of the arguments are waiting on the apply of another resource, nor do the
+// vars hold the placeholder of such a value. Reading a map not known until apply from the diff
+// panics, so a map is checked by its count and the arguments are checked before any is read
+func inputsKnown(d *schema.ResourceDiff, attributes map[string]*schema.Schema) bool {
+	for name, x := range attributes {
+		if x.Type == schema.TypeMap {
+			name += ".%"
+		}
+		if (x.Optional || x.Required) && !d.NewValueKnown(name) {
+			return false
+		}
+	}
+	vars, _ := d.Get("vars").(map[string]interface{})
+
+	return len(unknownKeys(vars)) == 0
+}
+
+// planData is the diff of a plan presented to the render, the computed outputs of which are only
+// recorded by the apply
+type planData struct {
+	*schema.ResourceDiff
+}
+
+// Set discards the computed output
+func (p planData) Set(string, interface{}) error {
+	return nil
+}
+
+// contentDiff returns the unified diff of the content redacted by the redactor of the render,
+// truncated to maxContentDiffLines
+func contentDiff(r *redactor, filename, from, to string) string {
+	lines := strings.SplitAfter(r.redact(diff.Unified(filename, filename, from, to)), "\n")
+	if len(lines) > maxContentDiffLines {
+		lines = append(lines[:maxContentDiffLines], fmt.Sprintf("... %d more lines truncated\n", len(lines)-maxContentDiffLines))
+	}
+
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestContentDiff(t *testing.T) {
+	changes := contentDiff(redactions.withValues("hunter2"), "app.conf", "password: old\n", "password: hunter2\n")
+	if strings.Contains(changes, "hunter2") || !strings.Contains(changes, "+password: <redacted>") {
+		t.Errorf("expected the secret to be redacted, got: %q", changes)
+	}
+
+	var from, to []string
+	for i := 0; i < 200; i++ {
+		from = append(from, fmt.Sprintf("line %d", i))
+		to = append(to, fmt.Sprintf("changed %d", i))
+	}
+	lines := strings.Split(contentDiff(redactions, "app.conf", strings.Join(from, "\n"), strings.Join(to, "\n")), "\n")
+	if len(lines) != maxContentDiffLines+2 || !strings.HasPrefix(lines[maxContentDiffLines], "... ") {
+		t.Errorf("expected the diff to be truncated, got %d lines ending: %q", len(lines), lines[len(lines)-2])
+	}
+}
+
+// remoteBackend is the fake backend of a remote resource, holding the content rendered from the template
+type remoteBackend struct {
+	// config is the provider and resource configuration
+	config string
+	// value returns the content held by the backend
+	value func() string
+	// set changes the content as a change made outside of terraform would
+	set func(string)
+	// check verifies the attributes of the resource once the template changed, if any
+	check resource.TestCheckFunc
+}
+
+func TestRenderedDiffDrift(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Backend func(template string) (remoteBackend, func())
+	}{
+		{Name: "consul", Backend: func(template string) (remoteBackend, func()) {
+			consul, server, provider := testConsul(t)
+			return remoteBackend{
+				config: provider + fmt.Sprintf(`
+					resource "gotemplate_consul_key" "test" {
+						path     = "app/config"
+						template = "%s"
+					}`, template),
+				value: func() string {
+					value, _ := consul.value("app/config")
+					return value
+				},
+				set:   func(value string) { consul.set("app/config", value) },
+				check: resource.TestCheckResourceAttr("gotemplate_consul_key.test", "content_sha256", hash("name: api")),
+			}, server.Close
+		}},
+		{Name: "ssm", Backend: func(template string) (remoteBackend, func()) {
+			ssm, server, provider := testSSM(t)
+			return remoteBackend{
+				config: provider + fmt.Sprintf(`
+					resource "gotemplate_ssm_parameter" "test" {
+						name     = "/app/config"
+						template = "%s"
+					}`, template),
+				value: func() string {
+					parameter, _ := ssm.parameter("/app/config")
+					return parameter.Value
+				},
+				set:   func(value string) { ssm.set("/app/config", value) },
+				check: resource.TestCheckResourceAttr("gotemplate_ssm_parameter.test", "version", "4"),
+			}, server.Close
+		}},
+		{Name: "s3", Backend: func(template string) (remoteBackend, func()) {
+			s3, server, provider := testS3(t)
+			return remoteBackend{
+				config: provider + fmt.Sprintf(`
+					resource "gotemplate_s3_object" "test" {
+						bucket   = "configs"
+						key      = "app/config.yaml"
+						template = "%s"
+					}`, template),
+				value: func() string {
+					object, _ := s3.object("/configs/app/config.yaml")
+					return object.Body
+				},
+				set: func(value string) { s3.set("/configs/app/config.yaml", value) },
+				check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("gotemplate_s3_object.test", "etag", etag("name: api")),
+					resource.TestCheckResourceAttr("gotemplate_s3_object.test", "upload_etag", etag("name: api")),
+				),
+			}, server.Close
+		}},
+		{Name: "vault", Backend: func(template string) (remoteBackend, func()) {
+			vault, server, provider := testVault(t)
+			return remoteBackend{
+				config: provider + fmt.Sprintf(`
+					resource "gotemplate_vault_kv" "test" {
+						path     = "app/config"
+						format   = "raw"
+						template = "%s"
+					}`, template),
+				value: func() string {
+					data, _ := vault.secret("secret/app/config")
+					content, _ := data["content"].(string)
+					return content
+				},
+				set: func(value string) { vault.set("secret/app/config", map[string]interface{}{"content": value}) },
+			}, server.Close
+		}},
+	}
+	for _, x := range cases {
+		testRemoteDrift(t, x.Name, x.Backend)
+	}
+}
+
+// testRemoteDrift checks the changes made outside of terraform, and those of the template file, are
+// planned and applied to the backend
+func testRemoteDrift(t *testing.T, name string, setup func(template string) (remoteBackend, func())) {
+	dir := testTemplateDir(t, map[string]string{"app.tmpl": "name: web"})
+	defer os.RemoveAll(dir)
+	template := filepath.Join(dir, "app.tmpl")
+	backend, closer := setup(filepath.ToSlash(template))
+	defer closer()
+
+	checkValue := func(expected string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if value := backend.value(); value != expected {
+				return fmt.Errorf("%s holds: %q, want: %q", name, value, expected)
+			}
+			return nil
+		}
+	}
+	checkChanged := checkValue("name: api")
+	if backend.check != nil {
+		checkChanged = resource.ComposeTestCheckFunc(checkChanged, backend.check)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: backend.config,
+				Check:  checkValue("name: web"),
+			},
+			{
+				// a change made outside of terraform is planned and overwritten
+				PreConfig: func() { backend.set("manual") },
+				Config:    backend.config,
+				Check:     checkValue("name: web"),
+			},
+			{
+				// a change of the template file is planned though no argument changed
+				PreConfig: func() {
+					if err := ioutil.WriteFile(template, []byte("name: api"), 0644); err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+				},
+				Config: backend.config,
+				Check:  checkChanged,
+			},
+		},
+	})
+}
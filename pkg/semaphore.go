@@ -0,0 +1,43 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+// renderSemaphore bounds the number of data sources rendering at once, nil being unbounded
+type renderSemaphore chan struct{}
+
+// newRenderSemaphore returns a semaphore of the size, or nil when the size is zero
+func newRenderSemaphore(size int) renderSemaphore {
+	if size <= 0 {
+		return nil
+	}
+
+	return make(renderSemaphore, size)
+}
+
+// acquire waits for a free slot; a read acquires its slot before reserving any of the memory
+// budget, so a render waiting on the budget never waits on a render waiting for a slot
+func (s renderSemaphore) acquire() {
+	if s != nil {
+		s <- struct{}{}
+	}
+}
+
+// release frees the slot
+func (s renderSemaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
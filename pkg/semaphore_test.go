@@ -0,0 +1,121 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/render"
+)
+
+func TestRenderSemaphore(t *testing.T) {
+	var none renderSemaphore
+	none.acquire()
+	none.release()
+
+	semaphore := newRenderSemaphore(2)
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore.acquire()
+			defer semaphore.release()
+			current := atomic.AddInt32(&running, 1)
+			for {
+				previous := atomic.LoadInt32(&peak)
+				if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent holders, got: %d", peak)
+	}
+}
+
+// TestConcurrentReads reads the data sources in parallel against a shared provider configuration,
+// as terraform does; run with -race to check the shared state
+func TestConcurrentReads(t *testing.T) {
+	library := testTemplateDir(t, map[string]string{
+		"banner.tmpl": `{{ define "banner" }}# {{ .name }}{{ end }}`,
+	})
+	defer os.RemoveAll(library)
+	source := testTemplateDir(t, map[string]string{
+		"a.conf": `{{ template "banner" . }} a`,
+		"b.conf": `{{ template "banner" . }} b`,
+	})
+	defer os.RemoveAll(source)
+
+	lib, err := render.LoadLibrary(library, render.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	meta := &providerConfig{
+		library:   lib,
+		budget:    newMemoryBudget(1<<20, budgetQueue),
+		semaphore: newRenderSemaphore(4),
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			d := schema.TestResourceDataRaw(t, goDataSourceFile().Schema, map[string]interface{}{
+				"template":     `{{ template "banner" . }} {{ upper .name }}`,
+				"vars":         map[string]interface{}{"name": fmt.Sprintf("app%d", i)},
+				"post_process": []interface{}{"trim", "gzip"},
+				"debug":        true,
+			})
+			if err := dataSourceFileRead(d, meta); err != nil {
+				errs <- err
+				return
+			}
+			if expected := fmt.Sprintf("# app%d APP%d", i, i); d.Get("rendered").(string) != expected {
+				errs <- fmt.Errorf("got: %q, want: %q", d.Get("rendered"), expected)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			d := schema.TestResourceDataRaw(t, goDataSourceDir().Schema, map[string]interface{}{
+				"source_dir": source,
+				"vars":       map[string]interface{}{"name": fmt.Sprintf("app%d", i)},
+			})
+			if err := dataSourceDirRead(d, meta); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
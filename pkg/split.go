@@ -0,0 +1,79 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/validate"
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+// splitOutput is responsible for splitting the content on the separator; a line break directly
+// following a separator is removed along with it, so a marker line leaves no blank line behind,
+// and parts holding nothing but whitespace, such as one before a leading marker, are dropped
+func splitOutput(content, separator string) []string {
+	parts := []string{}
+	for i, x := range strings.Split(content, separator) {
+		if i > 0 {
+			if strings.HasPrefix(x, "\r\n") {
+				x = x[2:]
+			} else if strings.HasPrefix(x, "\n") {
+				x = x[1:]
+			}
+		}
+		if strings.TrimSpace(x) == "" {
+			continue
+		}
+		parts = append(parts, x)
+	}
+
+	return parts
+}
+
+// splitYAML is responsible for splitting the content into its yaml documents, dropping any which
+// are empty; when decode is set each document is also decoded and returned encoded as json, the
+// documents decoding to nothing, such as those holding only comments, then being dropped too
+func splitYAML(content string, decode bool) ([]string, []string, error) {
+	documents := []string{}
+	decoded := []string{}
+	for _, x := range validate.SplitYAMLDocuments(content) {
+		if strings.TrimSpace(x) == "" {
+			continue
+		}
+		if decode {
+			var document interface{}
+			if err := yaml.Unmarshal([]byte(x), &document); err != nil {
+				return nil, nil, fmt.Errorf("unable to decode yaml document %d, error: %s", len(documents), err)
+			}
+			if document == nil {
+				continue
+			}
+			encoded, err := json.Marshal(values.Normalize(document))
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to encode yaml document %d, error: %s", len(documents), err)
+			}
+			decoded = append(decoded, string(encoded))
+		}
+		documents = append(documents, x)
+	}
+
+	return documents, decoded, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestSplitOutput(t *testing.T) {
+	cases := []struct {
+		Content   string
+		Separator string
+		Expected  []string
+	}{
+		{Content: "a\n#--\nb\n", Separator: "#--", Expected: []string{"a\n", "b\n"}},
+		{Content: "#--\na\n#--\n\n#--\nb", Separator: "#--", Expected: []string{"a\n", "b"}},
+		{Content: "a,b,,c", Separator: ",", Expected: []string{"a", "b", "c"}},
+		{Content: "single", Separator: "#--", Expected: []string{"single"}},
+		{Content: "", Separator: "#--", Expected: []string{}},
+	}
+	for i, x := range cases {
+		if got := splitOutput(x.Content, x.Separator); !reflect.DeepEqual(got, x.Expected) {
+			t.Errorf("case %d, got: %q, want: %q", i, got, x.Expected)
+		}
+	}
+}
+
+func TestGoTemplateSplitOn(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						template = "%s"
+						split_on = "# --- next file"
+					}`, `{{ range split \"a,b\" \",\" }}# --- next file\nname: {{ . }}\n{{ end }}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered_parts.#", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered_parts.0", "name: a\n"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered_parts.1", "name: b\n"),
+				),
+			},
+		},
+	})
+}
+
+func TestSplitYAML(t *testing.T) {
+	content := "---\nkind: A\nitems: [1, 2]\n---\n\n---\n# only a comment\n---\nkind: B\n"
+	documents, decoded, err := splitYAML(content, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := []string{"kind: A\nitems: [1, 2]", "kind: B\n"}; !reflect.DeepEqual(documents, expected) {
+		t.Errorf("got: %q, want: %q", documents, expected)
+	}
+	if expected := []string{`{"items":[1,2],"kind":"A"}`, `{"kind":"B"}`}; !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("got: %q, want: %q", decoded, expected)
+	}
+	if _, _, err := splitYAML("a: [", true); err == nil {
+		t.Errorf("expected an error for invalid yaml")
+	}
+	if documents, _, err := splitYAML("a: [", false); err != nil || len(documents) != 1 {
+		t.Errorf("expected the document to be returned undecoded, got: %q, error: %v", documents, err)
+	}
+}
+
+func TestGoTemplateSplitYAML(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template    = "kind: A\n---\nkind: B\n"
+						split_yaml  = true
+						decode_yaml = true
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "yaml_documents.#", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "yaml_documents.1", "kind: B\n"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "yaml_documents_json.0", `{"kind":"A"}`),
+				),
+			},
+		},
+	})
+}
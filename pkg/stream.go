@@ -0,0 +1,95 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"io"
+)
+
+// outputOptions control the conversion of the rendered template into the payload
+type outputOptions struct {
+	// encoding is the output encoding
+	encoding string
+	// byteOrderMark prefixes the payload with the byte order mark of the encoding
+	byteOrderMark bool
+	// codecs are the compression codecs applied in order
+	codecs []string
+	// sizeLimitProfile and sizeLimitAction guard the size of the payload
+	sizeLimitProfile string
+	sizeLimitAction  string
+}
+
+// outputStream converts the rendered template into the payload as it is written, each stage
+// writing to the next, so the payload is not copied between the stages and the size guard can
+// abort the render once exceeded; compressors buffer internally so the guard sees their output
+// in blocks rather than byte for byte
+type outputStream struct {
+	encoder *encodingWriter
+	// stages are the compressors in the order the content flows through them
+	stages []io.WriteCloser
+	limit  *limitWriter
+}
+
+// newOutputStream returns a stream writing the payload to the writer
+func newOutputStream(w io.Writer, options outputOptions) (*outputStream, error) {
+	stream := &outputStream{
+		limit: &limitWriter{w: w, profile: options.sizeLimitProfile, action: options.sizeLimitAction},
+	}
+	var next io.Writer = stream.limit
+	for i := len(options.codecs) - 1; i >= 0; i-- {
+		compressor, err := newCompressWriter(next, options.codecs[i])
+		if err != nil {
+			return nil, err
+		}
+		stream.stages = append([]io.WriteCloser{compressor}, stream.stages...)
+		next = compressor
+	}
+	encoder, err := newEncodingWriter(next, options.encoding)
+	if err != nil {
+		return nil, err
+	}
+	stream.encoder = encoder
+	if options.byteOrderMark {
+		bom, err := byteOrderMark(options.encoding)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := next.Write(bom); err != nil {
+			return nil, err
+		}
+	}
+
+	return stream, nil
+}
+
+// Write passes the rendered content into the stream
+func (s *outputStream) Write(p []byte) (int, error) {
+	return s.encoder.Write(p)
+}
+
+// Close flushes each of the stages in turn and checks the final size of the payload
+func (s *outputStream) Close() error {
+	if err := s.encoder.Flush(); err != nil {
+		return err
+	}
+	for _, x := range s.stages {
+		if err := x.Close(); err != nil {
+			return err
+		}
+	}
+
+	return s.limit.Close()
+}
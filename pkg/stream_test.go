@@ -0,0 +1,110 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOutputStream(t *testing.T) {
+	content := strings.Repeat("héllo wörld ", 200)
+	cases := []outputOptions{
+		{},
+		{encoding: encodingUTF16LE, byteOrderMark: true},
+		{encoding: encodingLatin1, codecs: []string{compressionGzip}},
+		{byteOrderMark: true, codecs: []string{compressionZstd, compressionBrotli}},
+	}
+	for i, x := range cases {
+		// step: build the expected payload the long way around
+		expected, err := encodeOutput(content, x.encoding)
+		if err != nil {
+			t.Fatalf("case %d, unexpected error: %s", i, err)
+		}
+		if x.byteOrderMark {
+			bom, _ := byteOrderMark(x.encoding)
+			expected = append(bom, expected...)
+		}
+		for _, codec := range x.codecs {
+			if expected, err = compressOutput(expected, codec); err != nil {
+				t.Fatalf("case %d, unexpected error: %s", i, err)
+			}
+		}
+
+		payload := new(bytes.Buffer)
+		stream, err := newOutputStream(payload, x)
+		if err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		// step: write in small chunks, splitting the multibyte characters
+		for j := 0; j < len(content); j += 7 {
+			end := j + 7
+			if end > len(content) {
+				end = len(content)
+			}
+			if _, err := stream.Write([]byte(content[j:end])); err != nil {
+				t.Fatalf("case %d, unexpected error: %s", i, err)
+			}
+		}
+		if err := stream.Close(); err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+			continue
+		}
+		if !bytes.Equal(payload.Bytes(), expected) {
+			t.Errorf("case %d, the streamed payload does not match", i)
+		}
+	}
+}
+
+func TestOutputStreamLimit(t *testing.T) {
+	stream, err := newOutputStream(new(bytes.Buffer), outputOptions{sizeLimitProfile: "aws_user_data", sizeLimitAction: sizeLimitError})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	chunk := []byte(strings.Repeat("x", 1024))
+	var writes int
+	for ; writes < 100; writes++ {
+		if _, err = stream.Write(chunk); err != nil {
+			break
+		}
+	}
+	if err == nil || !strings.Contains(err.Error(), "exceeding the aws_user_data limit") {
+		t.Errorf("expected the size limit error, got: %v", err)
+	}
+	if writes != 16 {
+		t.Errorf("expected the stream to fail once over the limit, writes: %d", writes)
+	}
+}
+
+func TestOutputStreamErrors(t *testing.T) {
+	cases := []outputOptions{
+		{encoding: "ebcdic"},
+		{encoding: encodingLatin1, byteOrderMark: true},
+		{codecs: []string{"lzma"}},
+	}
+	for i, x := range cases {
+		if _, err := newOutputStream(new(bytes.Buffer), x); err == nil {
+			t.Errorf("case %d, expected an error", i)
+		}
+	}
+	stream, _ := newOutputStream(new(bytes.Buffer), outputOptions{encoding: encodingLatin1})
+	stream.Write([]byte("h\xe2\x82"))
+	if _, err := stream.Write([]byte("\xac")); err == nil || !strings.Contains(err.Error(), "offset 1") {
+		t.Errorf("expected an error for a character split across writes, got: %v", err)
+	}
+}
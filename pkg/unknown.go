@@ -0,0 +1,76 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/config"
+)
+
+// unknownKeys returns the sorted dotted paths of the vars which hold, or are composed from,
+// values not known until apply. Terraform defers reading a data source whose arguments are
+// unknown, but values nested within maps can slip through as the unknown placeholder
+func unknownKeys(vars map[string]interface{}) []string {
+	var keys []string
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case string:
+			if strings.Contains(v, config.UnknownVariableValue) {
+				keys = append(keys, prefix)
+			}
+		case map[string]interface{}:
+			for k, x := range v {
+				walk(joinKey(prefix, k), x)
+			}
+		case []interface{}:
+			for i, x := range v {
+				walk(joinKey(prefix, fmt.Sprintf("%d", i)), x)
+			}
+		}
+	}
+	walk("", vars)
+	sort.Strings(keys)
+
+	return keys
+}
+
+// checkUnknown is responsible for refusing to render content or vars holding unknown values,
+// which would otherwise be rendered into the output as the placeholder. The resources defer the
+// render to the apply instead, leaving the error to the read of a data source, which cannot mark
+// its outputs unknown
+func checkUnknown(content string, vars map[string]interface{}) error {
+	if strings.Contains(content, config.UnknownVariableValue) {
+		return fmt.Errorf("the template contains values not known until apply and cannot be rendered yet")
+	}
+	if keys := unknownKeys(vars); len(keys) > 0 {
+		return fmt.Errorf("vars contain values not known until apply and cannot be rendered yet: %s", strings.Join(keys, ", "))
+	}
+
+	return nil
+}
+
+// joinKey joins the key onto the dotted prefix
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+)
+
+func TestUnknownKeys(t *testing.T) {
+	vars := map[string]interface{}{
+		"known":   "value",
+		"id":      config.UnknownVariableValue,
+		"url":     "https://" + config.UnknownVariableValue + "/path",
+		"nested":  map[string]interface{}{"ip": config.UnknownVariableValue, "name": "x"},
+		"servers": []interface{}{"a", config.UnknownVariableValue},
+	}
+	expected := []string{"id", "nested.ip", "servers.1", "url"}
+	if got := unknownKeys(vars); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, want: %v", got, expected)
+	}
+}
+
+func TestCheckUnknown(t *testing.T) {
+	if err := checkUnknown("{{ .name }}", map[string]interface{}{"name": "x"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err := checkUnknown("{{ .name }}", map[string]interface{}{"name": config.UnknownVariableValue})
+	if err == nil || err.Error() != "vars contain values not known until apply and cannot be rendered yet: name" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkUnknown(config.UnknownVariableValue, nil); err == nil {
+		t.Errorf("expected an error for an unknown template")
+	}
+}
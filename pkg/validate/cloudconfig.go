@@ -0,0 +1,226 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// cloudConfigHeader is the first line cloud-init requires of a cloud-config document
+	cloudConfigHeader = "#cloud-config"
+
+	typeBool   = "boolean"
+	typeList   = "list"
+	typeMap    = "map"
+	typeScalar = "scalar"
+)
+
+// cloudConfigKeys are the top level keys of the cloud-init modules and the types they accept
+var cloudConfigKeys = map[string][]string{
+	"allow_public_ssh_keys":      {typeBool},
+	"ansible":                    {typeMap},
+	"apk_repos":                  {typeMap},
+	"apt":                        {typeMap},
+	"apt_pipelining":             {typeBool, typeScalar},
+	"apt_reboot_if_required":     {typeBool},
+	"apt_update":                 {typeBool},
+	"apt_upgrade":                {typeBool},
+	"authkey_hash":               {typeScalar},
+	"bootcmd":                    {typeList},
+	"byobu_by_default":           {typeScalar},
+	"ca-certs":                   {typeMap},
+	"ca_certs":                   {typeMap},
+	"chef":                       {typeMap},
+	"chpasswd":                   {typeMap},
+	"cloud_config_modules":       {typeList},
+	"cloud_final_modules":        {typeList},
+	"cloud_init_modules":         {typeList},
+	"create_hostname_file":       {typeBool},
+	"datasource":                 {typeMap},
+	"device_aliases":             {typeMap},
+	"disable_ec2_metadata":       {typeBool},
+	"disable_root":               {typeBool},
+	"disable_root_opts":          {typeScalar},
+	"disk_setup":                 {typeMap},
+	"drivers":                    {typeMap},
+	"fan":                        {typeMap},
+	"final_message":              {typeScalar},
+	"fqdn":                       {typeScalar},
+	"fs_setup":                   {typeList},
+	"groups":                     {typeList, typeMap, typeScalar},
+	"growpart":                   {typeMap},
+	"hostname":                   {typeScalar},
+	"keyboard":                   {typeMap},
+	"landscape":                  {typeMap},
+	"locale":                     {typeScalar, typeBool},
+	"locale_configfile":          {typeScalar},
+	"lxd":                        {typeMap},
+	"manage_etc_hosts":           {typeBool, typeScalar},
+	"manage_resolv_conf":         {typeBool},
+	"mcollective":                {typeMap},
+	"merge_how":                  {typeList, typeScalar},
+	"merge_type":                 {typeList, typeScalar},
+	"mount_default_fields":       {typeList},
+	"mounts":                     {typeList},
+	"no_ssh_fingerprints":        {typeBool},
+	"ntp":                        {typeMap},
+	"output":                     {typeMap},
+	"package_reboot_if_required": {typeBool},
+	"package_update":             {typeBool},
+	"package_upgrade":            {typeBool},
+	"packages":                   {typeList},
+	"password":                   {typeScalar},
+	"phone_home":                 {typeMap},
+	"power_state":                {typeMap},
+	"prefer_fqdn_over_hostname":  {typeBool},
+	"preserve_hostname":          {typeBool},
+	"puppet":                     {typeMap},
+	"random_seed":                {typeMap},
+	"reporting":                  {typeMap},
+	"resize_rootfs":              {typeBool, typeScalar},
+	"resolv_conf":                {typeMap},
+	"rh_subscription":            {typeMap},
+	"rsyslog":                    {typeMap},
+	"runcmd":                     {typeList},
+	"salt_minion":                {typeMap},
+	"seed_random":                {typeMap},
+	"snap":                       {typeMap},
+	"spacewalk":                  {typeMap},
+	"ssh":                        {typeMap},
+	"ssh_authorized_keys":        {typeList},
+	"ssh_deletekeys":             {typeBool},
+	"ssh_fp_console_blacklist":   {typeList},
+	"ssh_genkeytypes":            {typeList},
+	"ssh_import_id":              {typeList},
+	"ssh_key_console_blacklist":  {typeList},
+	"ssh_keys":                   {typeMap},
+	"ssh_publish_hostkeys":       {typeMap},
+	"ssh_pwauth":                 {typeBool, typeScalar},
+	"ssh_quiet_keygen":           {typeBool},
+	"swap":                       {typeMap},
+	"system_info":                {typeMap},
+	"timezone":                   {typeScalar},
+	"ubuntu_advantage":           {typeMap},
+	"ubuntu_pro":                 {typeMap},
+	"updates":                    {typeMap},
+	"user":                       {typeMap, typeScalar},
+	"users":                      {typeList, typeMap, typeScalar},
+	"vendor_data":                {typeMap},
+	"wireguard":                  {typeMap},
+	"write_files":                {typeList},
+	"yum_repo_dir":               {typeScalar},
+	"yum_repos":                  {typeMap},
+	"zypper":                     {typeMap},
+}
+
+// writeFileKeys are the keys of an entry of write_files
+var writeFileKeys = []string{"append", "content", "defer", "encoding", "owner", "path", "permissions", "source"}
+
+// CloudConfig checks the content is a #cloud-config document of the known cloud-init keys, each
+// holding a value of the expected type
+func CloudConfig(content string, options Options) error {
+	if firstLine := strings.SplitN(content, "\n", 2)[0]; strings.TrimSpace(firstLine) != cloudConfigHeader {
+		return fmt.Errorf("the first line must be %s", cloudConfigHeader)
+	}
+	var decoded interface{}
+	if err := yaml.Unmarshal([]byte(content), &decoded); err != nil {
+		return fmt.Errorf("invalid yaml: %s", err)
+	}
+	if decoded == nil {
+		return nil
+	}
+	config, ok := normalizeYAML(decoded).(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a map of cloud-init modules")
+	}
+
+	var keys []string
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []string
+	for _, key := range keys {
+		types, found := cloudConfigKeys[key]
+		if !found {
+			errs = append(errs, fmt.Sprintf("unknown key: %s, is it indented correctly", key))
+			continue
+		}
+		if kind := yamlKind(config[key]); !containsString(types, kind) {
+			errs = append(errs, fmt.Sprintf("%s must be a %s, got a %s", key, strings.Join(types, " or "), kind))
+		}
+	}
+	errs = append(errs, checkWriteFiles(config["write_files"])...)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// checkWriteFiles checks every entry of write_files is a map of the known keys with a path
+func checkWriteFiles(value interface{}) []string {
+	entries, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var errs []string
+	for i, x := range entries {
+		entry, ok := x.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("write_files[%d] must be a map", i))
+			continue
+		}
+		if path, ok := entry["path"].(string); !ok || path == "" {
+			errs = append(errs, fmt.Sprintf("write_files[%d] is missing the path", i))
+		}
+		var keys []string
+		for key := range entry {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !containsString(writeFileKeys, key) {
+				errs = append(errs, fmt.Sprintf("write_files[%d] has an unknown key: %s", i, key))
+			}
+		}
+	}
+
+	return errs
+}
+
+// yamlKind returns the type of the decoded yaml value, strings and numbers being scalars
+func yamlKind(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return typeMap
+	case []interface{}:
+		return typeList
+	case bool:
+		return typeBool
+	case nil:
+		return "null"
+	}
+
+	return typeScalar
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCloudConfig(t *testing.T) {
+	cases := []struct {
+		Content string
+		Error   string
+	}{
+		{
+			Content: `#cloud-config
+hostname: web-1
+package_update: true
+packages:
+  - nginx
+write_files:
+  - path: /etc/motd
+    content: |
+      welcome
+    permissions: "0644"
+runcmd:
+  - [systemctl, restart, nginx]
+users:
+  - default
+  - name: app
+    groups: docker
+`,
+		},
+		{Content: "#cloud-config\n"},
+		{Content: "#cloud-config\nhostname: 1234\n"},
+		{Content: "hostname: web\n", Error: "the first line must be #cloud-config"},
+		{Content: "#cloud-config\npackages:\n  - a\n - b\n", Error: "invalid yaml"},
+		{Content: "#cloud-config\n- a\n", Error: "expected a map of cloud-init modules"},
+		{Content: "#cloud-config\nwrite_files:\n  - path: /etc/motd\ncontent: hello\n", Error: "unknown key: content, is it indented correctly"},
+		{Content: "#cloud-config\npackages: nginx\n", Error: "packages must be a list, got a scalar"},
+		{Content: "#cloud-config\npackage_update: yes please\n", Error: "package_update must be a boolean, got a scalar"},
+		{Content: "#cloud-config\nntp:\n", Error: "ntp must be a map, got a null"},
+		{Content: "#cloud-config\nwrite_files:\n  - content: hello\n", Error: "write_files[0] is missing the path"},
+		{Content: "#cloud-config\nwrite_files:\n  - path: /a\n    mode: 0644\n", Error: "write_files[0] has an unknown key: mode"},
+		{Content: "#cloud-config\nwrite_files:\n  - /a\n", Error: "write_files[0] must be a map"},
+	}
+	for i, x := range cases {
+		err := CloudConfig(x.Content, Options{})
+		if x.Error == "" && err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+		}
+		if x.Error != "" && (err == nil || !strings.Contains(err.Error(), x.Error)) {
+			t.Errorf("case %d, expected error: %q, got: %v", i, x.Error, err)
+		}
+	}
+}
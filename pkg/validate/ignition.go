@@ -0,0 +1,123 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ignitionVersion matches the semantic version of the ignition spec
+var ignitionVersion = regexp.MustCompile(`^([23])\.[0-9]+\.[0-9]+(-experimental)?$`)
+
+// ignitionSections are the top level sections of an ignition config, by spec major version
+var ignitionSections = map[string][]string{
+	"2": {"ignition", "networkd", "passwd", "storage", "systemd"},
+	"3": {"ignition", "kernelArguments", "passwd", "storage", "systemd"},
+}
+
+// Ignition checks the content is a structurally valid ignition config
+func Ignition(content string, options Options) error {
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		return fmt.Errorf("invalid json: %s", err)
+	}
+
+	// step: check the ignition section and version
+	section, ok := config["ignition"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("missing the ignition section")
+	}
+	version, ok := section["version"].(string)
+	if !ok {
+		return fmt.Errorf("missing the ignition.version")
+	}
+	matches := ignitionVersion.FindStringSubmatch(version)
+	if matches == nil {
+		return fmt.Errorf("unsupported ignition.version: %s", version)
+	}
+	sections := ignitionSections[matches[1]]
+	for key := range config {
+		if !containsString(sections, key) {
+			return fmt.Errorf("unknown section: %s, expected one of: %s", key, strings.Join(sections, ", "))
+		}
+	}
+
+	// step: check the entries which must be named
+	checks := []struct {
+		Section string
+		List    string
+		Key     string
+	}{
+		{Section: "storage", List: "directories", Key: "path"},
+		{Section: "storage", List: "files", Key: "path"},
+		{Section: "storage", List: "links", Key: "path"},
+		{Section: "systemd", List: "units", Key: "name"},
+		{Section: "passwd", List: "users", Key: "name"},
+		{Section: "passwd", List: "groups", Key: "name"},
+	}
+	for _, x := range checks {
+		if err := checkIgnitionList(config, x.Section, x.List, x.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkIgnitionList checks every entry of section.list is an object with a non-empty key
+func checkIgnitionList(config map[string]interface{}, section, list, key string) error {
+	value, found := config[section]
+	if !found {
+		return nil
+	}
+	s, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s must be an object", section)
+	}
+	value, found = s[list]
+	if !found {
+		return nil
+	}
+	entries, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s.%s must be a list", section, list)
+	}
+	for i, x := range entries {
+		entry, ok := x.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s.%s[%d] must be an object", section, list, i)
+		}
+		if name, ok := entry[key].(string); !ok || name == "" {
+			return fmt.Errorf("%s.%s[%d] is missing the %s", section, list, i, key)
+		}
+	}
+
+	return nil
+}
+
+// containsString checks if the list contains the value
+func containsString(list []string, value string) bool {
+	for _, x := range list {
+		if x == value {
+			return true
+		}
+	}
+
+	return false
+}
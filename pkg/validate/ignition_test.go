@@ -0,0 +1,51 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"testing"
+)
+
+func TestIgnition(t *testing.T) {
+	cases := []struct {
+		Content string
+		Valid   bool
+	}{
+		{Content: `{"ignition": {"version": "3.3.0"}}`, Valid: true},
+		{Content: `{"ignition": {"version": "2.2.0"}, "networkd": {}}`, Valid: true},
+		{
+			Content: `{"ignition": {"version": "3.3.0"}, "storage": {"files": [{"path": "/etc/motd"}]}, "systemd": {"units": [{"name": "a.service"}]}}`,
+			Valid:   true,
+		},
+		{Content: `not json`},
+		{Content: `{}`},
+		{Content: `{"ignition": {}}`},
+		{Content: `{"ignition": {"version": "1.0"}}`},
+		{Content: `{"ignition": {"version": "3.3.0"}, "networkd": {}}`},
+		{Content: `{"ignition": {"version": "3.3.0"}, "storage": {"files": [{"mode": 420}]}}`},
+		{Content: `{"ignition": {"version": "3.3.0"}, "systemd": {"units": {}}}`},
+		{Content: `{"ignition": {"version": "3.3.0"}, "systemd": []}`},
+	}
+	for i, x := range cases {
+		err := Ignition(x.Content, Options{})
+		if x.Valid && err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+		}
+		if !x.Valid && err == nil {
+			t.Errorf("case %d, expected an error", i)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// jsonSchema is the subset of json schema used by the kubernetes standalone schemas
+type jsonSchema struct {
+	Type                 interface{}            `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Required             []string               `json:"required"`
+	Enum                 []interface{}          `json:"enum"`
+	OneOf                []*jsonSchema          `json:"oneOf"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+
+	// forbidAdditional is set when additionalProperties is false
+	forbidAdditional bool
+	// additional is the schema of any additional properties
+	additional *jsonSchema
+}
+
+// parseSchema decodes a json schema document
+func parseSchema(content []byte) (*jsonSchema, error) {
+	schema := &jsonSchema{}
+	if err := json.Unmarshal(content, schema); err != nil {
+		return nil, err
+	}
+	if err := schema.resolve(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// validate checks the value against the schema, returning a list of violations
+func (s *jsonSchema) validate(path string, value interface{}) []string {
+	if s == nil {
+		return nil
+	}
+	var errs []string
+
+	if types := s.types(); len(types) > 0 && !matchesType(types, value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), typeOf(value))}
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, value) {
+		errs = append(errs, fmt.Sprintf("%s: value %v is not one of %v", path, value, s.Enum))
+	}
+	for _, x := range s.AllOf {
+		errs = append(errs, x.validate(path, value)...)
+	}
+	if len(s.AnyOf) > 0 && !anyValid(s.AnyOf, path, value) {
+		errs = append(errs, fmt.Sprintf("%s: value does not match any of the permitted schemas", path))
+	}
+	if len(s.OneOf) > 0 && !anyValid(s.OneOf, path, value) {
+		errs = append(errs, fmt.Sprintf("%s: value does not match any of the permitted schemas", path))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, found := v[name]; !found {
+				errs = append(errs, fmt.Sprintf("%s: missing required field %q", path, name))
+			}
+		}
+		var keys []string
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if property, found := s.Properties[k]; found {
+				errs = append(errs, property.validate(path+"."+k, v[k])...)
+				continue
+			}
+			if s.forbidAdditional {
+				errs = append(errs, fmt.Sprintf("%s: unknown field %q", path, k))
+				continue
+			}
+			errs = append(errs, s.additional.validate(path+"."+k, v[k])...)
+		}
+	case []interface{}:
+		for i, x := range v {
+			errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), x)...)
+		}
+	}
+
+	return errs
+}
+
+// types returns the permitted types of the schema
+func (s *jsonSchema) types() []string {
+	switch v := s.Type.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var list []string
+		for _, x := range v {
+			if name, ok := x.(string); ok {
+				list = append(list, name)
+			}
+		}
+		return list
+	}
+
+	return nil
+}
+
+// resolve decodes the additionalProperties of the schema and its children
+func (s *jsonSchema) resolve() error {
+	if s == nil {
+		return nil
+	}
+	switch raw := strings.TrimSpace(string(s.AdditionalProperties)); raw {
+	case "", "true":
+	case "false":
+		s.forbidAdditional = true
+	default:
+		s.additional = &jsonSchema{}
+		if err := json.Unmarshal(s.AdditionalProperties, s.additional); err != nil {
+			return err
+		}
+	}
+
+	children := []*jsonSchema{s.Items, s.additional}
+	for _, x := range s.Properties {
+		children = append(children, x)
+	}
+	children = append(children, s.OneOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.AllOf...)
+	for _, x := range children {
+		if err := x.resolve(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// anyValid checks if the value is valid against any of the schemas
+func anyValid(schemas []*jsonSchema, path string, value interface{}) bool {
+	for _, x := range schemas {
+		if len(x.validate(path, value)) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesType checks the value is one of the json types
+func matchesType(types []string, value interface{}) bool {
+	actual := typeOf(value)
+	for _, x := range types {
+		if x == actual || (x == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeOf returns the json type of the value
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// containsValue checks the list contains the value
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, x := range list {
+		if fmt.Sprintf("%v", x) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+
+	return false
+}
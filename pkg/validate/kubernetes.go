@@ -0,0 +1,153 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// yamlSeparator matches a yaml document separator line
+var yamlSeparator = regexp.MustCompile(`^---(\s.*)?$`)
+
+// Kubernetes checks each document of the content against the kubernetes json schemas, the schemas
+// being read from an offline bundle laid out as per kubernetes-json-schema, i.e.
+// <schemas>/v1.27.0-standalone-strict/deployment-apps-v1.json
+func Kubernetes(content string, options Options) error {
+	if options.KubernetesSchemas == "" {
+		return fmt.Errorf("the kubernetes validation requires the path to the schemas bundle")
+	}
+	schemas := make(map[string]*jsonSchema)
+
+	var errs []string
+	for i, document := range SplitYAMLDocuments(content) {
+		var decoded interface{}
+		if err := yaml.Unmarshal([]byte(document), &decoded); err != nil {
+			errs = append(errs, fmt.Sprintf("document %d: invalid yaml: %s", i+1, err))
+			continue
+		}
+		if decoded == nil {
+			continue
+		}
+		manifest, ok := normalizeYAML(decoded).(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("document %d: expected a kubernetes object", i+1))
+			continue
+		}
+		kind, _ := manifest["kind"].(string)
+		apiVersion, _ := manifest["apiVersion"].(string)
+		if kind == "" || apiVersion == "" {
+			errs = append(errs, fmt.Sprintf("document %d: missing the kind or apiVersion", i+1))
+			continue
+		}
+
+		key := apiVersion + "/" + kind
+		schema, found := schemas[key]
+		if !found {
+			var err error
+			if schema, err = loadKubernetesSchema(options, apiVersion, kind); err != nil {
+				errs = append(errs, fmt.Sprintf("document %d (%s): %s", i+1, kind, err))
+				continue
+			}
+			schemas[key] = schema
+		}
+		for _, x := range schema.validate(kind, manifest) {
+			errs = append(errs, fmt.Sprintf("document %d: %s", i+1, x))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// loadKubernetesSchema reads the schema for the resource from the bundle
+func loadKubernetesSchema(options Options, apiVersion, kind string) (*jsonSchema, error) {
+	version := "master"
+	if options.KubernetesVersion != "" {
+		version = "v" + strings.TrimPrefix(options.KubernetesVersion, "v")
+	}
+
+	// step: the filename is <kind>-<group>-<version>.json, the group being omitted for the core api
+	name := strings.ToLower(kind)
+	if index := strings.Index(apiVersion, "/"); index > 0 {
+		group := strings.Split(apiVersion[:index], ".")[0]
+		name += "-" + strings.ToLower(group) + "-" + strings.ToLower(apiVersion[index+1:])
+	} else {
+		name += "-" + strings.ToLower(apiVersion)
+	}
+	name += ".json"
+
+	for _, dir := range []string{version + "-standalone-strict", version + "-standalone"} {
+		path := filepath.Join(options.KubernetesSchemas, dir, name)
+		content, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		schema, err := parseSchema(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %s, error: %s", path, err)
+		}
+		return schema, nil
+	}
+
+	return nil, fmt.Errorf("no schema found for %s %s in kubernetes %s", apiVersion, kind, version)
+}
+
+// SplitYAMLDocuments splits the content on the yaml document separators
+func SplitYAMLDocuments(content string) []string {
+	var documents []string
+	var current []string
+	for _, line := range strings.Split(content, "\n") {
+		if yamlSeparator.MatchString(strings.TrimRight(line, "\r")) {
+			documents = append(documents, strings.Join(current, "\n"))
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+
+	return append(documents, strings.Join(current, "\n"))
+}
+
+// normalizeYAML converts the decoded yaml maps into string keyed maps
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[fmt.Sprintf("%v", k)] = normalizeYAML(x)
+		}
+		return m
+	case []interface{}:
+		for i, x := range v {
+			v[i] = normalizeYAML(x)
+		}
+		return v
+	}
+
+	return value
+}
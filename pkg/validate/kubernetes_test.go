@@ -0,0 +1,116 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+var testKubernetesOptions = Options{KubernetesSchemas: "testdata", KubernetesVersion: "1.27.0"}
+
+func TestKubernetes(t *testing.T) {
+	valid := `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+# an empty document
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  strategy:
+    rollingUpdate:
+      maxSurge: 25%
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx
+`
+	if err := Kubernetes(valid, testKubernetesOptions); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestKubernetesErrors(t *testing.T) {
+	cases := []struct {
+		Content string
+		Errors  []string
+	}{
+		{
+			Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  lables: {}\n",
+			Errors:  []string{`document 1: ConfigMap.metadata: unknown field "lables"`},
+		},
+		{
+			Content: "apiVersion: v1\nkind: ConfigMap\n---\napiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: three\n  selector: {}\n",
+			Errors:  []string{"document 2: Deployment.spec.replicas: expected integer or null, got string"},
+		},
+		{
+			Content: "apiVersion: apps/v1\nkind: Deployment\nspec:\n  template:\n    spec:\n      containers:\n        - image: nginx\n",
+			Errors: []string{
+				`Deployment.spec: missing required field "selector"`,
+				`Deployment.spec.template.spec.containers[0]: missing required field "name"`,
+			},
+		},
+		{
+			Content: "apiVersion: apps/v1\nkind: Deployment\nspec:\n  selector: {}\n  strategy:\n    rollingUpdate:\n      maxSurge: true\n",
+			Errors:  []string{"maxSurge: value does not match any of the permitted schemas"},
+		},
+		{Content: "apiVersion: v1\nkind: Secret\n", Errors: []string{"no schema found for v1 Secret in kubernetes v1.27.0"}},
+		{Content: "kind: ConfigMap\n", Errors: []string{"document 1: missing the kind or apiVersion"}},
+		{Content: "- a\n- b\n", Errors: []string{"document 1: expected a kubernetes object"}},
+		{Content: "a: [\n", Errors: []string{"document 1: invalid yaml"}},
+	}
+	for i, x := range cases {
+		err := Kubernetes(x.Content, testKubernetesOptions)
+		if err == nil {
+			t.Errorf("case %d, expected an error", i)
+			continue
+		}
+		for _, expected := range x.Errors {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("case %d, expected %q in error: %s", i, expected, err)
+			}
+		}
+	}
+}
+
+func TestKubernetesNoSchemas(t *testing.T) {
+	if err := Kubernetes("apiVersion: v1\nkind: ConfigMap\n", Options{}); err == nil {
+		t.Error("expected an error without a schemas bundle")
+	}
+}
+
+func TestSplitYAMLDocuments(t *testing.T) {
+	documents := SplitYAMLDocuments("a: 1\n---\nb: 2\n--- # comment\nc: 3\n----\n")
+	if len(documents) != 3 {
+		t.Fatalf("expected 3 documents, got: %d, %q", len(documents), documents)
+	}
+	if documents[2] != "c: 3\n----\n" {
+		t.Errorf("unexpected document: %q", documents[2])
+	}
+}
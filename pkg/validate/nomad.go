@@ -0,0 +1,236 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+// nomadJobTypes are the schedulers a job may use
+var nomadJobTypes = []string{"batch", "service", "sysbatch", "system"}
+
+// Nomad checks the content is a structurally valid nomad job specification, either in hcl, the
+// json form of the hcl or the json of the jobs api
+func Nomad(content string, options Options) error {
+	// step: the jobs api wraps the job in a Job object
+	if strings.HasPrefix(strings.TrimSpace(content), "{") {
+		var api map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(content), &api); err == nil && api["Job"] != nil {
+			job := &nomadAPIJob{}
+			if err := json.Unmarshal(api["Job"], job); err != nil {
+				return fmt.Errorf("invalid job: %s", err)
+			}
+			return job.check()
+		}
+	}
+
+	file, err := hcl.Parse(content)
+	if err != nil {
+		return fmt.Errorf("invalid hcl: %s", err)
+	}
+	root, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return fmt.Errorf("expected a job block")
+	}
+	jobs := nomadBlocks(root, "job")
+	if len(jobs.Items) != 1 {
+		return fmt.Errorf("expected a single job block, found %d", len(jobs.Items))
+	}
+	job := jobs.Items[0]
+	name, body, err := nomadBlock(job, "job")
+	if err != nil {
+		return err
+	}
+	if value, found := nomadString(body, "type"); found && !containsString(nomadJobTypes, value) {
+		return fmt.Errorf("job %s has an unknown type: %s, expected one of: %s", name, value, strings.Join(nomadJobTypes, ", "))
+	}
+
+	// step: check the groups and tasks, a task directly in the job being given a group of its own
+	groups := nomadBlocks(body, "group")
+	tasks := nomadBlocks(body, "task")
+	if len(groups.Items)+len(tasks.Items) == 0 {
+		return fmt.Errorf("job %s has no groups", name)
+	}
+	seen := make(map[string]bool)
+	for _, x := range groups.Items {
+		group, body, err := nomadBlock(x, "group")
+		if err != nil {
+			return err
+		}
+		if seen[group] {
+			return fmt.Errorf("line %d: group %s is defined more than once", x.Pos().Line, group)
+		}
+		seen[group] = true
+		groupTasks := nomadBlocks(body, "task")
+		if len(groupTasks.Items) == 0 {
+			return fmt.Errorf("line %d: group %s has no tasks", x.Pos().Line, group)
+		}
+		if err := checkNomadTasks(group, groupTasks); err != nil {
+			return err
+		}
+	}
+
+	return checkNomadTasks(name, tasks)
+}
+
+// checkNomadTasks checks the tasks are named uniquely and each has a driver
+func checkNomadTasks(group string, tasks *ast.ObjectList) error {
+	seen := make(map[string]bool)
+	for _, x := range tasks.Items {
+		task, body, err := nomadBlock(x, "task")
+		if err != nil {
+			return err
+		}
+		if seen[task] {
+			return fmt.Errorf("line %d: task %s is defined more than once in group %s", x.Pos().Line, task, group)
+		}
+		seen[task] = true
+		if driver, _ := nomadString(body, "driver"); driver == "" {
+			return fmt.Errorf("line %d: task %s is missing the driver", x.Pos().Line, task)
+		}
+	}
+
+	return nil
+}
+
+// nomadBlocks returns the blocks of the kind, expanding the json form where the labelled blocks are
+// nested within an object or flattened into the keys
+func nomadBlocks(list *ast.ObjectList, kind string) *ast.ObjectList {
+	blocks := &ast.ObjectList{}
+	flattened := make(map[string]*ast.ObjectList)
+	for _, x := range list.Filter(kind).Items {
+		// step: the json parser flattens the nested objects into the keys of an item per leaf, so
+		// the items sharing a label are gathered back into one block
+		if len(x.Keys) > 1 {
+			label, _ := x.Keys[0].Token.Value().(string)
+			body, found := flattened[label]
+			if !found {
+				body = &ast.ObjectList{}
+				flattened[label] = body
+				blocks.Add(&ast.ObjectItem{Keys: x.Keys[:1], Val: &ast.ObjectType{List: body}})
+			}
+			body.Add(&ast.ObjectItem{Keys: x.Keys[1:], Val: x.Val})
+			continue
+		}
+		if object, ok := x.Val.(*ast.ObjectType); ok && len(x.Keys) == 0 {
+			for _, child := range object.List.Items {
+				if _, ok := child.Val.(*ast.ObjectType); ok {
+					blocks.Add(child)
+				}
+			}
+			continue
+		}
+		blocks.Add(x)
+	}
+
+	return blocks
+}
+
+// nomadBlock returns the label and body of a block which must have a single label
+func nomadBlock(item *ast.ObjectItem, kind string) (string, *ast.ObjectList, error) {
+	if len(item.Keys) != 1 {
+		return "", nil, fmt.Errorf("line %d: %s block must have a single name label", item.Pos().Line, kind)
+	}
+	name, _ := item.Keys[0].Token.Value().(string)
+	if name == "" {
+		return "", nil, fmt.Errorf("line %d: %s block must have a name", item.Pos().Line, kind)
+	}
+	body, ok := item.Val.(*ast.ObjectType)
+	if !ok {
+		return "", nil, fmt.Errorf("line %d: %s %s must be a block", item.Pos().Line, kind, name)
+	}
+
+	return name, body.List, nil
+}
+
+// nomadString returns the value of a string attribute, if any
+func nomadString(body *ast.ObjectList, key string) (string, bool) {
+	for _, x := range body.Items {
+		if len(x.Keys) != 1 || x.Keys[0].Token.Value() != key {
+			continue
+		}
+		if literal, ok := x.Val.(*ast.LiteralType); ok {
+			value, ok := literal.Token.Value().(string)
+			return value, ok
+		}
+	}
+
+	return "", false
+}
+
+// nomadAPIJob is the subset of a job in the json of the jobs api that we validate
+type nomadAPIJob struct {
+	ID         string
+	Name       string
+	Type       string
+	TaskGroups []struct {
+		Name  string
+		Tasks []struct {
+			Name   string
+			Driver string
+		}
+	}
+}
+
+// check validates the job has named groups and tasks with drivers
+func (j *nomadAPIJob) check() error {
+	name := j.ID
+	if name == "" {
+		name = j.Name
+	}
+	if name == "" {
+		return fmt.Errorf("job is missing the ID")
+	}
+	if j.Type != "" && !containsString(nomadJobTypes, j.Type) {
+		return fmt.Errorf("job %s has an unknown type: %s, expected one of: %s", name, j.Type, strings.Join(nomadJobTypes, ", "))
+	}
+	if len(j.TaskGroups) == 0 {
+		return fmt.Errorf("job %s has no groups", name)
+	}
+	groups := make(map[string]bool)
+	for i, group := range j.TaskGroups {
+		if group.Name == "" {
+			return fmt.Errorf("TaskGroups[%d] is missing the Name", i)
+		}
+		if groups[group.Name] {
+			return fmt.Errorf("group %s is defined more than once", group.Name)
+		}
+		groups[group.Name] = true
+		if len(group.Tasks) == 0 {
+			return fmt.Errorf("group %s has no tasks", group.Name)
+		}
+		tasks := make(map[string]bool)
+		for k, task := range group.Tasks {
+			if task.Name == "" {
+				return fmt.Errorf("group %s Tasks[%d] is missing the Name", group.Name, k)
+			}
+			if tasks[task.Name] {
+				return fmt.Errorf("task %s is defined more than once in group %s", task.Name, group.Name)
+			}
+			tasks[task.Name] = true
+			if task.Driver == "" {
+				return fmt.Errorf("task %s is missing the driver", task.Name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNomad(t *testing.T) {
+	cases := []struct {
+		Content string
+		Error   string
+	}{
+		{
+			Content: `
+job "web" {
+  datacenters = ["dc1"]
+  type        = "service"
+
+  group "app" {
+    count = 2
+    task "server" {
+      driver = "docker"
+      config {
+        image = "nginx"
+      }
+    }
+    task "sidecar" {
+      driver = "exec"
+    }
+  }
+}`,
+		},
+		{Content: `job "batch" { task "run" { driver = "exec" } }`},
+		{Content: `{"job": {"web": {"group": {"app": {"task": {"server": {"driver": "docker"}}}}}}}`},
+		{Content: `{"job": {"web": {"group": {"a": {"task": {"t": {"driver": "exec"}}}, "b": {"task": {"t": {"driver": "exec"}, "u": {"driver": "raw_exec"}}}}}}}`},
+		{Content: `{"job": {"web": {"group": {"app": {"task": {"server": {"image": "nginx"}}}}}}}`, Error: "task server is missing the driver"},
+		{Content: `{"Job": {"ID": "web", "Type": "batch", "TaskGroups": [{"Name": "app", "Tasks": [{"Name": "server", "Driver": "docker"}]}]}}`},
+		{Content: `job "web" {`, Error: "invalid hcl"},
+		{Content: `group "app" {}`, Error: "expected a single job block, found 0"},
+		{Content: `job "a" { group "x" { task "t" { driver = "exec" } } } job "b" {}`, Error: "expected a single job block, found 2"},
+		{Content: `job { group "x" {} }`, Error: "job block must have a single name label"},
+		{Content: `job "web" { type = "cron" group "x" { task "t" { driver = "exec" } } }`, Error: "unknown type: cron"},
+		{Content: `job "web" { datacenters = ["dc1"] }`, Error: "job web has no groups"},
+		{Content: `job "web" { group "x" { count = 1 } }`, Error: "group x has no tasks"},
+		{Content: `job "web" { group "x" { task "t" { driver = "exec" } } group "x" { task "t" { driver = "exec" } } }`, Error: "group x is defined more than once"},
+		{Content: "job \"web\" {\n  group \"x\" {\n    task \"t\" {\n      config {}\n    }\n  }\n}", Error: "line 3: task t is missing the driver"},
+		{Content: `job "web" { group "x" { task "t" { driver = "exec" } task "t" { driver = "exec" } } }`, Error: "task t is defined more than once in group x"},
+		{Content: `{"Job": {"ID": "web", "TaskGroups": []}}`, Error: "job web has no groups"},
+		{Content: `{"Job": {"ID": "web", "TaskGroups": [{"Name": "app", "Tasks": [{"Name": "server"}]}]}}`, Error: "task server is missing the driver"},
+		{Content: `{"Job": {"TaskGroups": []}}`, Error: "job is missing the ID"},
+	}
+	for i, x := range cases {
+		err := Nomad(x.Content, Options{})
+		if x.Error == "" && err != nil {
+			t.Errorf("case %d, unexpected error: %s", i, err)
+		}
+		if x.Error != "" && (err == nil || !strings.Contains(err.Error(), x.Error)) {
+			t.Errorf("case %d, expected error: %q, got: %v", i, x.Error, err)
+		}
+	}
+}
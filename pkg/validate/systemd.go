@@ -0,0 +1,122 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// systemdSection matches a section header
+	systemdSection = regexp.MustCompile(`^\[([^\]]+)\]$`)
+	// systemdKey matches a valid key name
+	systemdKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+)
+
+// systemdSections are the sections found in unit files
+var systemdSections = []string{
+	"Automount", "Device", "Install", "Mount", "Path", "Scope", "Service",
+	"Slice", "Socket", "Swap", "Target", "Timer", "Unit",
+}
+
+// systemdKeys are the commonly used keys, used to catch typos in their case
+var systemdKeys = []string{
+	"After", "Alias", "Also", "Before", "BindsTo", "Conflicts", "DefaultInstance", "Description",
+	"Documentation", "Environment", "EnvironmentFile", "ExecReload", "ExecStart", "ExecStartPost",
+	"ExecStartPre", "ExecStop", "ExecStopPost", "Group", "KillMode", "KillSignal", "LimitNOFILE",
+	"ListenStream", "OnBootSec", "OnCalendar", "OnUnitActiveSec", "PIDFile", "PartOf", "Persistent",
+	"RemainAfterExit", "Requires", "RequiredBy", "Restart", "RestartSec", "StandardError",
+	"StandardOutput", "TimeoutStartSec", "TimeoutStopSec", "Type", "Unit", "User", "Wants",
+	"WantedBy", "WorkingDirectory",
+}
+
+// SystemdUnit checks the content is a structurally valid systemd unit file
+func SystemdUnit(content string, options Options) error {
+	var errs []string
+	section := ""
+	continued := false
+
+	for i, line := range strings.Split(content, "\n") {
+		number := i + 1
+		line = strings.TrimSpace(line)
+
+		// step: skip over the continuation of a value
+		if continued {
+			continued = strings.HasSuffix(line, "\\")
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			matches := systemdSection.FindStringSubmatch(line)
+			if matches == nil {
+				errs = append(errs, fmt.Sprintf("line %d: malformed section header: %s", number, line))
+				continue
+			}
+			section = matches[1]
+			if !containsString(systemdSections, section) && !strings.HasPrefix(section, "X-") {
+				message := fmt.Sprintf("line %d: unknown section: [%s]", number, section)
+				if suggestion := caseInsensitiveMatch(systemdSections, section); suggestion != "" {
+					message += fmt.Sprintf(", did you mean [%s]", suggestion)
+				}
+				errs = append(errs, message)
+			}
+			continue
+		}
+
+		// step: we must have a key=value within a section
+		if section == "" {
+			errs = append(errs, fmt.Sprintf("line %d: assignment outside of a section: %s", number, line))
+			continue
+		}
+		index := strings.Index(line, "=")
+		if index < 0 {
+			errs = append(errs, fmt.Sprintf("line %d: expected key=value, got: %s", number, line))
+			continue
+		}
+		key := strings.TrimSpace(line[:index])
+		if !systemdKey.MatchString(key) {
+			errs = append(errs, fmt.Sprintf("line %d: invalid key: %q", number, key))
+			continue
+		}
+		if suggestion := caseInsensitiveMatch(systemdKeys, key); suggestion != "" && suggestion != key {
+			errs = append(errs, fmt.Sprintf("line %d: unknown key: %s, did you mean %s", number, key, suggestion))
+		}
+		continued = strings.HasSuffix(line, "\\")
+	}
+	if section == "" && len(errs) == 0 {
+		errs = append(errs, "no sections found")
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// caseInsensitiveMatch returns the entry in the list matching the value regardless of case
+func caseInsensitiveMatch(list []string, value string) string {
+	for _, x := range list {
+		if strings.EqualFold(x, value) {
+			return x
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	valid := `# a comment
+[Unit]
+Description=My service
+After=network.target
+
+[Service]
+ExecStart=/usr/bin/app \
+  --flag=value
+Restart=always
+X-Custom=yes
+
+[X-Extension]
+Anything=goes
+
+[Install]
+WantedBy=multi-user.target
+`
+	if err := SystemdUnit(valid, Options{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	cases := []struct {
+		Content string
+		Error   string
+	}{
+		{Content: "", Error: "no sections found"},
+		{Content: "ExecStart=/bin/true", Error: "line 1: assignment outside of a section"},
+		{Content: "[Service\nExecStart=/bin/true", Error: "line 1: malformed section header"},
+		{Content: "[service]\nExecStart=/bin/true", Error: "did you mean [Service]"},
+		{Content: "[Service]\nexecstart=/bin/true", Error: "line 2: unknown key: execstart, did you mean ExecStart"},
+		{Content: "[Service]\nExecStart /bin/true", Error: "line 2: expected key=value"},
+		{Content: "[Service]\nExec Start=/bin/true", Error: "line 2: invalid key"},
+	}
+	for i, x := range cases {
+		err := SystemdUnit(x.Content, Options{})
+		if err == nil || !strings.Contains(err.Error(), x.Error) {
+			t.Errorf("case %d, expected error: %q, got: %v", i, x.Error, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validate provides structural validation of rendered documents
+package validate
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Options are the settings used by the validators
+type Options struct {
+	// KubernetesSchemas is the path to an offline bundle of kubernetes json schemas
+	KubernetesSchemas string
+	// KubernetesVersion is the version of the kubernetes schemas to validate against
+	KubernetesVersion string
+}
+
+// Validator checks the content is a structurally valid document
+type Validator func(content string, options Options) error
+
+// validators is a map of the validation modes we support
+var validators = map[string]Validator{
+	"cloud-config": CloudConfig,
+	"ignition":     Ignition,
+	"kubernetes":   Kubernetes,
+	"nomad":        Nomad,
+	"systemd-unit": SystemdUnit,
+}
+
+// Names returns a sorted list of the validation modes
+func Names() []string {
+	var names []string
+	for name := range validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Validate is responsible for validating the content in the given mode
+func Validate(mode, content string, options Options) error {
+	validator, found := validators[mode]
+	if !found {
+		return fmt.Errorf("unsupported validation mode: %s", mode)
+	}
+	if err := validator(content, options); err != nil {
+		return fmt.Errorf("rendered content is not a valid %s document: %s", mode, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate("ignition", `{"ignition": {"version": "3.3.0"}}`, Options{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := Validate("ignition", `{}`, Options{}); err == nil {
+		t.Error("expected a validation error")
+	}
+	if err := Validate("unknown", "", Options{}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestNames(t *testing.T) {
+	if len(Names()) != len(validators) {
+		t.Errorf("expected all the validators to be listed")
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dotenvKey matches a valid variable name in an env file
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// dotenvEscapes are the escape sequences expanded within double quoted values
+var dotenvEscapes = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`, `\$`, "$")
+
+// ParseDotenv is responsible for decoding an env file of KEY=VALUE lines; blank lines and those
+// starting with a hash are ignored, an export prefix is permitted, single quoted values are
+// taken literally and double quoted values have their escape sequences expanded
+func ParseDotenv(content string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for i, line := range strings.Split(strings.Replace(content, "\r\n", "\n", -1), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		index := strings.Index(line, "=")
+		if index < 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", i+1)
+		}
+		key := strings.TrimSpace(line[:index])
+		if !dotenvKey.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid variable name: %q", i+1, key)
+		}
+		value, err := dotenvValue(strings.TrimSpace(line[index+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// dotenvValue is responsible for unquoting the value of an env file line
+func dotenvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	switch quote := raw[0]; quote {
+	case '"', '\'':
+		end := closingQuote(raw, quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected content after the quoted value: %q", rest)
+		}
+		if quote == '\'' {
+			return raw[1:end], nil
+		}
+		return dotenvEscapes.Replace(raw[1:end]), nil
+	}
+	// step: an unquoted value runs until an inline comment
+	if index := strings.Index(raw, " #"); index >= 0 {
+		raw = raw[:index]
+	}
+
+	return strings.TrimSpace(raw), nil
+}
+
+// closingQuote returns the index of the quote closing the value, skipping escaped double quotes
+func closingQuote(raw string, quote byte) int {
+	for i := 1; i < len(raw); i++ {
+		switch {
+		case quote == '"' && raw[i] == '\\':
+			i++
+		case raw[i] == quote:
+			return i
+		}
+	}
+
+	return -1
+}
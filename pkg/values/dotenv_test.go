@@ -0,0 +1,64 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	content := `# application settings
+APP_NAME=web
+export LOG_LEVEL = debug
+GREETING="hello\nworld" # inline comment
+LITERAL='no \n expansion'
+URL=http://example.com/#anchor
+PLAIN=value # trailing comment
+EMPTY=
+`
+	values, err := ParseDotenv(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"APP_NAME":  "web",
+		"LOG_LEVEL": "debug",
+		"GREETING":  "hello\nworld",
+		"LITERAL":   `no \n expansion`,
+		"URL":       "http://example.com/#anchor",
+		"PLAIN":     "value",
+		"EMPTY":     "",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got: %v, want: %v", values, expected)
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	cases := map[string]string{
+		"NO_EQUALS":       "line 1: expected KEY=VALUE",
+		"A=1\n1BAD=x":     `line 2: invalid variable name: "1BAD"`,
+		`QUOTED="open`:    "line 1: unterminated quoted value",
+		`QUOTED="a" junk`: `line 1: unexpected content after the quoted value: "junk"`,
+	}
+	for content, expected := range cases {
+		_, err := ParseDotenv(content)
+		if err == nil || err.Error() != expected {
+			t.Errorf("content: %q, got: %v, want: %s", content, err, expected)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ParseTfvars is responsible for decoding a terraform .tfvars file, being hcl2 attributes whose
+// values must be literals, as terraform permits no references or function calls in the file
+func ParseTfvars(content string) (map[string]interface{}, error) {
+	file, diags := hclsyntax.ParseConfig([]byte(content), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diagnosticsError("", diags)
+	}
+	attributes, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diagnosticsError("", diags)
+	}
+
+	values := make(map[string]interface{}, len(attributes))
+	for name, x := range attributes {
+		value, diags := x.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diagnosticsError(name, diags)
+		}
+		values[name] = FromCty(value)
+	}
+
+	return values, nil
+}
+
+// diagnosticsError returns the first of the error diagnostics as line: summary; detail, naming
+// the attribute when the diagnostic came from its value
+func diagnosticsError(name string, diags hcl.Diagnostics) error {
+	for _, x := range diags {
+		if x.Severity != hcl.DiagError {
+			continue
+		}
+		message := x.Summary
+		if name != "" {
+			message = name + ": " + message
+		}
+		if x.Detail != "" {
+			message += "; " + x.Detail
+		}
+		subject := x.Subject
+		if subject == nil {
+			subject = x.Context
+		}
+		if subject != nil {
+			return fmt.Errorf("line %d: %s", subject.Start.Line, message)
+		}
+		return fmt.Errorf("%s", message)
+	}
+
+	return diags
+}
+
+// FromCty converts the cty value into the plain go types used by the templates: strings, bools,
+// int64 for whole numbers otherwise float64, lists and maps; null and unknown values become nil
+func FromCty(value cty.Value) interface{} {
+	if value.IsNull() || !value.IsKnown() {
+		return nil
+	}
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		return value.AsString()
+	case ty == cty.Bool:
+		return value.True()
+	case ty == cty.Number:
+		number := value.AsBigFloat()
+		if number.IsInt() {
+			if i, accuracy := number.Int64(); accuracy == 0 {
+				return i
+			}
+		}
+		f, _ := number.Float64()
+		return f
+	case ty.IsTupleType():
+		list := make([]interface{}, 0, len(ty.TupleElementTypes()))
+		for i := range ty.TupleElementTypes() {
+			list = append(list, FromCty(value.Index(cty.NumberIntVal(int64(i)))))
+		}
+		return list
+	case ty.IsObjectType():
+		m := make(map[string]interface{}, len(ty.AttributeTypes()))
+		for name := range ty.AttributeTypes() {
+			m[name] = FromCty(value.GetAttr(name))
+		}
+		return m
+	case ty.IsListType() || ty.IsSetType():
+		list := make([]interface{}, 0, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			list = append(list, FromCty(v))
+		}
+		return list
+	case ty.IsMapType():
+		m := make(map[string]interface{}, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			m[k.AsString()] = FromCty(v)
+		}
+		return m
+	}
+
+	return nil
+}
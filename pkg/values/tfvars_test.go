@@ -0,0 +1,76 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTfvars(t *testing.T) {
+	content := `# the environment settings
+region   = "eu-west-2"
+replicas = 3
+ratio    = 0.5
+enabled  = true
+zones    = ["a", "b"]
+tags = {
+  env  = "prod"
+  team = "platform"
+}
+name = "web-${"01"}"
+`
+	values, err := ParseTfvars(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"region":   "eu-west-2",
+		"replicas": int64(3),
+		"ratio":    0.5,
+		"enabled":  true,
+		"zones":    []interface{}{"a", "b"},
+		"tags":     map[string]interface{}{"env": "prod", "team": "platform"},
+		"name":     "web-01",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got: %v, want: %v", values, expected)
+	}
+}
+
+func TestParseTfvarsErrors(t *testing.T) {
+	cases := []struct {
+		Content string
+		Line    string
+		Key     string
+	}{
+		{Content: "region = ", Line: "line 1: "},
+		{Content: "a = 1\nb = var.region", Line: "line 2: ", Key: "b"},
+		{Content: "a = 1\ntags {\n  a = 1\n}", Line: "line 2: ", Key: "tags"},
+		{Content: "a = 1\nb = upper(\"x\")", Line: "line 2: ", Key: "b"},
+	}
+	for _, c := range cases {
+		_, err := ParseTfvars(c.Content)
+		if err == nil {
+			t.Errorf("content: %q, expected an error", c.Content)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), c.Line) || !strings.Contains(err.Error(), c.Key) {
+			t.Errorf("content: %q, got: %s, want: %s naming %q", c.Content, err, c.Line, c.Key)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// numericString matches a string holding a json number; leading zeros are excluded so values
+// such as zip codes and octal modes remain strings
+var numericString = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+const (
+	// TypeAny accepts any value unchanged
+	TypeAny = "any"
+	// TypeBool is a boolean
+	TypeBool = "bool"
+	// TypeList is a list of elements of the element type
+	TypeList = "list"
+	// TypeMap is a string keyed map of elements of the element type
+	TypeMap = "map"
+	// TypeNumber is an integer or floating point number
+	TypeNumber = "number"
+	// TypeString is a string
+	TypeString = "string"
+)
+
+// Type is the declared type of a variable, i.e. number or map(list(string))
+type Type struct {
+	// Kind is the kind of the type
+	Kind string
+	// Elem is the type of the elements of a list or map
+	Elem *Type
+}
+
+// String returns the type as declared
+func (t Type) String() string {
+	if t.Elem != nil {
+		return t.Kind + "(" + t.Elem.String() + ")"
+	}
+
+	return t.Kind
+}
+
+// ParseType is responsible for parsing a type declaration; a list or map without an element
+// type holds elements of any type
+func ParseType(spec string) (Type, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case TypeAny, TypeBool, TypeNumber, TypeString:
+		return Type{Kind: spec}, nil
+	case TypeList, TypeMap:
+		return Type{Kind: spec, Elem: &Type{Kind: TypeAny}}, nil
+	}
+	for _, kind := range []string{TypeList, TypeMap} {
+		if strings.HasPrefix(spec, kind+"(") && strings.HasSuffix(spec, ")") {
+			elem, err := ParseType(spec[len(kind)+1 : len(spec)-1])
+			if err != nil {
+				return Type{}, err
+			}
+			return Type{Kind: kind, Elem: &elem}, nil
+		}
+	}
+
+	return Type{}, fmt.Errorf("invalid type: %q, expected any, bool, number, string, list(<type>) or map(<type>)", spec)
+}
+
+// Coerce is responsible for converting the variables to their declared types; the strings held
+// by terraform maps are converted to bools and numbers, and decoded as json for lists and maps.
+// A declared variable must be set, the error naming the path of the value which did not conform
+func Coerce(vars map[string]interface{}, types map[string]Type) (map[string]interface{}, error) {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	coerced := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		coerced[k] = v
+	}
+	for _, name := range names {
+		value, found := vars[name]
+		if !found {
+			return nil, fmt.Errorf("variable %s is declared as %s but is not set", name, types[name])
+		}
+		converted, err := coerceValue(value, types[name], name)
+		if err != nil {
+			return nil, err
+		}
+		coerced[name] = converted
+	}
+
+	return coerced, nil
+}
+
+// coerceValue converts the value at the path to the type
+func coerceValue(value interface{}, t Type, path string) (interface{}, error) {
+	mismatch := func() error {
+		return fmt.Errorf("variable %s: expected %s, got %s", path, t, describeValue(value))
+	}
+	switch t.Kind {
+	case TypeAny:
+		return value, nil
+	case TypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case bool, int, int64, float64, json.Number:
+			return fmt.Sprintf("%v", v), nil
+		}
+		return nil, mismatch()
+	case TypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, mismatch()
+	case TypeNumber:
+		switch v := value.(type) {
+		case int, int64, float64:
+			return v, nil
+		case json.Number:
+			return ParseNumber(v.String())
+		case string:
+			if n, err := ParseNumber(strings.TrimSpace(v)); err == nil {
+				return n, nil
+			}
+		}
+		return nil, mismatch()
+	}
+
+	// step: a collection held in a string is decoded as json
+	if s, ok := value.(string); ok {
+		decoder := json.NewDecoder(strings.NewReader(s))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, mismatch()
+		}
+	}
+	switch t.Kind {
+	case TypeList:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, mismatch()
+		}
+		converted := make([]interface{}, len(list))
+		for i, x := range list {
+			item, err := coerceValue(x, *t.Elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = item
+		}
+		return converted, nil
+	case TypeMap:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, mismatch()
+		}
+		converted := make(map[string]interface{}, len(m))
+		for k, x := range m {
+			item, err := coerceValue(x, *t.Elem, path+"."+k)
+			if err != nil {
+				return nil, err
+			}
+			converted[k] = item
+		}
+		return converted, nil
+	}
+
+	return nil, fmt.Errorf("variable %s: unsupported type: %s", path, t)
+}
+
+// CoerceStrings returns the value with the strings "true" and "false" converted to bools and the
+// numeric strings converted to numbers, within lists and maps too; other values are unchanged
+func CoerceStrings(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		switch {
+		case v == "true" || v == "false":
+			return v == "true"
+		case numericString.MatchString(v):
+			if n, err := ParseNumber(v); err == nil {
+				return n
+			}
+		}
+		return v
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, x := range v {
+			converted[i] = CoerceStrings(x)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			converted[k] = CoerceStrings(x)
+		}
+		return converted
+	}
+
+	return value
+}
+
+// ParseNumber parses the string as an int when integral, otherwise as a float
+func ParseNumber(s string) (interface{}, error) {
+	if n, err := strconv.ParseInt(s, 10, 0); err == nil {
+		return int(n), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number: %q", s)
+	}
+
+	return f, nil
+}
+
+// describeValue describes the value for an error message
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "a map"
+	}
+
+	return fmt.Sprintf("%v (%T)", value, value)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseType(t *testing.T) {
+	cases := map[string]string{
+		"number":              "number",
+		" bool ":              "bool",
+		"list":                "list(any)",
+		"map(string)":         "map(string)",
+		"map(list(number))":   "map(list(number))",
+		"list( map(string) )": "list(map(string))",
+	}
+	for spec, expected := range cases {
+		parsed, err := ParseType(spec)
+		if err != nil {
+			t.Errorf("spec: %q, unexpected error: %s", spec, err)
+			continue
+		}
+		if parsed.String() != expected {
+			t.Errorf("spec: %q, got: %s, want: %s", spec, parsed, expected)
+		}
+	}
+	for _, spec := range []string{"", "int", "list(", "map(strings)", "list()"} {
+		if _, err := ParseType(spec); err == nil {
+			t.Errorf("spec: %q, expected an error", spec)
+		}
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	types := make(map[string]Type)
+	for name, spec := range map[string]string{
+		"replicas": "number",
+		"ratio":    "number",
+		"enabled":  "bool",
+		"tags":     "map(string)",
+		"ports":    "list(number)",
+		"name":     "string",
+		"extra":    "any",
+	} {
+		parsed, err := ParseType(spec)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		types[name] = parsed
+	}
+	vars := map[string]interface{}{
+		"replicas": "3",
+		"ratio":    "0.5",
+		"enabled":  "true",
+		"tags":     `{"env": "prod", "tier": 1}`,
+		"ports":    []interface{}{"80", 443},
+		"name":     "web",
+		"extra":    "left",
+		"other":    "untyped",
+	}
+	coerced, err := Coerce(vars, types)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"replicas": 3,
+		"ratio":    0.5,
+		"enabled":  true,
+		"tags":     map[string]interface{}{"env": "prod", "tier": "1"},
+		"ports":    []interface{}{80, 443},
+		"name":     "web",
+		"extra":    "left",
+		"other":    "untyped",
+	}
+	if !reflect.DeepEqual(coerced, expected) {
+		t.Errorf("got: %v, want: %v", coerced, expected)
+	}
+	if vars["replicas"] != "3" {
+		t.Error("expected the variables to be left unchanged")
+	}
+
+	errs := []struct {
+		Vars     map[string]interface{}
+		Type     string
+		Expected string
+	}{
+		{Vars: map[string]interface{}{"v": "three"}, Type: "number", Expected: `variable v: expected number, got "three"`},
+		{Vars: map[string]interface{}{"v": "yes please"}, Type: "bool", Expected: `variable v: expected bool, got "yes please"`},
+		{Vars: map[string]interface{}{"v": []interface{}{"1", "x"}}, Type: "list(number)", Expected: `variable v[1]: expected number, got "x"`},
+		{Vars: map[string]interface{}{"v": map[string]interface{}{"a": []interface{}{}}}, Type: "map(string)", Expected: "variable v.a: expected string, got a list"},
+		{Vars: map[string]interface{}{"v": "not json"}, Type: "map(string)", Expected: `variable v: expected map(string), got "not json"`},
+		{Vars: map[string]interface{}{}, Type: "number", Expected: "variable v is declared as number but is not set"},
+	}
+	for i, x := range errs {
+		parsed, _ := ParseType(x.Type)
+		if _, err := Coerce(x.Vars, map[string]Type{"v": parsed}); err == nil || !strings.Contains(err.Error(), x.Expected) {
+			t.Errorf("case %d, expected error: %s, got: %v", i, x.Expected, err)
+		}
+	}
+}
+
+func TestCoerceStrings(t *testing.T) {
+	value := map[string]interface{}{
+		"enabled":  "true",
+		"disabled": "false",
+		"title":    "True",
+		"replicas": "3",
+		"ratio":    "-0.25",
+		"big":      "1e3",
+		"zip":      "01234",
+		"mode":     "0644",
+		"version":  "1.2.3",
+		"hex":      "0x10",
+		"blank":    "",
+		"list":     []interface{}{"1", "a", map[string]interface{}{"b": "false"}},
+		"typed":    2,
+	}
+	expected := map[string]interface{}{
+		"enabled":  true,
+		"disabled": false,
+		"title":    "True",
+		"replicas": 3,
+		"ratio":    -0.25,
+		"big":      1000.0,
+		"zip":      "01234",
+		"mode":     "0644",
+		"version":  "1.2.3",
+		"hex":      "0x10",
+		"blank":    "",
+		"list":     []interface{}{1, "a", map[string]interface{}{"b": false}},
+		"typed":    2,
+	}
+	if got := CoerceStrings(value); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %v, want: %v", got, expected)
+	}
+}
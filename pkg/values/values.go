@@ -0,0 +1,201 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package values provides the parsing and layering of structured template values
+package values
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Parse is responsible for decoding a yaml or json document of values
+func Parse(content string) (map[string]interface{}, error) {
+	var decoded interface{}
+	if err := yaml.Unmarshal([]byte(content), &decoded); err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		return make(map[string]interface{}), nil
+	}
+	values, ok := Normalize(decoded).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("values must be a map, got: %T", decoded)
+	}
+
+	return values, nil
+}
+
+// Normalize converts the maps produced by the yaml decoder into string keyed maps
+func Normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[fmt.Sprintf("%v", k)] = Normalize(x)
+		}
+		return m
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = Normalize(x)
+		}
+		return v
+	case []interface{}:
+		for i, x := range v {
+			v[i] = Normalize(x)
+		}
+		return v
+	}
+
+	return value
+}
+
+const (
+	// StrategyOverride replaces any value other than a map with that of the later layer
+	StrategyOverride = "override"
+	// StrategyAppendLists is as override, except lists are concatenated
+	StrategyAppendLists = "append-lists"
+	// StrategyFailOnConflict fails if the layers hold differing values, other than maps, for a key
+	StrategyFailOnConflict = "fail-on-conflict"
+)
+
+// Strategies is the list of supported merge strategies
+var Strategies = []string{StrategyOverride, StrategyAppendLists, StrategyFailOnConflict}
+
+// Merge is responsible for deep merging the layers in order of precedence, later layers winning;
+// maps are merged recursively while any other value, lists included, replaces the earlier one
+func Merge(layers ...map[string]interface{}) map[string]interface{} {
+	merged, _ := MergeWithStrategy(StrategyOverride, layers...)
+
+	return merged
+}
+
+// MergeWithStrategy is responsible for deep merging the layers in order, maps being merged
+// recursively and any other values according to the strategy
+func MergeWithStrategy(strategy string, layers ...map[string]interface{}) (map[string]interface{}, error) {
+	switch strategy {
+	case StrategyOverride, StrategyAppendLists, StrategyFailOnConflict:
+	default:
+		return nil, fmt.Errorf("unsupported merge strategy: %q, expected one of: %s", strategy, strings.Join(Strategies, ", "))
+	}
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		if err := mergeInto(merged, layer, strategy, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeInto merges the source map into the destination, prefix being the path of the maps
+func mergeInto(dst, src map[string]interface{}, strategy, prefix string) error {
+	for k, v := range src {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		existing, found := dst[k]
+		if from, isMap := v.(map[string]interface{}); isMap {
+			to, isMap := existing.(map[string]interface{})
+			if !isMap {
+				if found && strategy == StrategyFailOnConflict {
+					return fmt.Errorf("conflicting values for key: %s", path)
+				}
+				to = make(map[string]interface{}, len(from))
+				dst[k] = to
+			}
+			if err := mergeInto(to, from, strategy, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if !found {
+			dst[k] = copyList(v)
+			continue
+		}
+		switch strategy {
+		case StrategyAppendLists:
+			to, isList := existing.([]interface{})
+			from, fromList := v.([]interface{})
+			if isList && fromList {
+				dst[k] = append(to, from...)
+				continue
+			}
+		case StrategyFailOnConflict:
+			if !reflect.DeepEqual(existing, v) {
+				return fmt.Errorf("conflicting values for key: %s", path)
+			}
+		}
+		dst[k] = copyList(v)
+	}
+
+	return nil
+}
+
+// copyList returns a copy of the value if it's a list, so appending never modifies a layer
+func copyList(v interface{}) interface{} {
+	if list, isList := v.([]interface{}); isList {
+		return append([]interface{}{}, list...)
+	}
+
+	return v
+}
+
+// Set is responsible for setting the value at the dotted key, i.e. a.b.c, creating any
+// intermediate maps; a literal dot in a key can be escaped with a backslash
+func Set(values map[string]interface{}, key string, value interface{}) error {
+	path := SplitKey(key)
+	for i, x := range path {
+		if x == "" {
+			return fmt.Errorf("invalid key: %q, contains an empty element", key)
+		}
+		if i == len(path)-1 {
+			values[x] = value
+			break
+		}
+		next, found := values[x].(map[string]interface{})
+		if !found {
+			next = make(map[string]interface{})
+			values[x] = next
+		}
+		values = next
+	}
+
+	return nil
+}
+
+// SplitKey splits a dotted key into its elements, honouring backslash escaped dots
+func SplitKey(key string) []string {
+	var elements []string
+	var current strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key) && key[i+1] == '.':
+			current.WriteByte('.')
+			i++
+		case key[i] == '.':
+			elements = append(elements, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(key[i])
+		}
+	}
+
+	return append(elements, current.String())
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	values, err := Parse("a:\n  b: 1\nlist:\n  - x: z\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"a":    map[string]interface{}{"b": 1},
+		"list": []interface{}{map[string]interface{}{"x": "z"}},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got: %v, want: %v", values, expected)
+	}
+	if _, err := Parse("- not a map"); err == nil {
+		t.Errorf("expected an error for a non map document")
+	}
+	if values, err := Parse(""); err != nil || len(values) != 0 {
+		t.Errorf("expected an empty document to produce no values, got: %v, error: %v", values, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge(
+		map[string]interface{}{
+			"a":    map[string]interface{}{"b": "1", "c": "1"},
+			"list": []interface{}{"x", "y"},
+		},
+		map[string]interface{}{
+			"a":    map[string]interface{}{"c": "2"},
+			"list": []interface{}{"z"},
+		},
+	)
+	expected := map[string]interface{}{
+		"a":    map[string]interface{}{"b": "1", "c": "2"},
+		"list": []interface{}{"z"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("got: %v, want: %v", merged, expected)
+	}
+}
+
+func TestMergeDoesNotModifyLayers(t *testing.T) {
+	base := map[string]interface{}{"a": map[string]interface{}{"b": "1"}}
+	Merge(base, map[string]interface{}{"a": map[string]interface{}{"b": "2"}})
+	if base["a"].(map[string]interface{})["b"] != "1" {
+		t.Errorf("expected the base layer to be left untouched")
+	}
+}
+
+func TestSet(t *testing.T) {
+	values := map[string]interface{}{"a": "scalar"}
+	if err := Set(values, "a.b.c", "x"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := Set(values, `annotations.example\.com/name`, "y"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"a":           map[string]interface{}{"b": map[string]interface{}{"c": "x"}},
+		"annotations": map[string]interface{}{"example.com/name": "y"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got: %v, want: %v", values, expected)
+	}
+	if err := Set(values, "a..b", "x"); err == nil {
+		t.Errorf("expected an error for an empty key element")
+	}
+}
+
+func TestMergeWithStrategy(t *testing.T) {
+	base := map[string]interface{}{
+		"a":     map[string]interface{}{"list": []interface{}{"x"}, "name": "base"},
+		"other": "same",
+	}
+	layer := map[string]interface{}{
+		"a":     map[string]interface{}{"list": []interface{}{"y"}, "name": "layer"},
+		"other": "same",
+	}
+
+	merged, err := MergeWithStrategy(StrategyAppendLists, base, layer)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{
+		"a":     map[string]interface{}{"list": []interface{}{"x", "y"}, "name": "layer"},
+		"other": "same",
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("got: %v, want: %v", merged, expected)
+	}
+	if list := base["a"].(map[string]interface{})["list"].([]interface{}); len(list) != 1 {
+		t.Errorf("expected the base layer to be left untouched, got: %v", list)
+	}
+
+	_, err = MergeWithStrategy(StrategyFailOnConflict, base, layer)
+	if err == nil || err.Error() != "conflicting values for key: a.list" && err.Error() != "conflicting values for key: a.name" {
+		t.Errorf("expected a conflict error, got: %v", err)
+	}
+	if _, err := MergeWithStrategy(StrategyFailOnConflict, base, map[string]interface{}{"other": "same"}); err != nil {
+		t.Errorf("unexpected error on equal values: %s", err)
+	}
+	if _, err := MergeWithStrategy("unknown", base); err == nil {
+		t.Errorf("expected an error for an unknown strategy")
+	}
+}
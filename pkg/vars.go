@@ -0,0 +1,167 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/gambol99/terraform-gotemplate/pkg/values"
+)
+
+// templateVars is responsible for building the variables passed to the template, layered in
+// order of precedence, the later layers winning: the base layers, being the vars of the provider
+// profile and then those of any registered template, then the values_file, each of the
+// override_files, the vars_files, vars, the set blocks and finally the secret_vars. Maps are always
+// deep merged, the merge_strategy deciding how the other values of two layers are combined
+func templateVars(d attributeGetter, base ...map[string]interface{}) (map[string]interface{}, error) {
+	basePath := d.Get("base_path").(string)
+
+	layers := append([]map[string]interface{}{}, base...)
+	if filename := d.Get("values_file").(string); filename != "" {
+		layer, err := readValuesFile(resolvePath(basePath, filename))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	for _, x := range d.Get("override_files").([]interface{}) {
+		layer, err := readValuesFile(resolvePath(basePath, x.(string)))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	for _, x := range d.Get("vars_files").([]interface{}) {
+		layer, err := ReadVarsFile(resolvePath(basePath, x.(string)))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	layers = append(layers, d.Get("vars").(map[string]interface{}))
+
+	sets := make(map[string]interface{})
+	for _, x := range d.Get("set").([]interface{}) {
+		item := x.(map[string]interface{})
+		if err := values.Set(sets, item["key"].(string), item["value"].(string)); err != nil {
+			return nil, fmt.Errorf("invalid set block, error: %s", err)
+		}
+	}
+
+	layers = append(layers, sets, d.Get("secret_vars").(map[string]interface{}))
+
+	merged, err := values.MergeWithStrategy(d.Get("merge_strategy").(string), layers...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to merge the vars, error: %s", err)
+	}
+
+	return merged, nil
+}
+
+// effectiveVarsHash returns the hash of the variables as seen by the template, less the render
+// context, which is json encoded with the keys of every map sorted
+func effectiveVarsHash(vars map[string]interface{}) (string, error) {
+	effective := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		if k != contextVar {
+			effective[k] = v
+		}
+	}
+	encoded, err := json.Marshal(effective)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode the vars, error: %s", err)
+	}
+
+	return hash(string(encoded)), nil
+}
+
+// valuesExtensions are the extensions of the yaml and json files of values
+var valuesExtensions = map[string]bool{".json": true, ".yaml": true, ".yml": true}
+
+// ReadVarsFile is responsible for reading a file of variables, the format being chosen by the file
+// name: .env files, including those named .env.<environment>, are env files, .tfvars are terraform
+// variable files, otherwise yaml or json, which includes .tfvars.json and .env.yaml. It is shared
+// with the gotemplate cli
+func ReadVarsFile(filename string) (map[string]interface{}, error) {
+	name := filepath.Base(filename)
+	parse, format := values.ParseDotenv, "env"
+	switch {
+	case filepath.Ext(name) == ".tfvars":
+		parse, format = values.ParseTfvars, "tfvars"
+	case !isDotenvFile(name):
+		return readValuesFile(filename)
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s file: %s, error: %s", format, filename, err)
+	}
+
+	return decoded, nil
+}
+
+// isDotenvFile checks if the file name is that of an env file: .env, <name>.env or an
+// .env.<environment> without the extension of a yaml or json file
+func isDotenvFile(name string) bool {
+	if name == ".env" || filepath.Ext(name) == ".env" {
+		return true
+	}
+
+	return strings.HasPrefix(name, ".env.") && !valuesExtensions[filepath.Ext(name)]
+}
+
+// readValuesFile is responsible for reading and decoding a yaml or json file of values
+func readValuesFile(filename string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := values.Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse values file: %s, error: %s", filename, err)
+	}
+
+	return decoded, nil
+}
+
+// validateVarTypes checks each of the declared variable types parses
+func validateVarTypes(v interface{}, k string) ([]string, []error) {
+	var errs []error
+	for name, spec := range v.(map[string]interface{}) {
+		if _, err := values.ParseType(spec.(string)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: variable %s, %s", k, name, err))
+		}
+	}
+
+	return nil, errs
+}
+
+// varTypes returns the parsed variable types, having been validated by the schema
+func varTypes(declared map[string]interface{}) map[string]values.Type {
+	types := make(map[string]values.Type, len(declared))
+	for name, spec := range declared {
+		types[name], _ = values.ParseType(spec.(string))
+	}
+
+	return types
+}
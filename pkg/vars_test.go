@@ -0,0 +1,200 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoTemplateLayeredValues(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"values.yaml":      "image:\n  name: nginx\n  tag: \"1.0\"\nreplicas: 1\nregion: base\n",
+		"overrides/a.yaml": "image:\n  tag: \"1.1\"\nreplicas: 2\n",
+		"overrides/b.json": `{"replicas": 3}`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path      = "%s"
+						template       = "{{ .image.name }}:{{ .image.tag }} x{{ .replicas }} {{ .region }} {{ .env.name }}"
+						values_file    = "values.yaml"
+						override_files = ["overrides/a.yaml", "overrides/b.json"]
+						vars           = { region = "eu-west-2" }
+
+						set {
+							key   = "env.name"
+							value = "prod"
+						}
+						set {
+							key   = "image.tag"
+							value = "2.0"
+						}
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "nginx:2.0 x3 eu-west-2 prod"),
+			},
+			{
+				// the secret_vars are merged over every other layer
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path   = "%s"
+						template    = "{{ .region }} {{ .env.name }}"
+						values_file = "values.yaml"
+						vars        = { region = "eu-west-2" }
+						secret_vars = { region = "eu-west-1" }
+
+						set {
+							key   = "region"
+							value = "us-east-1"
+						}
+						set {
+							key   = "env.name"
+							value = "prod"
+						}
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "eu-west-1 prod"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateVarsFiles(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"app.env":          "NAME=web\nPORT=8080\n",
+		".env.production":  "PORT=\"443\"\n",
+		"prod.tfvars":      "ZONES = [\"a\", \"b\"]\nTAGS = { team = \"platform\" }\n",
+		"prod.tfvars.json": `{"TIER": "frontend"}`,
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path  = "%s"
+						template   = "{{ .NAME }}:{{ .PORT }} {{ .REGION }} {{ join \",\" .ZONES }} {{ .TAGS.team }} {{ .TIER }}"
+						vars_files = ["app.env", ".env.production", "prod.tfvars", "prod.tfvars.json"]
+						vars       = { REGION = "eu-west-2" }
+					}`, filepath.ToSlash(dir)),
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "web:443 eu-west-2 a,b platform frontend"),
+			},
+		},
+	})
+}
+
+func TestIsDotenvFile(t *testing.T) {
+	cases := map[string]bool{
+		".env":            true,
+		"app.env":         true,
+		".env.production": true,
+		".env.yaml":       false,
+		".env.json":       false,
+		".envrc":          false,
+		"values.yaml":     false,
+	}
+	for name, expected := range cases {
+		if isDotenvFile(name) != expected {
+			t.Errorf("name: %s, expected: %t", name, expected)
+		}
+	}
+}
+
+func TestGoTemplateMergeStrategy(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"values.yaml":   "ports: [80]\nname: web\n",
+		"override.yaml": "ports: [443]\n",
+	})
+	defer os.RemoveAll(dir)
+
+	config := func(strategy string) string {
+		return fmt.Sprintf(`
+			data "gotemplate_file" "test" {
+				base_path      = "%s"
+				template       = "{{ .name }} {{ range .ports }}{{ . }} {{ end }}"
+				values_file    = "values.yaml"
+				override_files = ["override.yaml"]
+				merge_strategy = "%s"
+			}`, filepath.ToSlash(dir), strategy)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: config("override"),
+				Check:  resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "web 443 "),
+			},
+			{
+				Config: config("append-lists"),
+				Check:  resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "web 80 443 "),
+			},
+			{
+				Config:      config("fail-on-conflict"),
+				ExpectError: regexp.MustCompile("unable to merge the vars"),
+			},
+		},
+	})
+}
+
+func TestGoTemplateEffectiveVars(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"values.yaml": "name: web\n",
+	})
+	defer os.RemoveAll(dir)
+
+	// step: the same effective vars reached through different layers share the hash
+	expected, err := effectiveVarsHash(map[string]interface{}{"name": "web", "port": "80"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	check := resource.TestCheckResourceAttr("data.gotemplate_file.test", "effective_vars_sha256", expected)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						base_path   = "%s"
+						template    = "{{ .name }}:{{ .port }} {{ .gotemplate.timestamp }}"
+						values_file = "values.yaml"
+						vars        = { port = "80" }
+					}`, filepath.ToSlash(dir)),
+				Check: check,
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ .name }}:{{ .port }}"
+						vars     = { name = "web", port = "80" }
+					}`,
+				Check: check,
+			},
+		},
+	})
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// vaultSettings are the settings of the vault client used by the vault resources
+type vaultSettings struct {
+	// address is the url of the vault server
+	address string
+	// token is the token used to authenticate the requests
+	token string
+	// namespace is the enterprise namespace of the requests
+	namespace string
+	// caFile is the path to a certificate authority used to verify the server
+	caFile string
+	// skipVerify disables the verification of the server certificate
+	skipVerify bool
+}
+
+// vaultSchema returns the provider block configuring the vault client, any setting left unset
+// falling back to the standard VAULT_* environment variables
+func vaultSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "The connection settings of the vault server used by the vault resources",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"address": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The url of the vault server, i.e. https://vault:8200",
+				},
+				"token": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "The token used to authenticate the requests",
+				},
+				"namespace": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The namespace of the requests on vault enterprise",
+				},
+				"ca_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The path to a pem encoded certificate authority used to verify the server",
+				},
+				"skip_tls_verify": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: "Disable the verification of the server certificate",
+				},
+			},
+		},
+	}
+}
+
+// vaultConfig returns the vault settings from the provider block, if any
+func vaultConfig(blocks []interface{}) vaultSettings {
+	if len(blocks) == 0 || blocks[0] == nil {
+		return vaultSettings{}
+	}
+	block := blocks[0].(map[string]interface{})
+
+	return vaultSettings{
+		address:    block["address"].(string),
+		token:      block["token"].(string),
+		namespace:  block["namespace"].(string),
+		caFile:     block["ca_file"].(string),
+		skipVerify: block["skip_tls_verify"].(bool),
+	}
+}
+
+// vaultClient is a minimal client of the vault http api
+type vaultClient struct {
+	settings vaultSettings
+	client   *http.Client
+}
+
+// errVaultNotFound indicates the requested path does not exist
+var errVaultNotFound = errors.New("vault path not found")
+
+// vaultOf returns a vault client from the provider configuration
+func vaultOf(meta interface{}) (*vaultClient, error) {
+	var settings vaultSettings
+	if config, ok := meta.(*providerConfig); ok {
+		settings = config.vault
+	}
+	if settings.address == "" {
+		settings.address = os.Getenv("VAULT_ADDR")
+	}
+	if settings.token == "" {
+		settings.token = os.Getenv("VAULT_TOKEN")
+	}
+	if settings.namespace == "" {
+		settings.namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if settings.caFile == "" {
+		settings.caFile = os.Getenv("VAULT_CACERT")
+	}
+	if settings.address == "" {
+		return nil, fmt.Errorf("no vault address configured, set the provider vault block or VAULT_ADDR")
+	}
+
+	config := &tls.Config{InsecureSkipVerify: settings.skipVerify}
+	if settings.caFile != "" {
+		content, err := ioutil.ReadFile(settings.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the vault ca_file, error: %s", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no certificates found in the vault ca_file: %s", settings.caFile)
+		}
+	}
+
+	return &vaultClient{
+		settings: settings,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: config},
+		},
+	}, nil
+}
+
+// do is responsible for making a request to the api, decoding the response into the output
+func (v *vaultClient) do(method, path string, input, output interface{}) error {
+	var body bytes.Buffer
+	if input != nil {
+		if err := json.NewEncoder(&body).Encode(input); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(v.settings.address, "/")+"/v1/"+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.settings.token)
+	if v.settings.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.settings.namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errVaultNotFound
+	case resp.StatusCode >= http.StatusBadRequest:
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(content, &failure) == nil && len(failure.Errors) > 0 {
+			return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(failure.Errors, ", "))
+		}
+		return fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+	if output == nil || len(content) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(content, output)
+}
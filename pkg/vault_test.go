@@ -0,0 +1,72 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestVaultConfig(t *testing.T) {
+	settings := vaultConfig([]interface{}{map[string]interface{}{
+		"address":         "https://vault:8200",
+		"token":           "root",
+		"namespace":       "team",
+		"ca_file":         "",
+		"skip_tls_verify": true,
+	}})
+	expected := vaultSettings{address: "https://vault:8200", token: "root", namespace: "team", skipVerify: true}
+	if settings != expected {
+		t.Errorf("got: %+v, want: %+v", settings, expected)
+	}
+}
+
+func TestVaultOf(t *testing.T) {
+	os.Unsetenv("VAULT_ADDR")
+	if _, err := vaultOf(&providerConfig{}); err == nil || !strings.Contains(err.Error(), "no vault address configured") {
+		t.Errorf("expected an error without an address, got: %v", err)
+	}
+	if _, err := vaultOf(&providerConfig{vault: vaultSettings{address: "http://vault", caFile: "/nonexistent"}}); err == nil {
+		t.Error("expected an error for a missing ca_file")
+	}
+}
+
+func TestVaultClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/secret/data/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+	}))
+	defer server.Close()
+
+	client, err := vaultOf(&providerConfig{vault: vaultSettings{address: server.URL}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := client.do("GET", "secret/data/missing", nil, nil); err != errVaultNotFound {
+		t.Errorf("expected not found, got: %v", err)
+	}
+	if err := client.do("GET", "secret/data/denied", nil, nil); err == nil || err.Error() != "vault returned 403: permission denied" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
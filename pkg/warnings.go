@@ -0,0 +1,31 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+// addWarnings is responsible for logging the warnings and appending them to the warnings
+// attribute, empty warnings being ignored. Terraform 0.11 has no way for a data source to raise a
+// warning in the plan, so the attribute is the means of surfacing them
+func addWarnings(d templateData, warnings ...string) {
+	list := toStrings(d.Get("warnings").([]interface{}))
+	for _, x := range warnings {
+		if x == "" {
+			continue
+		}
+		logEvent(logWarn, x)
+		list = append(list, x)
+	}
+	d.Set("warnings", list)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2017 All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestGoTemplateWarnings(t *testing.T) {
+	dir := testTemplateDir(t, map[string]string{
+		"large.tmpl": "0123456789",
+	})
+	defer os.RemoveAll(dir)
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "gotemplate_file" "test" {
+						template           = "{{ printf \"%%015000d\" 0 }}"
+						snippets           = "%s"
+						max_snippet_size   = 5
+						oversize_action    = "skip"
+						size_limit_profile = "aws_user_data"
+					}`, filepath.ToSlash(dir)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "warnings.#", "2"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "warnings.0", "snippet large.tmpl skipped, 10 bytes exceeds the limit of 5 bytes"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "warnings.1", "the rendered output is 15000 bytes, within 10% of the aws_user_data limit of 16384 bytes"),
+				),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "{{ if is_true .enabled }}on{{ end }}"
+						vars     = { enabled = "true" }
+					}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "rendered", "on"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "warnings.#", "1"),
+					resource.TestCheckResourceAttr("data.gotemplate_file.test", "warnings.0", "the function is_true is deprecated, use isTrue"),
+				),
+			},
+			{
+				Config: `
+					data "gotemplate_file" "test" {
+						template = "hello"
+					}`,
+				Check: resource.TestCheckResourceAttr("data.gotemplate_file.test", "warnings.#", "0"),
+			},
+		},
+	})
+}